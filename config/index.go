@@ -22,6 +22,42 @@ type Config struct {
 	CloudinaryName   string
 	CloudinaryKey    string
 	CloudinarySecret string
+	StockCodePattern string
+	StockCodeBranch  string
+	ReceiptPattern   string
+	HoldPickupDays   string
+	ClosedWeekdays   string
+	ProcessingFee    string
+	// Months a membership runs when renewed
+	MembershipMonths string
+	// Days without activity after which a customer is anonymized, 0 to keep customers forever
+	RetentionInactiveDays string
+	// Checkout eligibility
+	MaxUnpaidFines         string
+	BlockOverdue           string
+	BlockExpiredMembership string
+	// Background jobs
+	SchedulerEnabled         string
+	OverdueCheckInterval     string
+	HoldExpiryInterval       string
+	NotificationInterval     string
+	MembershipExpiryInterval string
+	RetentionCheckInterval   string
+	// Notifications
+	DueReminderDays           string
+	OverdueNoticeIntervalDays string
+	MaxOverdueNotices         string
+	NotificationMaxAttempts   string
+	SMTPHost                  string
+	SMTPPort                  string
+	SMTPUsername              string
+	SMTPPassword              string
+	SMTPFrom                  string
+	SMSGatewayURL             string
+	SMSAPIKey                 string
+	SMSSender                 string
+	WebhookURL                string
+	WebhookSecret             string
 }
 
 func LoadConfig() (*Config, error) {
@@ -42,6 +78,103 @@ func LoadConfig() (*Config, error) {
 		CloudinaryName:   os.Getenv("CLOUDINARY_CLOUD_NAME"),
 		CloudinaryKey:    os.Getenv("CLOUDINARY_API_KEY"),
 		CloudinarySecret: os.Getenv("CLOUDINARY_API_SECRET"),
+		StockCodePattern: os.Getenv("STOCK_CODE_PATTERN"),
+		StockCodeBranch:  os.Getenv("STOCK_CODE_BRANCH"),
+		ReceiptPattern:   os.Getenv("RECEIPT_NUMBER_PATTERN"),
+		HoldPickupDays:   os.Getenv("HOLD_PICKUP_DAYS"),
+		ClosedWeekdays:   os.Getenv("LIBRARY_CLOSED_WEEKDAYS"),
+		ProcessingFee:    os.Getenv("REPLACEMENT_PROCESSING_FEE"),
+		MembershipMonths: os.Getenv("MEMBERSHIP_PERIOD_MONTHS"),
+
+		RetentionInactiveDays: os.Getenv("RETENTION_INACTIVE_DAYS"),
+
+		MaxUnpaidFines:         os.Getenv("ELIGIBILITY_MAX_UNPAID_FINES"),
+		BlockOverdue:           os.Getenv("ELIGIBILITY_BLOCK_OVERDUE"),
+		BlockExpiredMembership: os.Getenv("ELIGIBILITY_BLOCK_EXPIRED_MEMBERSHIP"),
+
+		SchedulerEnabled:         os.Getenv("SCHEDULER_ENABLED"),
+		OverdueCheckInterval:     os.Getenv("OVERDUE_CHECK_INTERVAL"),
+		HoldExpiryInterval:       os.Getenv("HOLD_EXPIRY_INTERVAL"),
+		NotificationInterval:     os.Getenv("NOTIFICATION_INTERVAL"),
+		MembershipExpiryInterval: os.Getenv("MEMBERSHIP_EXPIRY_INTERVAL"),
+		RetentionCheckInterval:   os.Getenv("RETENTION_CHECK_INTERVAL"),
+
+		DueReminderDays:           os.Getenv("DUE_REMINDER_DAYS"),
+		OverdueNoticeIntervalDays: os.Getenv("OVERDUE_NOTICE_INTERVAL_DAYS"),
+		MaxOverdueNotices:         os.Getenv("OVERDUE_NOTICE_MAX"),
+		NotificationMaxAttempts:   os.Getenv("NOTIFICATION_MAX_ATTEMPTS"),
+		SMTPHost:                  os.Getenv("SMTP_HOST"),
+		SMTPPort:                  os.Getenv("SMTP_PORT"),
+		SMTPUsername:              os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:              os.Getenv("SMTP_PASSWORD"),
+		SMTPFrom:                  os.Getenv("SMTP_FROM"),
+		SMSGatewayURL:             os.Getenv("SMS_GATEWAY_URL"),
+		SMSAPIKey:                 os.Getenv("SMS_API_KEY"),
+		SMSSender:                 os.Getenv("SMS_SENDER"),
+		WebhookURL:                os.Getenv("NOTIFICATION_WEBHOOK_URL"),
+		WebhookSecret:             os.Getenv("NOTIFICATION_WEBHOOK_SECRET"),
+	}
+
+	// Fall back to sensible defaults for optional settings
+	if config.StockCodePattern == "" {
+		config.StockCodePattern = "{BRANCH}-{YEAR}-{SEQ:6}"
+	}
+	if config.StockCodeBranch == "" {
+		config.StockCodeBranch = "MAIN"
+	}
+	if config.ReceiptPattern == "" {
+		config.ReceiptPattern = "RCP-{BRANCH}-{YEAR}-{SEQ:6}"
+	}
+	if config.HoldPickupDays == "" {
+		config.HoldPickupDays = "3"
+	}
+	if config.ProcessingFee == "" {
+		config.ProcessingFee = "0"
+	}
+	if config.MembershipMonths == "" {
+		config.MembershipMonths = "12"
+	}
+	if config.RetentionInactiveDays == "" {
+		config.RetentionInactiveDays = "0"
+	}
+	if config.BlockOverdue == "" {
+		config.BlockOverdue = "true"
+	}
+	if config.BlockExpiredMembership == "" {
+		config.BlockExpiredMembership = "true"
+	}
+	if config.SchedulerEnabled == "" {
+		config.SchedulerEnabled = "true"
+	}
+	if config.OverdueCheckInterval == "" {
+		config.OverdueCheckInterval = "15m"
+	}
+	if config.HoldExpiryInterval == "" {
+		config.HoldExpiryInterval = "15m"
+	}
+	if config.NotificationInterval == "" {
+		config.NotificationInterval = "1h"
+	}
+	if config.MembershipExpiryInterval == "" {
+		config.MembershipExpiryInterval = "1h"
+	}
+	if config.RetentionCheckInterval == "" {
+		config.RetentionCheckInterval = "24h"
+	}
+	if config.DueReminderDays == "" {
+		config.DueReminderDays = "2"
+	}
+	if config.OverdueNoticeIntervalDays == "" {
+		config.OverdueNoticeIntervalDays = "7"
+	}
+	if config.MaxOverdueNotices == "" {
+		config.MaxOverdueNotices = "3"
+	}
+	if config.NotificationMaxAttempts == "" {
+		config.NotificationMaxAttempts = "3"
+	}
+	if config.SMTPPort == "" {
+		config.SMTPPort = "587"
 	}
 
 	return config, nil
@@ -58,19 +191,43 @@ func SetupDatabase(cfg *Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
+	if err := Migrate(db); err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
+
+// Migrate creates or updates the tables of every model
+func Migrate(db *gorm.DB) error {
 	// Lakukan AutoMigrate untuk semua model yang kamu pakai
-	err = db.AutoMigrate(
+	return db.AutoMigrate(
+		&model.Branch{},
 		&model.Book{},
 		&model.Media{},
 		&model.User{},
 		&model.BookStock{},
+		&model.BookStockStatusHistory{},
 		&model.BookTransaction{},
+		&model.BookTransactionEvent{},
 		&model.Customer{},
 		&model.Charge{},
+		&model.CodeSequence{},
+		&model.InventorySession{},
+		&model.InventoryScan{},
+		&model.BranchTransfer{},
+		&model.LoanPolicy{},
+		&model.LoanRenewal{},
+		&model.Hold{},
+		&model.Payment{},
+		&model.ChargeAdjustment{},
+		&model.EligibilityOverride{},
+		&model.Refund{},
+		&model.Notification{},
+		&model.NotificationPreference{},
+		&model.NotificationTemplate{},
+		&model.OpeningHours{},
+		&model.Closure{},
+		&model.CustomerMerge{},
 	)
-	if err != nil {
-		return nil, err
-	}
-
-	return db, nil
 }
@@ -15,15 +15,31 @@ type AuthRes struct {
 }
 
 type UserData struct {
-	ID    uuid.UUID `json:"id"`
-	Name  string    `json:"name"`
-	Email string    `json:"email"`
-	Role  string    `json:"role"`
+	ID       uuid.UUID  `json:"id"`
+	Name     string     `json:"name"`
+	Email    string     `json:"email"`
+	Role     string     `json:"role"`
+	BranchID *uuid.UUID `json:"branch_id,omitempty"`
+	// Customer record of a library member account
+	CustomerID *uuid.UUID `json:"customer_id,omitempty"`
+	// Dependants of that customer, whose records the member may see as their guardian
+	DependantIDs []uuid.UUID `json:"dependant_ids,omitempty"`
 }
 
 type RegisterReq struct {
 	Name     string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=6"`
-	Role     string `json:"role" validate:"omitempty,oneof=user admin"`
+}
+
+// UserBranchAssignRequest represents the request to bind a staff account to a branch
+// Staff bound to a branch can only manage copies located there; a null branch_id removes the binding
+type UserBranchAssignRequest struct {
+	BranchID *uuid.UUID `json:"branch_id"`
+}
+
+// UserCustomerLinkRequest represents the request to link a user account to a customer record
+// A null customer_id removes the link
+type UserCustomerLinkRequest struct {
+	CustomerID *uuid.UUID `json:"customer_id"`
 }
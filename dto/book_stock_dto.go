@@ -1,31 +1,81 @@
 package dto
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 // BookStockResponse represents the response for book stock data
 type BookStockResponse struct {
-	Code   string    `json:"code"`
-	BookID uuid.UUID `json:"book_id"`
-	Book   *BookRes  `json:"book,omitempty"`
-	Status string    `json:"status"`
+	Code            string          `json:"code"`
+	BookID          uuid.UUID       `json:"book_id"`
+	Book            *BookRes        `json:"book,omitempty"`
+	Status          string          `json:"status"`
+	OwnerBranchID   *uuid.UUID      `json:"owner_branch_id,omitempty"`
+	CurrentBranchID *uuid.UUID      `json:"current_branch_id,omitempty"`
+	CurrentBranch   *BranchResponse `json:"current_branch,omitempty"`
+	Price           float64         `json:"price"`
 }
 
 // BookStockCreateRequest represents the request to create a book stock
+// When code is omitted it is generated from the configured stock code pattern
+// The copy is owned by and located at branch_id, defaulting to the staff member's own branch
 type BookStockCreateRequest struct {
-	Code   string    `json:"code" validate:"required,min=3,max=50"`
-	BookID uuid.UUID `json:"book_id" validate:"required"`
-	Status string    `json:"status" validate:"omitempty,oneof=Available Borrowed Damaged Lost"`
+	Code     string     `json:"code" validate:"omitempty,min=3,max=50"`
+	BookID   uuid.UUID  `json:"book_id" validate:"required"`
+	Status   string     `json:"status" validate:"omitempty,oneof=Available Damaged InRepair"`
+	Branch   string     `json:"branch" validate:"omitempty,alphanum,max=10"`
+	BranchID *uuid.UUID `json:"branch_id"`
+	Price    float64    `json:"price" validate:"gte=0"`
+}
+
+// BookStockBulkCreateRequest represents the request to add several copies of a book at once
+type BookStockBulkCreateRequest struct {
+	BookID   uuid.UUID  `json:"book_id" validate:"required"`
+	Quantity int        `json:"quantity" validate:"required,min=1,max=500"`
+	Status   string     `json:"status" validate:"omitempty,oneof=Available Damaged InRepair"`
+	Branch   string     `json:"branch" validate:"omitempty,alphanum,max=10"`
+	BranchID *uuid.UUID `json:"branch_id"`
+	Price    float64    `json:"price" validate:"gte=0"`
+}
+
+// BookStockBulkCreateResponse represents the codes generated for a bulk create
+type BookStockBulkCreateResponse struct {
+	BookID uuid.UUID `json:"book_id"`
+	Codes  []string  `json:"codes"`
 }
 
 // BookStockUpdateRequest represents the request to update a book stock
+// Changing the owner branch also sets the location of copies that have none yet;
+// afterwards copies only move through transfers
 type BookStockUpdateRequest struct {
-	BookID uuid.UUID `json:"book_id,omitempty"`
-	Status string    `json:"status,omitempty" validate:"omitempty,oneof=Available Borrowed Damaged Lost"`
+	BookID        uuid.UUID  `json:"book_id,omitempty"`
+	OwnerBranchID *uuid.UUID `json:"owner_branch_id,omitempty"`
+	Status        string     `json:"status,omitempty" validate:"omitempty,oneof=Available Borrowed Damaged Lost InRepair Withdrawn InTransit"`
+	Reason        string     `json:"reason,omitempty" validate:"omitempty,max=500"`
+	Price         *float64   `json:"price,omitempty" validate:"omitempty,gte=0"`
 }
 
 // BookStockStatusUpdateRequest represents the request to update a book stock's status
 type BookStockStatusUpdateRequest struct {
-	Status string `json:"status" validate:"required,oneof=Available Borrowed Damaged Lost"`
+	Status string `json:"status" validate:"required,oneof=Available Borrowed Damaged Lost InRepair Withdrawn InTransit"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// BookStockFoundRequest represents the request to mark a lost book stock as found
+type BookStockFoundRequest struct {
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+}
+
+// BookStockStatusHistoryResponse represents a recorded status change of a book stock
+type BookStockStatusHistoryResponse struct {
+	ID         uuid.UUID  `json:"id"`
+	StockCode  string     `json:"stock_code"`
+	FromStatus string     `json:"from_status"`
+	ToStatus   string     `json:"to_status"`
+	Action     string     `json:"action"`
+	Reason     string     `json:"reason,omitempty"`
+	UserID     *uuid.UUID `json:"user_id,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
 }
@@ -14,20 +14,47 @@ type BookTransactionResponse struct {
 	BookStock  *BookStockResponse `json:"book_stock,omitempty"`
 	CustomerID uuid.UUID          `json:"customer_id"`
 	Customer   *CustomerResponse  `json:"customer,omitempty"`
+	BranchID   *uuid.UUID         `json:"branch_id,omitempty"`
 	DueDate    time.Time          `json:"due_date"`
 	Status     string             `json:"status"`
 	BorrowedAt *time.Time         `json:"borrowed_at,omitempty"`
 	ReturnAt   *time.Time         `json:"return_at,omitempty"`
 	Charges    []ChargeResponse   `json:"charges,omitempty"`
+	// Loan terms applied at checkout
+	LoanPolicyID    *uuid.UUID          `json:"loan_policy_id,omitempty"`
+	LoanPolicy      *LoanPolicyResponse `json:"loan_policy,omitempty"`
+	LoanPeriodDays  int                 `json:"loan_period_days"`
+	DailyLateFee    float64             `json:"daily_late_fee"`
+	MaxRenewals     int                 `json:"max_renewals"`
+	GracePeriodDays int                 `json:"grace_period_days"`
+	MaxLateFee      float64             `json:"max_late_fee"`
+	SkipClosedDays  bool                `json:"skip_closed_days"`
+	RenewalCount    int                 `json:"renewal_count"`
+}
+
+// LoanRenewalResponse represents a recorded extension of a loan
+type LoanRenewalResponse struct {
+	ID                uuid.UUID  `json:"id"`
+	BookTransactionID uuid.UUID  `json:"book_transaction_id"`
+	PreviousDueDate   time.Time  `json:"previous_due_date"`
+	NewDueDate        time.Time  `json:"new_due_date"`
+	UserID            *uuid.UUID `json:"user_id,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
 }
 
 type BookTransactionCreateRequest struct {
 	// BookID     uuid.UUID `json:"book_id" validate:"required"`
 	StockCode  string    `json:"stock_code" validate:"required"`
 	CustomerID uuid.UUID `json:"customer_id" validate:"required"`
-	Status     string    `json:"status" validate:"required,oneof=Borrowed Returned Overdue"`
+	// Loans always open Borrowed; kept for clients that still send it
+	Status string `json:"status" validate:"omitempty,oneof=Borrowed"`
+	// Lend despite failed eligibility checks; the override and its reason are recorded
+	Override       bool   `json:"override"`
+	OverrideReason string `json:"override_reason" validate:"required_if=Override true,max=500"`
 }
 
+// BookTransactionUpdateRequest represents the request to edit an open loan
+// return_at only applies together with status Returned; closed loans change through corrections
 type BookTransactionUpdateRequest struct {
 	// BookID     uuid.UUID  `json:"book_id,omitempty"`
 	StockCode  string     `json:"stock_code,omitempty"`
@@ -39,8 +66,95 @@ type BookTransactionUpdateRequest struct {
 
 type BookTransactionStatusUpdateRequest struct {
 	Status string `json:"status" validate:"required,oneof=Borrowed Returned Overdue"`
+	Reason string `json:"reason" validate:"omitempty,max=500"`
+}
+
+// BookTransactionCorrectionRequest represents an explicit correction of a closed loan
+// status Borrowed reopens a returned loan whose copy is still on the shelf; return_at fixes a mis-recorded return date
+type BookTransactionCorrectionRequest struct {
+	Status   string     `json:"status" validate:"omitempty,oneof=Borrowed"`
+	ReturnAt *time.Time `json:"return_at"`
+	Reason   string     `json:"reason" validate:"required,min=3,max=500"`
+}
+
+// BookTransactionEventResponse represents an entry in the event history of a loan
+type BookTransactionEventResponse struct {
+	ID                uuid.UUID  `json:"id"`
+	BookTransactionID uuid.UUID  `json:"book_transaction_id"`
+	FromStatus        string     `json:"from_status"`
+	ToStatus          string     `json:"to_status"`
+	Action            string     `json:"action"`
+	Reason            string     `json:"reason,omitempty"`
+	UserID            *uuid.UUID `json:"user_id,omitempty"`
+	User              *UserData  `json:"user,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
 }
 
+// BookTransactionReturnRequest represents the request to return a borrowed copy
+// refund_method applies when a copy reported lost is returned and part of its replacement charge is paid back
 type BookTransactionReturnRequest struct {
-	ReturnAt *time.Time `json:"return_at,omitempty"`
+	ReturnAt     *time.Time `json:"return_at,omitempty"`
+	RefundMethod string     `json:"refund_method,omitempty" validate:"omitempty,oneof=Cash Card Transfer Other"`
+}
+
+// BookTransactionLossRequest represents the request to close a loan whose copy was lost or came back damaged
+type BookTransactionLossRequest struct {
+	Status string `json:"status" validate:"required,oneof=Lost Damaged"`
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// BulkCheckoutRequest represents the request to lend a stack of copies to one customer
+// With all_or_nothing a single failure lends none of them; otherwise every copy that can be lent is
+type BulkCheckoutRequest struct {
+	CustomerID   uuid.UUID `json:"customer_id" validate:"required"`
+	StockCodes   []string  `json:"stock_codes" validate:"required,min=1,max=50,dive,required"`
+	AllOrNothing bool      `json:"all_or_nothing"`
+	// Lend despite failed eligibility checks; the override and its reason are recorded per loan
+	Override       bool   `json:"override"`
+	OverrideReason string `json:"override_reason" validate:"required_if=Override true,max=500"`
+}
+
+// BulkReturnRequest represents the request to return a stack of copies, possibly of several customers
+type BulkReturnRequest struct {
+	StockCodes   []string   `json:"stock_codes" validate:"required,min=1,max=50,dive,required"`
+	AllOrNothing bool       `json:"all_or_nothing"`
+	ReturnAt     *time.Time `json:"return_at,omitempty"`
+}
+
+// BulkCirculationItemResult represents the outcome of one copy in a bulk checkout or return
+type BulkCirculationItemResult struct {
+	StockCode   string                   `json:"stock_code"`
+	Success     bool                     `json:"success"`
+	Error       string                   `json:"error,omitempty"`
+	Transaction *BookTransactionResponse `json:"transaction,omitempty"`
+}
+
+// BulkCirculationResponse represents the per-copy results and the combined receipt of a bulk checkout or return
+type BulkCirculationResponse struct {
+	AllOrNothing bool                        `json:"all_or_nothing"`
+	Succeeded    int                         `json:"succeeded"`
+	Failed       int                         `json:"failed"`
+	Results      []BulkCirculationItemResult `json:"results"`
+	Receipt      CirculationReceiptResponse  `json:"receipt"`
+}
+
+// CirculationReceiptResponse represents the slip handed to the customer after a bulk checkout or return
+type CirculationReceiptResponse struct {
+	Title         string                   `json:"title"`
+	IssuedAt      time.Time                `json:"issued_at"`
+	CustomerCode  string                   `json:"customer_code,omitempty"`
+	CustomerName  string                   `json:"customer_name,omitempty"`
+	Items         []CirculationReceiptItem `json:"items"`
+	TotalLateFees float64                  `json:"total_late_fees"`
+	IssuedBy      string                   `json:"issued_by"`
+}
+
+// CirculationReceiptItem represents one copy on a circulation receipt
+type CirculationReceiptItem struct {
+	StockCode    string     `json:"stock_code"`
+	BookTitle    string     `json:"book_title"`
+	CustomerCode string     `json:"customer_code,omitempty"`
+	DueDate      time.Time  `json:"due_date"`
+	ReturnAt     *time.Time `json:"return_at,omitempty"`
+	LateFee      float64    `json:"late_fee,omitempty"`
 }
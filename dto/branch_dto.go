@@ -0,0 +1,66 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// BranchResponse represents the response for branch data
+type BranchResponse struct {
+	ID        uuid.UUID `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	Address   string    `json:"address,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BranchCreateRequest represents the request to create a branch
+type BranchCreateRequest struct {
+	Code    string `json:"code" validate:"required,alphanum,min=2,max=10"`
+	Name    string `json:"name" validate:"required,min=3,max=255"`
+	Address string `json:"address" validate:"omitempty,max=1000"`
+}
+
+// BranchUpdateRequest represents the request to update a branch
+type BranchUpdateRequest struct {
+	Code    *string `json:"code,omitempty" validate:"omitempty,alphanum,min=2,max=10"`
+	Name    *string `json:"name,omitempty" validate:"omitempty,min=3,max=255"`
+	Address *string `json:"address,omitempty" validate:"omitempty,max=1000"`
+}
+
+// BranchAvailabilityResponse represents how many copies of a book are at a branch
+type BranchAvailabilityResponse struct {
+	BranchID   *uuid.UUID `json:"branch_id"`
+	BranchCode string     `json:"branch_code,omitempty"`
+	BranchName string     `json:"branch_name,omitempty"`
+	Available  int64      `json:"available"`
+	Total      int64      `json:"total"`
+}
+
+// BranchTransferResponse represents the response for a transfer between branches
+type BranchTransferResponse struct {
+	ID           uuid.UUID          `json:"id"`
+	StockCode    string             `json:"stock_code"`
+	BookStock    *BookStockResponse `json:"book_stock,omitempty"`
+	FromBranchID uuid.UUID          `json:"from_branch_id"`
+	FromBranch   *BranchResponse    `json:"from_branch,omitempty"`
+	ToBranchID   uuid.UUID          `json:"to_branch_id"`
+	ToBranch     *BranchResponse    `json:"to_branch,omitempty"`
+	Status       string             `json:"status"`
+	Notes        string             `json:"notes,omitempty"`
+	RequestedBy  uuid.UUID          `json:"requested_by"`
+	RequestedAt  time.Time          `json:"requested_at"`
+	ShippedBy    *uuid.UUID         `json:"shipped_by,omitempty"`
+	ShippedAt    *time.Time         `json:"shipped_at,omitempty"`
+	ReceivedBy   *uuid.UUID         `json:"received_by,omitempty"`
+	ReceivedAt   *time.Time         `json:"received_at,omitempty"`
+}
+
+// BranchTransferCreateRequest represents the request to move a book stock to another branch
+type BranchTransferCreateRequest struct {
+	StockCode  string    `json:"stock_code" validate:"required,max=50"`
+	ToBranchID uuid.UUID `json:"to_branch_id" validate:"required"`
+	Notes      string    `json:"notes" validate:"omitempty,max=1000"`
+}
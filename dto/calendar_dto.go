@@ -0,0 +1,63 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OpeningHoursDayRequest represents the hours of one weekday
+// Leave opens_at and closes_at empty on closed days
+type OpeningHoursDayRequest struct {
+	Weekday  string `json:"weekday" validate:"required,oneof=Sunday Monday Tuesday Wednesday Thursday Friday Saturday"`
+	OpensAt  string `json:"opens_at" validate:"omitempty,datetime=15:04"`
+	ClosesAt string `json:"closes_at" validate:"omitempty,datetime=15:04"`
+	Closed   bool   `json:"closed"`
+}
+
+// OpeningHoursRequest replaces the weekly opening hours of a branch, or the library-wide ones without branch_id
+// Weekdays left out fall back to the library-wide hours
+type OpeningHoursRequest struct {
+	BranchID *uuid.UUID               `json:"branch_id"`
+	Days     []OpeningHoursDayRequest `json:"days" validate:"max=7,dive"`
+}
+
+// OpeningHoursResponse represents the effective hours of one weekday
+// source tells where they come from: branch, library or default (the LIBRARY_CLOSED_WEEKDAYS setting)
+type OpeningHoursResponse struct {
+	Weekday  string `json:"weekday"`
+	OpensAt  string `json:"opens_at,omitempty"`
+	ClosesAt string `json:"closes_at,omitempty"`
+	Closed   bool   `json:"closed"`
+	Source   string `json:"source"`
+}
+
+// ClosureCreateRequest represents the request to close a branch, or every branch without branch_id, for some days
+type ClosureCreateRequest struct {
+	BranchID  *uuid.UUID `json:"branch_id"`
+	StartDate string     `json:"start_date" validate:"required,datetime=2006-01-02"`
+	EndDate   string     `json:"end_date" validate:"omitempty,datetime=2006-01-02"`
+	Reason    string     `json:"reason" validate:"required,max=255"`
+}
+
+// ClosureResponse represents days a branch or the whole library is closed
+type ClosureResponse struct {
+	ID        uuid.UUID       `json:"id"`
+	BranchID  *uuid.UUID      `json:"branch_id,omitempty"`
+	Branch    *BranchResponse `json:"branch,omitempty"`
+	StartDate string          `json:"start_date"`
+	EndDate   string          `json:"end_date"`
+	Reason    string          `json:"reason"`
+	UserID    uuid.UUID       `json:"user_id"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+// CalendarDayResponse represents whether a branch is open on a date
+type CalendarDayResponse struct {
+	Date     string `json:"date"`
+	Weekday  string `json:"weekday"`
+	Open     bool   `json:"open"`
+	OpensAt  string `json:"opens_at,omitempty"`
+	ClosesAt string `json:"closes_at,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+}
@@ -10,19 +10,57 @@ type ChargeResponse struct {
 	ID                uuid.UUID                `json:"id"`
 	BookTransactionID uuid.UUID                `json:"book_transaction_id"`
 	BookTransaction   *BookTransactionResponse `json:"book_transaction,omitempty"`
+	Type              string                   `json:"type"`
 	DaysLate          int                      `json:"days_late"`
 	DailyLateFee      float64                  `json:"daily_late_fee"`
 	Total             float64                  `json:"total"`
 	UserID            uuid.UUID                `json:"user_id"`
 	User              *UserData                `json:"user,omitempty"`
 	CreatedAt         time.Time                `json:"created_at"`
+	FrozenAt          *time.Time               `json:"frozen_at,omitempty"`
+	// Replacement charges
+	ReplacementCost float64    `json:"replacement_cost,omitempty"`
+	ProcessingFee   float64    `json:"processing_fee,omitempty"`
+	ReversedAt      *time.Time `json:"reversed_at,omitempty"`
+	// Ledger summary
+	Paid          float64 `json:"paid"`
+	Refunded      float64 `json:"refunded"`
+	Waived        float64 `json:"waived"`
+	Balance       float64 `json:"balance"`
+	PaymentStatus string  `json:"payment_status"`
 }
 
 type ChargeCreateRequest struct {
 	BookTransactionID uuid.UUID `json:"book_transaction_id" validate:"required"`
-	DailyLateFee      float64   `json:"daily_late_fee" validate:"required,min=0"`
+	// Defaults to the fee of the loan policy applied at checkout
+	DailyLateFee *float64 `json:"daily_late_fee" validate:"omitempty,min=0"`
+	// Charges a loan that is already closed
+	Override bool `json:"override"`
 }
 
 type ChargeUpdateRequest struct {
 	DailyLateFee *float64 `json:"daily_late_fee,omitempty" validate:"omitempty,min=0"`
 }
+
+// ChargePreviewRequest asks what a late fee would be without saving it
+// as_of defaults to the return date of the loan, or now while it is still open
+type ChargePreviewRequest struct {
+	BookTransactionID uuid.UUID  `json:"book_transaction_id" validate:"required"`
+	DailyLateFee      *float64   `json:"daily_late_fee" validate:"omitempty,min=0"`
+	AsOf              *time.Time `json:"as_of"`
+}
+
+// ChargePreviewResponse represents a late fee calculation that has not been saved
+type ChargePreviewResponse struct {
+	BookTransactionID uuid.UUID  `json:"book_transaction_id"`
+	DueDate           time.Time  `json:"due_date"`
+	ReturnAt          *time.Time `json:"return_at,omitempty"`
+	AsOf              time.Time  `json:"as_of"`
+	DaysLate          int        `json:"days_late"`
+	DailyLateFee      float64    `json:"daily_late_fee"`
+	GracePeriodDays   int        `json:"grace_period_days"`
+	MaxLateFee        float64    `json:"max_late_fee"`
+	Total             float64    `json:"total"`
+	// Frozen reports whether a saved charge for this loan would be frozen
+	Frozen bool `json:"frozen"`
+}
@@ -7,28 +7,72 @@ import (
 )
 
 type CustomerResponse struct {
-	ID        uuid.UUID `json:"id"`
-	Code      string    `json:"code"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID       uuid.UUID `json:"id"`
+	Code     string    `json:"code"`
+	Name     string    `json:"name"`
+	Category string    `json:"category"`
+	// Contact details
+	Email       string     `json:"email,omitempty"`
+	Phone       string     `json:"phone,omitempty"`
+	Address     string     `json:"address,omitempty"`
+	DateOfBirth *time.Time `json:"date_of_birth,omitempty"`
+	// Memberships without an expiry date never expire
+	MembershipStartedAt *time.Time `json:"membership_started_at,omitempty"`
+	MembershipExpiresAt *time.Time `json:"membership_expires_at,omitempty"`
+	Status              string     `json:"status"`
+	StatusReason        string     `json:"status_reason,omitempty"`
+	AnonymizedAt        *time.Time `json:"anonymized_at,omitempty"`
+	GuardianID          *uuid.UUID `json:"guardian_id,omitempty"`
+	CreatedAt           time.Time  `json:"created_at"`
+	UpdatedAt           time.Time  `json:"updated_at"`
 }
 
 type CustomerWithTransactionsResponse struct {
 	ID               uuid.UUID                 `json:"id"`
 	Code             string                    `json:"code"`
 	Name             string                    `json:"name"`
+	Category         string                    `json:"category"`
 	CreatedAt        time.Time                 `json:"created_at"`
 	UpdatedAt        time.Time                 `json:"updated_at"`
 	BookTransactions []BookTransactionResponse `json:"book_transactions,omitempty"`
 }
 
+// CustomerCreateRequest represents the request to register a customer
+// phone may contain spaces, dashes, dots and parentheses, which are stripped
 type CustomerCreateRequest struct {
-	Code string `json:"code" validate:"required,min=3,max=50"`
-	Name string `json:"name" validate:"required,min=3,max=255"`
+	Code        string     `json:"code" validate:"required,min=3,max=50"`
+	Name        string     `json:"name" validate:"required,min=3,max=255"`
+	Category    string     `json:"category" validate:"omitempty,max=50"`
+	Email       string     `json:"email" validate:"omitempty,email,max=255"`
+	Phone       string     `json:"phone" validate:"omitempty,min=6,max=30"`
+	Address     string     `json:"address" validate:"omitempty,max=1000"`
+	DateOfBirth *time.Time `json:"date_of_birth"`
+	// Defaults to now
+	MembershipStartedAt *time.Time `json:"membership_started_at"`
+	// Leave empty for a membership that never expires
+	MembershipExpiresAt *time.Time `json:"membership_expires_at"`
 }
 
+// CustomerUpdateRequest represents the request to change a customer
+// Setting status to Active lifts a suspension; an expired membership stays Expired until renewed
 type CustomerUpdateRequest struct {
-	Code *string `json:"code,omitempty" validate:"omitempty,min=3,max=50"`
-	Name *string `json:"name,omitempty" validate:"omitempty,min=3,max=255"`
+	Code                *string    `json:"code,omitempty" validate:"omitempty,min=3,max=50"`
+	Name                *string    `json:"name,omitempty" validate:"omitempty,min=3,max=255"`
+	Category            *string    `json:"category,omitempty" validate:"omitempty,max=50"`
+	Email               *string    `json:"email,omitempty" validate:"omitempty,email,max=255"`
+	Phone               *string    `json:"phone,omitempty" validate:"omitempty,min=6,max=30"`
+	Address             *string    `json:"address,omitempty" validate:"omitempty,max=1000"`
+	DateOfBirth         *time.Time `json:"date_of_birth,omitempty"`
+	MembershipStartedAt *time.Time `json:"membership_started_at,omitempty"`
+	MembershipExpiresAt *time.Time `json:"membership_expires_at,omitempty"`
+	Status              *string    `json:"status,omitempty" validate:"omitempty,oneof=Active Suspended"`
+	StatusReason        *string    `json:"status_reason,omitempty" validate:"omitempty,max=255"`
+}
+
+// MembershipRenewRequest represents the request to extend a customer's membership
+// The membership runs for months from its current expiry, or from today once expired;
+// expires_at sets the new expiry date directly. Without either the default membership period applies.
+type MembershipRenewRequest struct {
+	Months    *int       `json:"months" validate:"omitempty,min=1,max=120"`
+	ExpiresAt *time.Time `json:"expires_at" validate:"excluded_with=Months"`
 }
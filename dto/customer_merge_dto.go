@@ -0,0 +1,46 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CustomerDuplicateResponse represents two customers that are likely the same person
+// score runs from 0 to 1; reasons tell which details match
+type CustomerDuplicateResponse struct {
+	Customer  CustomerResponse `json:"customer"`
+	Duplicate CustomerResponse `json:"duplicate"`
+	Score     float64          `json:"score"`
+	Reasons   []string         `json:"reasons"`
+}
+
+// CustomerMergeRequest represents the request to fold a duplicate customer into another
+// Loans, charges, payments, refunds, holds and notices of merged_id move to survivor_id,
+// after which merged_id is deleted
+type CustomerMergeRequest struct {
+	SurvivorID uuid.UUID `json:"survivor_id" validate:"required"`
+	MergedID   uuid.UUID `json:"merged_id" validate:"required,nefield=SurvivorID"`
+	Reason     string    `json:"reason" validate:"required,max=500"`
+}
+
+// CustomerMergeResponse represents the audit record of a merge
+type CustomerMergeResponse struct {
+	ID           uuid.UUID         `json:"id"`
+	SurvivorID   uuid.UUID         `json:"survivor_id"`
+	Survivor     *CustomerResponse `json:"survivor,omitempty"`
+	MergedID     uuid.UUID         `json:"merged_id"`
+	MergedCode   string            `json:"merged_code"`
+	MergedName   string            `json:"merged_name"`
+	Snapshot     json.RawMessage   `json:"snapshot"`
+	Transactions int               `json:"transactions"`
+	Charges      int               `json:"charges"`
+	Payments     int               `json:"payments"`
+	Refunds      int               `json:"refunds"`
+	Holds        int               `json:"holds"`
+	Reason       string            `json:"reason"`
+	UserID       uuid.UUID         `json:"user_id"`
+	User         *UserData         `json:"user,omitempty"`
+	CreatedAt    time.Time         `json:"created_at"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CustomerDataExport holds every personal record the library keeps about a customer
+type CustomerDataExport struct {
+	ExportedAt              time.Time                        `json:"exported_at"`
+	Customer                CustomerResponse                 `json:"customer"`
+	Loans                   []BookTransactionResponse        `json:"loans"`
+	Charges                 []ChargeBalanceResponse          `json:"charges"`
+	Payments                []PaymentResponse                `json:"payments"`
+	Holds                   []HoldResponse                   `json:"holds"`
+	Notifications           []NotificationResponse           `json:"notifications"`
+	NotificationPreferences []NotificationPreferenceResponse `json:"notification_preferences"`
+}
+
+// CustomerAnonymizeRequest represents the request to erase a customer's personal data
+type CustomerAnonymizeRequest struct {
+	Reason string `json:"reason" validate:"required,max=255"`
+}
+
+// RetentionRunResponse summarises a pass of the retention policy over inactive customers
+type RetentionRunResponse struct {
+	Anonymized int             `json:"anonymized"`
+	Skipped    []RetentionSkip `json:"skipped"`
+}
+
+// RetentionSkip is an inactive customer the retention policy could not anonymize yet
+type RetentionSkip struct {
+	CustomerID uuid.UUID `json:"customer_id"`
+	Code       string    `json:"code"`
+	Reason     string    `json:"reason"`
+}
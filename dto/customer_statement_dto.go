@@ -0,0 +1,47 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CustomerStatementResponse represents a customer's account statement over a period
+// The opening balance is what the customer owed when the period started; each entry moves the
+// running balance, ending at the closing balance
+type CustomerStatementResponse struct {
+	Customer       CustomerResponse          `json:"customer"`
+	From           time.Time                 `json:"from"`
+	To             time.Time                 `json:"to"`
+	GeneratedAt    time.Time                 `json:"generated_at"`
+	OpeningBalance float64                   `json:"opening_balance"`
+	TotalCharged   float64                   `json:"total_charged"`
+	TotalPaid      float64                   `json:"total_paid"`
+	TotalWaived    float64                   `json:"total_waived"`
+	TotalRefunded  float64                   `json:"total_refunded"`
+	ClosingBalance float64                   `json:"closing_balance"`
+	Outstanding    float64                   `json:"outstanding"`
+	CurrentLoans   []BookTransactionResponse `json:"current_loans"`
+	Charges        []ChargeBalanceResponse   `json:"charges"`
+	Entries        []StatementEntryResponse  `json:"entries"`
+	History        StatementHistoryResponse  `json:"history"`
+}
+
+// StatementEntryResponse represents one movement on a customer's account
+// Debits raise what the customer owes (charges, refunds), credits lower it (payments, waivers, discounts, reversals)
+type StatementEntryResponse struct {
+	Date        time.Time `json:"date"`
+	Type        string    `json:"type"` // Charge, Payment, Adjustment, Refund
+	ChargeID    uuid.UUID `json:"charge_id"`
+	Description string    `json:"description"`
+	Reference   string    `json:"reference,omitempty"`
+	Debit       float64   `json:"debit"`
+	Credit      float64   `json:"credit"`
+	Balance     float64   `json:"balance"`
+}
+
+// StatementHistoryResponse represents a page of the loans borrowed during the statement period
+type StatementHistoryResponse struct {
+	Items []BookTransactionResponse `json:"items"`
+	Meta  PaginationMeta            `json:"meta"`
+}
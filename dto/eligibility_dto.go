@@ -0,0 +1,36 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EligibilityReason explains why a customer may not borrow
+// Limit and Actual hold the configured threshold and the customer's value where they apply
+type EligibilityReason struct {
+	Code    string  `json:"code"`
+	Message string  `json:"message"`
+	Limit   float64 `json:"limit,omitempty"`
+	Actual  float64 `json:"actual,omitempty"`
+}
+
+// EligibilityResponse represents the outcome of the checkout eligibility checks for a customer
+type EligibilityResponse struct {
+	CustomerID uuid.UUID           `json:"customer_id"`
+	Eligible   bool                `json:"eligible"`
+	Reasons    []EligibilityReason `json:"reasons"`
+}
+
+// EligibilityOverrideResponse represents an audited checkout despite failed eligibility checks
+type EligibilityOverrideResponse struct {
+	ID                uuid.UUID         `json:"id"`
+	BookTransactionID uuid.UUID         `json:"book_transaction_id"`
+	CustomerID        uuid.UUID         `json:"customer_id"`
+	Customer          *CustomerResponse `json:"customer,omitempty"`
+	ReasonCodes       []string          `json:"reason_codes"`
+	Reason            string            `json:"reason"`
+	UserID            uuid.UUID         `json:"user_id"`
+	User              *UserData         `json:"user,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+}
@@ -0,0 +1,37 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// HoldResponse represents the response for a hold
+// Position is the place of a pending hold in the queue of its book, starting at 1
+type HoldResponse struct {
+	ID             uuid.UUID         `json:"id"`
+	BookID         uuid.UUID         `json:"book_id"`
+	Book           *BookRes          `json:"book,omitempty"`
+	CustomerID     uuid.UUID         `json:"customer_id"`
+	Customer       *CustomerResponse `json:"customer,omitempty"`
+	Status         string            `json:"status"`
+	Position       int64             `json:"position,omitempty"`
+	StockCode      *string           `json:"stock_code,omitempty"`
+	ReadyAt        *time.Time        `json:"ready_at,omitempty"`
+	PickupDeadline *time.Time        `json:"pickup_deadline,omitempty"`
+	ClosedAt       *time.Time        `json:"closed_at,omitempty"`
+	CreatedAt      time.Time         `json:"created_at"`
+}
+
+// HoldCreateRequest represents the request to place a hold on a book
+type HoldCreateRequest struct {
+	BookID     uuid.UUID `json:"book_id" validate:"required"`
+	CustomerID uuid.UUID `json:"customer_id" validate:"required"`
+}
+
+// HoldExpireResponse summarises a run of expiring holds whose pickup deadline has passed
+type HoldExpireResponse struct {
+	Expired    int `json:"expired"`
+	Reassigned int `json:"reassigned"`
+	Failed     int `json:"failed"`
+}
@@ -0,0 +1,26 @@
+package dto
+
+import "github.com/google/uuid"
+
+// GuardianRequest represents the request to make a customer the guardian of another
+type GuardianRequest struct {
+	GuardianID uuid.UUID `json:"guardian_id" validate:"required"`
+}
+
+// HouseholdResponse represents a guardian and their dependants with everything they have on loan and owe
+// Outstanding is what the household owes together, the amount the fine limit applies to
+type HouseholdResponse struct {
+	Guardian    CustomerResponse          `json:"guardian"`
+	Members     []HouseholdMemberResponse `json:"members"`
+	ActiveLoans int                       `json:"active_loans"`
+	Outstanding float64                   `json:"outstanding"`
+}
+
+// HouseholdMemberResponse represents the current loans and charges of one customer of a household
+type HouseholdMemberResponse struct {
+	Customer    CustomerResponse          `json:"customer"`
+	IsGuardian  bool                      `json:"is_guardian"`
+	Loans       []BookTransactionResponse `json:"loans"`
+	Charges     []ChargeBalanceResponse   `json:"charges"`
+	Outstanding float64                   `json:"outstanding"`
+}
@@ -0,0 +1,60 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InventorySessionResponse represents the response for an inventory session
+type InventorySessionResponse struct {
+	ID        uuid.UUID  `json:"id"`
+	Name      string     `json:"name"`
+	Notes     string     `json:"notes,omitempty"`
+	Status    string     `json:"status"`
+	BranchID  *uuid.UUID `json:"branch_id,omitempty"`
+	UserID    uuid.UUID  `json:"user_id"`
+	User      *UserData  `json:"user,omitempty"`
+	ScanCount int64      `json:"scan_count"`
+	StartedAt time.Time  `json:"started_at"`
+	ClosedAt  *time.Time `json:"closed_at,omitempty"`
+}
+
+// InventorySessionCreateRequest represents the request to start an inventory session
+// Sessions cover the staff member's own branch unless branch_id is given
+type InventorySessionCreateRequest struct {
+	Name     string     `json:"name" validate:"required,min=3,max=255"`
+	Notes    string     `json:"notes" validate:"omitempty,max=1000"`
+	BranchID *uuid.UUID `json:"branch_id"`
+}
+
+// InventoryScanRequest represents a batch of stock codes scanned on the shelves
+type InventoryScanRequest struct {
+	Codes []string `json:"codes" validate:"required,min=1,max=500,dive,required,max=50"`
+}
+
+// InventoryScanResponse represents the outcome of a scan batch
+type InventoryScanResponse struct {
+	Accepted     int64    `json:"accepted"`
+	Duplicates   int64    `json:"duplicates"`
+	UnknownCodes []string `json:"unknown_codes"`
+}
+
+// InventoryReportResponse represents the reconciliation of an inventory session
+type InventoryReportResponse struct {
+	Session            InventorySessionResponse `json:"session"`
+	ExpectedNotScanned []BookStockResponse      `json:"expected_not_scanned"`
+	ScannedButBorrowed []BookStockResponse      `json:"scanned_but_borrowed"`
+	UnknownCodes       []string                 `json:"unknown_codes"`
+}
+
+// InventoryMarkLostRequest represents the request to mark missing copies as Lost.
+// When codes is empty every missing copy of the session is marked.
+type InventoryMarkLostRequest struct {
+	Codes []string `json:"codes" validate:"omitempty,max=1000,dive,required,max=50"`
+}
+
+// InventoryMarkLostResponse represents the copies marked as Lost
+type InventoryMarkLostResponse struct {
+	Codes []string `json:"codes"`
+}
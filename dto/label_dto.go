@@ -0,0 +1,7 @@
+package dto
+
+// LabelSheetRequest represents the request to print a sheet of labels
+type LabelSheetRequest struct {
+	Codes  []string `json:"codes" validate:"required,min=1,max=240,dive,required"`
+	Format string   `json:"format" validate:"omitempty,oneof=code128 qr"`
+}
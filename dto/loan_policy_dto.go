@@ -0,0 +1,54 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoanPolicyResponse represents the response for a loan policy
+type LoanPolicyResponse struct {
+	ID               uuid.UUID `json:"id"`
+	Name             string    `json:"name"`
+	CustomerCategory string    `json:"customer_category"`
+	MaterialType     string    `json:"material_type"`
+	LoanPeriodDays   int       `json:"loan_period_days"`
+	MaxLoans         int       `json:"max_loans"`
+	DailyLateFee     float64   `json:"daily_late_fee"`
+	MaxRenewals      int       `json:"max_renewals"`
+	GracePeriodDays  int       `json:"grace_period_days"`
+	MaxLateFee       float64   `json:"max_late_fee"`
+	SkipClosedDays   bool      `json:"skip_closed_days"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
+
+// LoanPolicyCreateRequest represents the request to create a loan policy
+// Leaving customer_category or material_type empty makes the policy apply to any value;
+// a max_loans or max_late_fee of 0 means no limit
+type LoanPolicyCreateRequest struct {
+	Name             string  `json:"name" validate:"required,min=3,max=255"`
+	CustomerCategory string  `json:"customer_category" validate:"omitempty,max=50"`
+	MaterialType     string  `json:"material_type" validate:"omitempty,max=50"`
+	LoanPeriodDays   int     `json:"loan_period_days" validate:"required,min=1,max=365"`
+	MaxLoans         int     `json:"max_loans" validate:"min=0,max=1000"`
+	DailyLateFee     float64 `json:"daily_late_fee" validate:"min=0"`
+	MaxRenewals      int     `json:"max_renewals" validate:"min=0,max=100"`
+	GracePeriodDays  int     `json:"grace_period_days" validate:"min=0,max=365"`
+	MaxLateFee       float64 `json:"max_late_fee" validate:"min=0"`
+	SkipClosedDays   bool    `json:"skip_closed_days"`
+}
+
+// LoanPolicyUpdateRequest represents the request to update a loan policy
+type LoanPolicyUpdateRequest struct {
+	Name             *string  `json:"name,omitempty" validate:"omitempty,min=3,max=255"`
+	CustomerCategory *string  `json:"customer_category,omitempty" validate:"omitempty,max=50"`
+	MaterialType     *string  `json:"material_type,omitempty" validate:"omitempty,max=50"`
+	LoanPeriodDays   *int     `json:"loan_period_days,omitempty" validate:"omitempty,min=1,max=365"`
+	MaxLoans         *int     `json:"max_loans,omitempty" validate:"omitempty,min=0,max=1000"`
+	DailyLateFee     *float64 `json:"daily_late_fee,omitempty" validate:"omitempty,min=0"`
+	MaxRenewals      *int     `json:"max_renewals,omitempty" validate:"omitempty,min=0,max=100"`
+	GracePeriodDays  *int     `json:"grace_period_days,omitempty" validate:"omitempty,min=0,max=365"`
+	MaxLateFee       *float64 `json:"max_late_fee,omitempty" validate:"omitempty,min=0"`
+	SkipClosedDays   *bool    `json:"skip_closed_days,omitempty"`
+}
@@ -0,0 +1,79 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// NotificationResponse represents an entry in the notification delivery log
+type NotificationResponse struct {
+	ID                uuid.UUID         `json:"id"`
+	CustomerID        uuid.UUID         `json:"customer_id"`
+	Customer          *CustomerResponse `json:"customer,omitempty"`
+	Type              string            `json:"type"`
+	Channel           string            `json:"channel,omitempty"`
+	Recipient         string            `json:"recipient,omitempty"`
+	Reference         string            `json:"reference"`
+	BookTransactionID *uuid.UUID        `json:"book_transaction_id,omitempty"`
+	HoldID            *uuid.UUID        `json:"hold_id,omitempty"`
+	Subject           string            `json:"subject,omitempty"`
+	Body              string            `json:"body,omitempty"`
+	Status            string            `json:"status"`
+	Error             string            `json:"error,omitempty"`
+	CreatedAt         time.Time         `json:"created_at"`
+}
+
+// NotificationPreferenceRequest represents one channel a customer wants notices on
+// address is the email address, phone number or webhook URL; without one email and SMS go to the
+// customer's own email and phone, and webhooks to the library's default URL. Only admins may set an
+// address other than the customer's own, and webhook URLs must point at public hosts.
+type NotificationPreferenceRequest struct {
+	Channel string `json:"channel" validate:"required,oneof=Email SMS Webhook"`
+	Address string `json:"address" validate:"omitempty,max=255"`
+	Enabled *bool  `json:"enabled"`
+}
+
+// NotificationPreferencesRequest replaces all channel preferences of a customer
+// An empty list turns notices off
+type NotificationPreferencesRequest struct {
+	Channels []NotificationPreferenceRequest `json:"channels" validate:"max=3,dive"`
+}
+
+// NotificationPreferenceResponse represents a channel a customer receives notices on
+type NotificationPreferenceResponse struct {
+	Channel   string    `json:"channel"`
+	Address   string    `json:"address,omitempty"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// NotificationTemplateRequest represents the request to change the wording of a notice on a channel
+// subject and body are Go text templates, see NotificationTemplateResponse for the available fields
+type NotificationTemplateRequest struct {
+	Type    string `json:"type" validate:"required,oneof=DueReminder OverdueNotice HoldReady"`
+	Channel string `json:"channel" validate:"required,oneof=Email SMS Webhook"`
+	Subject string `json:"subject" validate:"omitempty,max=255"`
+	Body    string `json:"body" validate:"required"`
+}
+
+// NotificationTemplateResponse represents the wording used for a notice on a channel
+// Templates can use {{.RecipientName}}, {{.Borrower}}, {{.CustomerName}}, {{.CustomerCode}}, {{.BookTitle}},
+// {{.StockCode}}, {{.DueDate}}, {{.Days}}, {{.NoticeNumber}} and {{.PickupDeadline}}
+// Notices about a dependant go to their guardian: RecipientName is the guardian and Borrower the dependant
+type NotificationTemplateResponse struct {
+	Type      string     `json:"type"`
+	Channel   string     `json:"channel"`
+	Subject   string     `json:"subject"`
+	Body      string     `json:"body"`
+	Custom    bool       `json:"custom"`
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+}
+
+// NotificationRunResponse summarises a pass over due reminders, overdue notices and hold-ready notices
+type NotificationRunResponse struct {
+	DueReminders   int `json:"due_reminders"`
+	OverdueNotices int `json:"overdue_notices"`
+	HoldReady      int `json:"hold_ready"`
+	Failed         int `json:"failed"`
+}
@@ -0,0 +1,106 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentResponse represents the response for a payment
+type PaymentResponse struct {
+	ID            uuid.UUID         `json:"id"`
+	ChargeID      uuid.UUID         `json:"charge_id"`
+	CustomerID    uuid.UUID         `json:"customer_id"`
+	Customer      *CustomerResponse `json:"customer,omitempty"`
+	Amount        float64           `json:"amount"`
+	Method        string            `json:"method"`
+	ReceiptNumber string            `json:"receipt_number"`
+	Note          string            `json:"note,omitempty"`
+	UserID        uuid.UUID         `json:"user_id"`
+	User          *UserData         `json:"user,omitempty"`
+	CreatedAt     time.Time         `json:"created_at"`
+}
+
+// PaymentCreateRequest represents the request to record a full or partial payment of a charge
+type PaymentCreateRequest struct {
+	Amount float64 `json:"amount" validate:"required,gt=0"`
+	Method string  `json:"method" validate:"required,oneof=Cash Card Transfer Other"`
+	Note   string  `json:"note" validate:"omitempty,max=500"`
+}
+
+// ChargeAdjustmentRequest represents the request to waive or discount a charge
+// A Waiver forgives the whole outstanding balance and ignores amount; approved_by defaults to the current user
+type ChargeAdjustmentRequest struct {
+	Type       string     `json:"type" validate:"required,oneof=Waiver Discount"`
+	Amount     float64    `json:"amount" validate:"required_if=Type Discount,gte=0"`
+	Reason     string     `json:"reason" validate:"required,min=3,max=500"`
+	ApprovedBy *uuid.UUID `json:"approved_by"`
+}
+
+// ChargeAdjustmentResponse represents a recorded waiver or discount
+type ChargeAdjustmentResponse struct {
+	ID           uuid.UUID `json:"id"`
+	ChargeID     uuid.UUID `json:"charge_id"`
+	Type         string    `json:"type"`
+	Amount       float64   `json:"amount"`
+	Reason       string    `json:"reason"`
+	ApprovedByID uuid.UUID `json:"approved_by_id"`
+	ApprovedBy   *UserData `json:"approved_by,omitempty"`
+	UserID       uuid.UUID `json:"user_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// RefundResponse represents money paid back to a customer on a charge
+type RefundResponse struct {
+	ID         uuid.UUID `json:"id"`
+	ChargeID   uuid.UUID `json:"charge_id"`
+	CustomerID uuid.UUID `json:"customer_id"`
+	Amount     float64   `json:"amount"`
+	Method     string    `json:"method"`
+	Reason     string    `json:"reason"`
+	UserID     uuid.UUID `json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// ChargeBalanceResponse represents the ledger and outstanding balance of a charge
+// Paid is what the customer paid net of refunds
+type ChargeBalanceResponse struct {
+	ChargeID          uuid.UUID                  `json:"charge_id"`
+	BookTransactionID uuid.UUID                  `json:"book_transaction_id"`
+	Total             float64                    `json:"total"`
+	Paid              float64                    `json:"paid"`
+	Refunded          float64                    `json:"refunded"`
+	Waived            float64                    `json:"waived"`
+	Balance           float64                    `json:"balance"`
+	Status            string                     `json:"status"`
+	Payments          []PaymentResponse          `json:"payments"`
+	Adjustments       []ChargeAdjustmentResponse `json:"adjustments"`
+	Refunds           []RefundResponse           `json:"refunds"`
+}
+
+// CustomerBalanceResponse represents the outstanding balance of a customer across all charges
+type CustomerBalanceResponse struct {
+	CustomerID   uuid.UUID               `json:"customer_id"`
+	TotalCharged float64                 `json:"total_charged"`
+	TotalPaid    float64                 `json:"total_paid"`
+	TotalWaived  float64                 `json:"total_waived"`
+	Balance      float64                 `json:"balance"`
+	Charges      []ChargeBalanceResponse `json:"charges"`
+}
+
+// PaymentReceiptResponse represents a printable payment receipt
+type PaymentReceiptResponse struct {
+	ReceiptNumber string    `json:"receipt_number"`
+	IssuedAt      time.Time `json:"issued_at"`
+	CustomerCode  string    `json:"customer_code"`
+	CustomerName  string    `json:"customer_name"`
+	ChargeID      uuid.UUID `json:"charge_id"`
+	BookTitle     string    `json:"book_title,omitempty"`
+	StockCode     string    `json:"stock_code,omitempty"`
+	ChargeTotal   float64   `json:"charge_total"`
+	Amount        float64   `json:"amount"`
+	Method        string    `json:"method"`
+	Note          string    `json:"note,omitempty"`
+	BalanceAfter  float64   `json:"balance_after"`
+	ReceivedBy    string    `json:"received_by"`
+}
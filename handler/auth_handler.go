@@ -7,6 +7,7 @@ import (
 	"net/http"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
 )
 
 type AuthHandler struct {
@@ -92,3 +93,79 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		Data:    userData,
 	})
 }
+
+// LinkCustomer handles linking a member account to its customer record
+func (h *AuthHandler) LinkCustomer(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid user ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	var req dto.UserCustomerLinkRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	userData, err := h.authService.LinkCustomer(c, userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "User linked successfully",
+		Data:    userData,
+	})
+}
+
+// AssignBranch handles binding a staff account to a branch
+func (h *AuthHandler) AssignBranch(c *gin.Context) {
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid user ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	var req dto.UserBranchAssignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	userData, err := h.authService.AssignBranch(c, userID, req)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "User branch assigned successfully",
+		Data:    userData,
+	})
+}
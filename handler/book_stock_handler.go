@@ -3,6 +3,7 @@
 package handler
 
 import (
+	"errors"
 	"go-gin-simple-api/dto"
 	"go-gin-simple-api/lib"
 	"go-gin-simple-api/service"
@@ -111,7 +112,21 @@ func (h *BookStockHandler) GetAvailableByBookID(c *gin.Context) {
 		return
 	}
 
-	bookStocks, err := h.bookStockService.GetAvailableByBookID(bookID)
+	// Optionally restrict to copies located at a single branch
+	var branchID *uuid.UUID
+	if branchStr := c.Query("branch_id"); branchStr != "" {
+		id, err := uuid.Parse(branchStr)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, dto.ResponseError{
+				Status:  http.StatusBadRequest,
+				Message: "Invalid branch ID format",
+				Error:   map[string]string{"error": err.Error()}})
+			return
+		}
+		branchID = &id
+	}
+
+	bookStocks, err := h.bookStockService.GetAvailableByBookID(bookID, branchID)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, dto.ResponseError{
 			Status:  http.StatusInternalServerError,
@@ -127,6 +142,35 @@ func (h *BookStockHandler) GetAvailableByBookID(c *gin.Context) {
 	})
 }
 
+// GetAvailabilityByBookID handles retrieving per-branch copy counts of a book
+func (h *BookStockHandler) GetAvailabilityByBookID(c *gin.Context) {
+	idStr := c.Param("book_id")
+
+	bookID, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid book ID format",
+			Error:   map[string]string{"error": err.Error()}})
+		return
+	}
+
+	availability, err := h.bookStockService.GetAvailabilityByBookID(bookID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Failed to retrieve book availability",
+			Error:   map[string]string{"error": err.Error()}})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Book availability retrieved successfully",
+		Data:    availability,
+	})
+}
+
 // Create handles creating a new book stock
 func (h *BookStockHandler) Create(c *gin.Context) {
 	var req dto.BookStockCreateRequest
@@ -148,18 +192,71 @@ func (h *BookStockHandler) Create(c *gin.Context) {
 		return
 	}
 
-	_, err := h.bookStockService.Create(req)
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	bookStock, err := h.bookStockService.Create(user, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to create book stock",
 			Error:   map[string]string{"error": err.Error()}})
 		return
 	}
 
-	c.JSON(http.StatusCreated, dto.ResponseSuccess{
+	// Return the created stock so callers learn the generated code
+	c.JSON(http.StatusCreated, dto.ResponseData{
 		Status:  http.StatusCreated,
 		Message: "Book stock created successfully",
+		Data:    bookStock,
+	})
+}
+
+// BulkCreate handles adding several copies of a book with generated codes
+func (h *BookStockHandler) BulkCreate(c *gin.Context) {
+	var req dto.BookStockBulkCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.bookStockService.BulkCreate(user, req)
+	if err != nil {
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to create book stocks",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseData{
+		Status:  http.StatusCreated,
+		Message: "Book stocks created successfully",
+		Data:    result,
 	})
 }
 
@@ -188,10 +285,16 @@ func (h *BookStockHandler) Update(c *gin.Context) {
 		return
 	}
 
-	bookStock, err := h.bookStockService.Update(code, req)
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	bookStock, err := h.bookStockService.Update(code, user, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to update book stock",
 			Error:   map[string]string{"error": err.Error()},
 		})
@@ -209,10 +312,16 @@ func (h *BookStockHandler) Update(c *gin.Context) {
 func (h *BookStockHandler) Delete(c *gin.Context) {
 	code := c.Param("code")
 
-	err := h.bookStockService.Delete(code)
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	err := h.bookStockService.Delete(code, user)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to delete book stock",
 			Error:   map[string]string{"error": err.Error()},
 		})
@@ -249,22 +358,16 @@ func (h *BookStockHandler) UpdateStatus(c *gin.Context) {
 		return
 	}
 
-	// userData, exists := c.Get("userData")
-	// if !exists {
-	// 	c.JSON(http.StatusUnauthorized, dto.ResponseError{Status: http.StatusUnauthorized, Message: "User data not found"})
-	// 	return
-	// }
-
-	// user, ok := userData.(dto.UserData)
-	// if !ok {
-	// 	c.JSON(http.StatusInternalServerError, dto.ResponseError{Status: http.StatusInternalServerError, Message: "Invalid user data"})
-	// 	return
-	// }
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
 
-	bookStock, err := h.bookStockService.UpdateStatus(code, req)
+	bookStock, err := h.bookStockService.UpdateStatus(code, user, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to update book stock status",
 			Error:   map[string]string{"error": err.Error()},
 		})
@@ -277,3 +380,91 @@ func (h *BookStockHandler) UpdateStatus(c *gin.Context) {
 		Data:    bookStock,
 	})
 }
+
+// MarkFound handles marking a lost book stock as found
+func (h *BookStockHandler) MarkFound(c *gin.Context) {
+	code := c.Param("code")
+
+	var req dto.BookStockFoundRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	bookStock, err := h.bookStockService.MarkFound(code, user, req)
+	if err != nil {
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to mark book stock as found",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Book stock marked as found successfully",
+		Data:    bookStock,
+	})
+}
+
+// GetStatusHistory handles retrieving the status history of a book stock
+func (h *BookStockHandler) GetStatusHistory(c *gin.Context) {
+	code := c.Param("code")
+
+	histories, err := h.bookStockService.GetStatusHistory(code)
+	if err != nil {
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to retrieve book stock status history",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Book stock status history retrieved successfully",
+		Data:    histories,
+	})
+}
+
+// bookStockErrorStatus maps rejected status transitions to 409, other branches' copies to 403,
+// missing books or copies to 404, unusable code patterns to 400 and everything else to 500
+func bookStockErrorStatus(err error) int {
+	var transitionErr *service.StockTransitionError
+	if errors.As(err, &transitionErr) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, service.ErrBranchAccessDenied) {
+		return http.StatusForbidden
+	}
+	if errors.Is(err, service.ErrBookNotFound) || errors.Is(err, service.ErrBookStockNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, service.ErrInvalidCodePattern) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -1,6 +1,8 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
 	"go-gin-simple-api/dto"
 	"go-gin-simple-api/lib"
 	"go-gin-simple-api/service"
@@ -71,6 +73,10 @@ func (h *BookTransactionHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	if !authorizeCustomer(c, transaction.CustomerID) {
+		return
+	}
+
 	c.JSON(http.StatusOK, dto.ResponseData{
 		Status:  http.StatusOK,
 		Message: "Book transaction retrieved successfully",
@@ -92,10 +98,16 @@ func (h *BookTransactionHandler) GetByCustomerID(c *gin.Context) {
 		return
 	}
 
-	transactions, err := h.bookTransactionService.GetByCustomerID(customerID)
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	transactions, err := h.bookTransactionService.GetByCustomerID(user, customerID)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		status := transactionErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to retrieve book transactions",
 			Error:   map[string]string{"error": err.Error()},
 		})
@@ -183,10 +195,31 @@ func (h *BookTransactionHandler) Create(c *gin.Context) {
 		return
 	}
 
-	transaction, err := h.bookTransactionService.Create(req)
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.bookTransactionService.Create(user, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		// Tell the desk every check the customer failed
+		var eligibilityErr *service.EligibilityError
+		if errors.As(err, &eligibilityErr) {
+			reasons := make(map[string]string, len(eligibilityErr.Reasons))
+			for _, reason := range eligibilityErr.Reasons {
+				reasons[reason.Code] = reason.Message
+			}
+			c.JSON(http.StatusUnprocessableEntity, dto.ResponseError{
+				Status:  http.StatusUnprocessableEntity,
+				Message: "Customer is not eligible to borrow",
+				Error:   reasons,
+			})
+			return
+		}
+
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to create book transaction",
 			Error:   map[string]string{"error": err.Error()},
 		})
@@ -234,10 +267,16 @@ func (h *BookTransactionHandler) Update(c *gin.Context) {
 		return
 	}
 
-	transaction, err := h.bookTransactionService.Update(id, req)
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.bookTransactionService.Update(id, user, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		status := transactionErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to update book transaction",
 			Error:   map[string]string{"error": err.Error()},
 		})
@@ -267,8 +306,9 @@ func (h *BookTransactionHandler) Delete(c *gin.Context) {
 
 	err = h.bookTransactionService.Delete(id)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		status := transactionErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to delete book transaction",
 			Error:   map[string]string{"error": err.Error()},
 		})
@@ -315,10 +355,16 @@ func (h *BookTransactionHandler) UpdateStatus(c *gin.Context) {
 		return
 	}
 
-	transaction, err := h.bookTransactionService.UpdateStatus(id, req)
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.bookTransactionService.UpdateStatus(id, user, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		status := transactionErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to update book transaction status",
 			Error:   map[string]string{"error": err.Error()},
 		})
@@ -356,10 +402,26 @@ func (h *BookTransactionHandler) ReturnBook(c *gin.Context) {
 		return
 	}
 
-	transaction, err := h.bookTransactionService.ReturnBook(id, req)
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.bookTransactionService.ReturnBook(id, user, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		status := transactionErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to return book",
 			Error:   map[string]string{"error": err.Error()},
 		})
@@ -373,6 +435,151 @@ func (h *BookTransactionHandler) ReturnBook(c *gin.Context) {
 	})
 }
 
+// ReportLoss handles closing a loan whose copy was lost or came back damaged
+func (h *BookTransactionHandler) ReportLoss(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid transaction ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	var req dto.BookTransactionLossRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.bookTransactionService.ReportLoss(id, user, req)
+	if err != nil {
+		status := transactionErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to report loss",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Loss reported successfully",
+		Data:    transaction,
+	})
+}
+
+// Correct handles an explicit correction of a closed loan
+func (h *BookTransactionHandler) Correct(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid transaction ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	var req dto.BookTransactionCorrectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.bookTransactionService.Correct(id, user, req)
+	if err != nil {
+		status := transactionErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to correct book transaction",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Book transaction corrected successfully",
+		Data:    transaction,
+	})
+}
+
+// GetEvents handles retrieving the status history of a loan
+func (h *BookTransactionHandler) GetEvents(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid transaction ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	events, err := h.bookTransactionService.GetEvents(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Failed to retrieve book transaction events",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Book transaction events retrieved successfully",
+		Data:    events,
+	})
+}
+
 // GetOverdueTransactions handles retrieving all overdue transactions
 func (h *BookTransactionHandler) GetOverdueTransactions(c *gin.Context) {
 	transactions, err := h.bookTransactionService.GetOverdueTransactions()
@@ -391,3 +598,278 @@ func (h *BookTransactionHandler) GetOverdueTransactions(c *gin.Context) {
 		Data:    transactions,
 	})
 }
+
+// Renew handles extending the due date of a loan
+func (h *BookTransactionHandler) Renew(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid transaction ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	transaction, err := h.bookTransactionService.Renew(id, user)
+	if err != nil {
+		status := transactionErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to renew loan",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Loan renewed successfully",
+		Data:    transaction,
+	})
+}
+
+// GetRenewals handles retrieving the renewal history of a loan
+func (h *BookTransactionHandler) GetRenewals(c *gin.Context) {
+	idStr := c.Param("id")
+
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid transaction ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	renewals, err := h.bookTransactionService.GetRenewals(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Failed to retrieve loan renewals",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Loan renewals retrieved successfully",
+		Data:    renewals,
+	})
+}
+
+// transactionErrorStatus maps refused loan operations to 409 and falls back to the book stock mapping
+func transactionErrorStatus(err error) int {
+	var transitionErr *service.LoanTransitionError
+	if errors.Is(err, service.ErrRenewalNotAllowed) || errors.Is(err, service.ErrLoanClosed) || errors.As(err, &transitionErr) {
+		return http.StatusConflict
+	}
+	if errors.Is(err, service.ErrCustomerAccessDenied) {
+		return http.StatusForbidden
+	}
+	return bookStockErrorStatus(err)
+}
+
+// CheckEligibility handles running the checkout eligibility checks for a customer
+// An optional stock_code query parameter applies the loan limit of the policy for that copy
+func (h *BookTransactionHandler) CheckEligibility(c *gin.Context) {
+	customerID, ok := parseCustomerID(c)
+	if !ok || !authorizeCustomer(c, customerID) {
+		return
+	}
+
+	eligibility, err := h.bookTransactionService.CheckEligibility(customerID, c.Query("stock_code"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Failed to check eligibility",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Eligibility checked successfully",
+		Data:    eligibility,
+	})
+}
+
+// GetEligibilityOverrides handles retrieving the audited eligibility overrides of a customer
+func (h *BookTransactionHandler) GetEligibilityOverrides(c *gin.Context) {
+	customerID, ok := parseCustomerID(c)
+	if !ok {
+		return
+	}
+
+	overrides, err := h.bookTransactionService.GetEligibilityOverrides(customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve eligibility overrides",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Eligibility overrides retrieved successfully",
+		Data:    overrides,
+	})
+}
+
+// BulkCheckout handles lending a stack of copies to one customer
+// The combined receipt is returned as a printable PDF with ?format=pdf
+func (h *BookTransactionHandler) BulkCheckout(c *gin.Context) {
+	var req dto.BulkCheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.bookTransactionService.BulkCheckout(user, req)
+	h.writeBulkResponse(c, response, err, "Bulk checkout", "loans")
+}
+
+// BulkReturn handles returning a stack of copies
+// The combined receipt is returned as a printable PDF with ?format=pdf
+func (h *BookTransactionHandler) BulkReturn(c *gin.Context) {
+	var req dto.BulkReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	response, err := h.bookTransactionService.BulkReturn(user, req)
+	h.writeBulkResponse(c, response, err, "Bulk return", "returns")
+}
+
+// writeBulkResponse reports the outcome of a bulk checkout or return
+func (h *BookTransactionHandler) writeBulkResponse(c *gin.Context, response *dto.BulkCirculationResponse, err error, operation, filename string) {
+	if err != nil {
+		// An all-or-nothing batch that was rolled back lists the error of every copy
+		if errors.Is(err, service.ErrBatchRolledBack) && response != nil {
+			results := make(map[string]string, len(response.Results))
+			for _, result := range response.Results {
+				results[result.StockCode] = result.Error
+			}
+			c.JSON(http.StatusConflict, dto.ResponseError{
+				Status:  http.StatusConflict,
+				Message: operation + " failed; no copies were processed",
+				Error:   results,
+			})
+			return
+		}
+
+		status := transactionErrorStatus(err)
+		if response == nil {
+			status = http.StatusNotFound
+		}
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: operation + " failed",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	if c.Query("format") == "pdf" {
+		receipt, err := h.bookTransactionService.CirculationReceiptPDF(response)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, dto.ResponseError{
+				Status:  http.StatusInternalServerError,
+				Message: "Failed to generate receipt",
+				Error:   map[string]string{"error": err.Error()},
+			})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="%s-%s.pdf"`, filename, response.Receipt.IssuedAt.Format("20060102-150405")))
+		c.Data(http.StatusOK, "application/pdf", receipt)
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: fmt.Sprintf("%s completed: %d succeeded, %d failed", operation, response.Succeeded, response.Failed),
+		Data:    response,
+	})
+}
+
+// GetMyLoans handles retrieving the loans of the customer linked to the authenticated member
+func (h *BookTransactionHandler) GetMyLoans(c *gin.Context) {
+	customerID, ok := getLinkedCustomerID(c)
+	if !ok {
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	transactions, err := h.bookTransactionService.GetByCustomerID(user, customerID)
+	if err != nil {
+		status := transactionErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to retrieve loans",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Loans retrieved successfully",
+		Data:    transactions,
+	})
+}
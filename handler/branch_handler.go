@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/service"
+	"go-gin-simple-api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BranchHandler struct {
+	branchService service.BranchService
+}
+
+func NewBranchHandler(branchService service.BranchService) *BranchHandler {
+	return &BranchHandler{
+		branchService: branchService,
+	}
+}
+
+// GetAll handles retrieving all branches with pagination, search, and filter
+func (h *BranchHandler) GetAll(c *gin.Context) {
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	search := c.Query("search")
+	filterStr := c.Query("filter")
+
+	// Parse filters
+	filters := lib.ParseFilterString(filterStr)
+
+	result, err := h.branchService.GetAll(page, perPage, search, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve branches",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetByID handles retrieving a branch by ID
+func (h *BranchHandler) GetByID(c *gin.Context) {
+	id, ok := parseBranchID(c)
+	if !ok {
+		return
+	}
+
+	branch, err := h.branchService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Branch not found",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Branch retrieved successfully",
+		Data:    branch,
+	})
+}
+
+// Create handles creating a new branch
+func (h *BranchHandler) Create(c *gin.Context) {
+	var req dto.BranchCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	branch, err := h.branchService.Create(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to create branch",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseData{
+		Status:  http.StatusCreated,
+		Message: "Branch created successfully",
+		Data:    branch,
+	})
+}
+
+// Update handles updating a branch
+func (h *BranchHandler) Update(c *gin.Context) {
+	id, ok := parseBranchID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.BranchUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	branch, err := h.branchService.Update(id, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to update branch",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Branch updated successfully",
+		Data:    branch,
+	})
+}
+
+// Delete handles deleting a branch
+func (h *BranchHandler) Delete(c *gin.Context) {
+	id, ok := parseBranchID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.branchService.Delete(id); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to delete branch",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess{
+		Status:  http.StatusOK,
+		Message: "Branch deleted successfully",
+	})
+}
+
+func parseBranchID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid branch ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
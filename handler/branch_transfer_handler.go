@@ -0,0 +1,178 @@
+package handler
+
+import (
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/service"
+	"go-gin-simple-api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type BranchTransferHandler struct {
+	transferService service.BranchTransferService
+}
+
+func NewBranchTransferHandler(transferService service.BranchTransferService) *BranchTransferHandler {
+	return &BranchTransferHandler{
+		transferService: transferService,
+	}
+}
+
+// GetAll handles retrieving all transfers with pagination, search, and filter
+func (h *BranchTransferHandler) GetAll(c *gin.Context) {
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	search := c.Query("search")
+	filterStr := c.Query("filter")
+
+	// Parse filters
+	filters := lib.ParseFilterString(filterStr)
+
+	result, err := h.transferService.GetAll(page, perPage, search, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve transfers",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetByID handles retrieving a transfer by ID
+func (h *BranchTransferHandler) GetByID(c *gin.Context) {
+	id, ok := parseTransferID(c)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.transferService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Transfer not found",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Transfer retrieved successfully",
+		Data:    transfer,
+	})
+}
+
+// Create handles requesting a transfer of a book stock to another branch
+func (h *BranchTransferHandler) Create(c *gin.Context) {
+	var req dto.BranchTransferCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	transfer, err := h.transferService.Request(user, req)
+	if err != nil {
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to request transfer",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseData{
+		Status:  http.StatusCreated,
+		Message: "Transfer requested successfully",
+		Data:    transfer,
+	})
+}
+
+// Ship handles sending a requested transfer on its way
+func (h *BranchTransferHandler) Ship(c *gin.Context) {
+	h.advance(c, h.transferService.Ship, "Failed to ship transfer", "Transfer shipped successfully")
+}
+
+// Receive handles confirming the arrival of a transfer
+func (h *BranchTransferHandler) Receive(c *gin.Context) {
+	h.advance(c, h.transferService.Receive, "Failed to receive transfer", "Transfer received successfully")
+}
+
+// Cancel handles cancelling a transfer that has not been shipped
+func (h *BranchTransferHandler) Cancel(c *gin.Context) {
+	h.advance(c, h.transferService.Cancel, "Failed to cancel transfer", "Transfer cancelled successfully")
+}
+
+// advance runs a workflow step on the transfer identified in the path
+func (h *BranchTransferHandler) advance(
+	c *gin.Context,
+	step func(id uuid.UUID, actor dto.UserData) (*dto.BranchTransferResponse, error),
+	failureMessage, successMessage string,
+) {
+	id, ok := parseTransferID(c)
+	if !ok {
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	transfer, err := step(id, user)
+	if err != nil {
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: failureMessage,
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: successMessage,
+		Data:    transfer,
+	})
+}
+
+func parseTransferID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid transfer ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
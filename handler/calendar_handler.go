@@ -0,0 +1,248 @@
+package handler
+
+import (
+	"errors"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/service"
+	"go-gin-simple-api/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CalendarHandler struct {
+	calendarService service.CalendarService
+}
+
+func NewCalendarHandler(calendarService service.CalendarService) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+	}
+}
+
+// GetOpeningHours handles retrieving the weekly opening hours of a branch, or the library-wide ones
+func (h *CalendarHandler) GetOpeningHours(c *gin.Context) {
+	branchID, ok := parseBranchQuery(c)
+	if !ok {
+		return
+	}
+
+	hours, err := h.calendarService.GetOpeningHours(branchID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve opening hours",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Opening hours retrieved successfully",
+		Data:    hours,
+	})
+}
+
+// SetOpeningHours handles replacing the weekly opening hours of a branch, or the library-wide ones
+func (h *CalendarHandler) SetOpeningHours(c *gin.Context) {
+	var req dto.OpeningHoursRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	hours, err := h.calendarService.SetOpeningHours(user, req)
+	if err != nil {
+		status := calendarErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to update opening hours",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Opening hours updated successfully",
+		Data:    hours,
+	})
+}
+
+// GetClosures handles retrieving the closures of a branch between two dates, library-wide ones included
+func (h *CalendarHandler) GetClosures(c *gin.Context) {
+	branchID, ok := parseBranchQuery(c)
+	if !ok {
+		return
+	}
+
+	closures, err := h.calendarService.GetClosures(branchID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		status := calendarErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to retrieve closures",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Closures retrieved successfully",
+		Data:    closures,
+	})
+}
+
+// CreateClosure handles closing a branch, or the whole library, for one or more days
+func (h *CalendarHandler) CreateClosure(c *gin.Context) {
+	var req dto.ClosureCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	closure, err := h.calendarService.CreateClosure(user, req)
+	if err != nil {
+		status := calendarErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to create closure",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseData{
+		Status:  http.StatusCreated,
+		Message: "Closure created successfully",
+		Data:    closure,
+	})
+}
+
+// DeleteClosure handles reopening the days of a closure
+func (h *CalendarHandler) DeleteClosure(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid closure ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	if err := h.calendarService.DeleteClosure(user, id); err != nil {
+		status := calendarErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to delete closure",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess{
+		Status:  http.StatusOK,
+		Message: "Closure deleted successfully",
+	})
+}
+
+// GetDays handles listing whether a branch is open on each day between two dates
+func (h *CalendarHandler) GetDays(c *gin.Context) {
+	branchID, ok := parseBranchQuery(c)
+	if !ok {
+		return
+	}
+
+	days, err := h.calendarService.GetDays(branchID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		status := calendarErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to retrieve calendar",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Calendar retrieved successfully",
+		Data:    days,
+	})
+}
+
+// parseBranchQuery reads the optional branch_id query parameter; nil means library-wide
+func parseBranchQuery(c *gin.Context) (*uuid.UUID, bool) {
+	branchStr := c.Query("branch_id")
+	if branchStr == "" {
+		return nil, true
+	}
+
+	id, err := uuid.Parse(branchStr)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid branch ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return nil, false
+	}
+	return &id, true
+}
+
+func calendarErrorStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidCalendar) {
+		return http.StatusBadRequest
+	}
+	if errors.Is(err, service.ErrBranchAccessDenied) {
+		return http.StatusForbidden
+	}
+	return http.StatusInternalServerError
+}
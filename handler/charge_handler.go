@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"go-gin-simple-api/dto"
 	"go-gin-simple-api/lib"
 	"go-gin-simple-api/service"
@@ -176,8 +177,12 @@ func (h *ChargeHandler) Create(c *gin.Context) {
 
 	charge, err := h.chargeService.Create(user.Email, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrDuplicateLateFee) || errors.Is(err, service.ErrLoanClosed) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to create charge",
 			Error:   map[string]string{"error": err.Error()},
 		})
@@ -227,8 +232,12 @@ func (h *ChargeHandler) Update(c *gin.Context) {
 
 	charge, err := h.chargeService.Update(id, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrChargeFrozen) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to update charge",
 			Error:   map[string]string{"error": err.Error()},
 		})
@@ -271,3 +280,42 @@ func (h *ChargeHandler) Delete(c *gin.Context) {
 		Message: "Charge deleted successfully",
 	})
 }
+
+// Preview handles calculating a late fee without saving a charge
+func (h *ChargeHandler) Preview(c *gin.Context) {
+	var req dto.ChargePreviewRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	preview, err := h.chargeService.Preview(req)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Failed to preview charge",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Charge preview calculated successfully",
+		Data:    preview,
+	})
+}
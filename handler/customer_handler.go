@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"errors"
 	"go-gin-simple-api/dto"
 	"go-gin-simple-api/lib"
 	"go-gin-simple-api/service"
 	"go-gin-simple-api/utils"
+	"io"
 	"net/http"
 	"strconv"
 
@@ -61,6 +63,10 @@ func (h *CustomerHandler) GetByID(c *gin.Context) {
 		return
 	}
 
+	if !authorizeCustomer(c, id) {
+		return
+	}
+
 	customer, err := h.customerService.GetByID(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, dto.ResponseError{
@@ -92,6 +98,10 @@ func (h *CustomerHandler) GetByIDWithTransactions(c *gin.Context) {
 		return
 	}
 
+	if !authorizeCustomer(c, id) {
+		return
+	}
+
 	customer, err := h.customerService.GetByIDWithTransactions(id)
 	if err != nil {
 		c.JSON(http.StatusNotFound, dto.ResponseError{
@@ -123,6 +133,10 @@ func (h *CustomerHandler) GetByCode(c *gin.Context) {
 		return
 	}
 
+	if !authorizeCustomer(c, customer.ID) {
+		return
+	}
+
 	c.JSON(http.StatusOK, dto.ResponseData{
 		Status:  http.StatusOK,
 		Message: "Customer retrieved successfully",
@@ -154,8 +168,9 @@ func (h *CustomerHandler) Create(c *gin.Context) {
 
 	customer, err := h.customerService.Create(req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		status := customerErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to create customer",
 			Error:   map[string]string{"error": err.Error()},
 		})
@@ -205,8 +220,9 @@ func (h *CustomerHandler) Update(c *gin.Context) {
 
 	customer, err := h.customerService.Update(id, req)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, dto.ResponseError{
-			Status:  http.StatusInternalServerError,
+		status := customerErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
 			Message: "Failed to update customer",
 			Error:   map[string]string{"error": err.Error()},
 		})
@@ -249,3 +265,56 @@ func (h *CustomerHandler) Delete(c *gin.Context) {
 		Message: "Customer deleted successfully",
 	})
 }
+
+// RenewMembership handles extending a customer's membership
+func (h *CustomerHandler) RenewMembership(c *gin.Context) {
+	id, ok := parseCustomerID(c)
+	if !ok {
+		return
+	}
+
+	// The body is optional; without it the default membership period applies
+	var req dto.MembershipRenewRequest
+	if err := c.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	customer, err := h.customerService.RenewMembership(id, req)
+	if err != nil {
+		status := customerErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to renew membership",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Membership renewed successfully",
+		Data:    customer,
+	})
+}
+
+func customerErrorStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidCustomerDetails) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
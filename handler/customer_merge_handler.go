@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"errors"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/service"
+	"go-gin-simple-api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CustomerMergeHandler struct {
+	customerMergeService service.CustomerMergeService
+}
+
+func NewCustomerMergeHandler(customerMergeService service.CustomerMergeService) *CustomerMergeHandler {
+	return &CustomerMergeHandler{
+		customerMergeService: customerMergeService,
+	}
+}
+
+// GetDuplicates handles listing the pairs of customers that are likely the same person
+func (h *CustomerMergeHandler) GetDuplicates(c *gin.Context) {
+	duplicates, err := h.customerMergeService.FindDuplicates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to find duplicate customers",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Duplicate customers retrieved successfully",
+		Data:    duplicates,
+	})
+}
+
+// GetDuplicatesOf handles listing the customers that are likely the same person as a customer
+func (h *CustomerMergeHandler) GetDuplicatesOf(c *gin.Context) {
+	id, ok := parseCustomerID(c)
+	if !ok {
+		return
+	}
+
+	duplicates, err := h.customerMergeService.FindDuplicatesOf(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Failed to find duplicate customers",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Duplicate customers retrieved successfully",
+		Data:    duplicates,
+	})
+}
+
+// Merge handles folding a duplicate customer into another
+func (h *CustomerMergeHandler) Merge(c *gin.Context) {
+	var req dto.CustomerMergeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	merge, err := h.customerMergeService.Merge(user, req)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, service.ErrCustomerMergeConflict) {
+			status = http.StatusConflict
+		}
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to merge customers",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseData{
+		Status:  http.StatusCreated,
+		Message: "Customers merged successfully",
+		Data:    merge,
+	})
+}
+
+// GetAll handles retrieving the audit records of customer merges with pagination, search, and filter
+func (h *CustomerMergeHandler) GetAll(c *gin.Context) {
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	search := c.Query("search")
+	filterStr := c.Query("filter")
+
+	// Parse filters
+	filters := lib.ParseFilterString(filterStr)
+
+	result, err := h.customerMergeService.GetAll(page, perPage, search, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve customer merges",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetByID handles retrieving the audit record of a customer merge
+func (h *CustomerMergeHandler) GetByID(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid customer merge ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	merge, err := h.customerMergeService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Customer merge not found",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Customer merge retrieved successfully",
+		Data:    merge,
+	})
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/service"
+	"go-gin-simple-api/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CustomerPrivacyHandler struct {
+	customerPrivacyService service.CustomerPrivacyService
+}
+
+func NewCustomerPrivacyHandler(customerPrivacyService service.CustomerPrivacyService) *CustomerPrivacyHandler {
+	return &CustomerPrivacyHandler{
+		customerPrivacyService: customerPrivacyService,
+	}
+}
+
+// Export handles exporting the personal data kept about a customer, as JSON or with ?format=zip as a ZIP archive
+func (h *CustomerPrivacyHandler) Export(c *gin.Context) {
+	customerID, ok := parseCustomerID(c)
+	if !ok || !authorizeCustomer(c, customerID) {
+		return
+	}
+
+	h.writeExport(c, customerID)
+}
+
+// ExportMine handles exporting the personal data of the customer linked to the authenticated member
+func (h *CustomerPrivacyHandler) ExportMine(c *gin.Context) {
+	customerID, ok := getLinkedCustomerID(c)
+	if !ok {
+		return
+	}
+
+	h.writeExport(c, customerID)
+}
+
+func (h *CustomerPrivacyHandler) writeExport(c *gin.Context, customerID uuid.UUID) {
+	if c.Query("format") == "zip" {
+		archive, err := h.customerPrivacyService.ExportZip(customerID)
+		if err != nil {
+			c.JSON(http.StatusNotFound, dto.ResponseError{
+				Status:  http.StatusNotFound,
+				Message: "Failed to export customer data",
+				Error:   map[string]string{"error": err.Error()},
+			})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="customer-%s.zip"`, customerID))
+		c.Data(http.StatusOK, "application/zip", archive)
+		return
+	}
+
+	export, err := h.customerPrivacyService.Export(customerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Failed to export customer data",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Customer data exported successfully",
+		Data:    export,
+	})
+}
+
+// Anonymize handles erasing the personal data of a customer while keeping their loans for statistics
+func (h *CustomerPrivacyHandler) Anonymize(c *gin.Context) {
+	customerID, ok := parseCustomerID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.CustomerAnonymizeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	customer, err := h.customerPrivacyService.Anonymize(customerID, req)
+	if err != nil {
+		status := customerPrivacyErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to anonymize customer",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Customer anonymized successfully",
+		Data:    customer,
+	})
+}
+
+// AnonymizeInactive handles running the retention policy now instead of waiting for the scheduler
+func (h *CustomerPrivacyHandler) AnonymizeInactive(c *gin.Context) {
+	result, err := h.customerPrivacyService.AnonymizeInactive()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to anonymize inactive customers",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Inactive customers anonymized successfully",
+		Data:    result,
+	})
+}
+
+func customerPrivacyErrorStatus(err error) int {
+	if errors.Is(err, service.ErrCustomerNotAnonymizable) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
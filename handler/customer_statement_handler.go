@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/service"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type CustomerStatementHandler struct {
+	customerStatementService service.CustomerStatementService
+}
+
+func NewCustomerStatementHandler(customerStatementService service.CustomerStatementService) *CustomerStatementHandler {
+	return &CustomerStatementHandler{
+		customerStatementService: customerStatementService,
+	}
+}
+
+// GetStatement handles retrieving a customer's account statement between ?from and ?to,
+// as JSON with a paginated reading history or with ?format=pdf for printing
+func (h *CustomerStatementHandler) GetStatement(c *gin.Context) {
+	customerID, ok := parseCustomerID(c)
+	if !ok || !authorizeCustomer(c, customerID) {
+		return
+	}
+
+	h.writeStatement(c, customerID)
+}
+
+// GetMyStatement handles retrieving the account statement of the customer linked to the authenticated member
+func (h *CustomerStatementHandler) GetMyStatement(c *gin.Context) {
+	customerID, ok := getLinkedCustomerID(c)
+	if !ok {
+		return
+	}
+
+	h.writeStatement(c, customerID)
+}
+
+func (h *CustomerStatementHandler) writeStatement(c *gin.Context, customerID uuid.UUID) {
+	from := c.Query("from")
+	to := c.Query("to")
+
+	if c.Query("format") == "pdf" {
+		statement, err := h.customerStatementService.GetStatementPDF(customerID, from, to)
+		if err != nil {
+			status := customerStatementErrorStatus(err)
+			c.JSON(status, dto.ResponseError{
+				Status:  status,
+				Message: "Failed to generate statement",
+				Error:   map[string]string{"error": err.Error()},
+			})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="statement-%s.pdf"`, customerID))
+		c.Data(http.StatusOK, "application/pdf", statement)
+		return
+	}
+
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+
+	statement, err := h.customerStatementService.GetStatement(customerID, from, to, page, perPage)
+	if err != nil {
+		status := customerStatementErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to retrieve statement",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Statement retrieved successfully",
+		Data:    statement,
+	})
+}
+
+func customerStatementErrorStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidStatementPeriod) {
+		return http.StatusBadRequest
+	}
+	return http.StatusNotFound
+}
@@ -0,0 +1,234 @@
+package handler
+
+import (
+	"errors"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/service"
+	"go-gin-simple-api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HoldHandler struct {
+	holdService service.HoldService
+}
+
+func NewHoldHandler(holdService service.HoldService) *HoldHandler {
+	return &HoldHandler{
+		holdService: holdService,
+	}
+}
+
+// GetAll handles retrieving all holds with pagination, search, and filter
+func (h *HoldHandler) GetAll(c *gin.Context) {
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	search := c.Query("search")
+	filterStr := c.Query("filter")
+
+	// Parse filters
+	filters := lib.ParseFilterString(filterStr)
+
+	result, err := h.holdService.GetAll(page, perPage, search, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve holds",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetByID handles retrieving a hold by ID
+func (h *HoldHandler) GetByID(c *gin.Context) {
+	id, ok := parseHoldID(c)
+	if !ok {
+		return
+	}
+
+	hold, err := h.holdService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Hold not found",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Hold retrieved successfully",
+		Data:    hold,
+	})
+}
+
+// GetByBookID handles retrieving the hold queue of a book
+func (h *HoldHandler) GetByBookID(c *gin.Context) {
+	bookID, err := uuid.Parse(c.Param("book_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid book ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	holds, err := h.holdService.GetByBookID(bookID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve holds",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Holds retrieved successfully",
+		Data:    holds,
+	})
+}
+
+// GetMyHolds handles retrieving the holds of the customer linked to the authenticated member
+func (h *HoldHandler) GetMyHolds(c *gin.Context) {
+	customerID, ok := getLinkedCustomerID(c)
+	if !ok {
+		return
+	}
+
+	holds, err := h.holdService.GetByCustomerID(customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve holds",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Holds retrieved successfully",
+		Data:    holds,
+	})
+}
+
+// Create handles placing a hold on a book
+func (h *HoldHandler) Create(c *gin.Context) {
+	var req dto.HoldCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	hold, err := h.holdService.Create(req)
+	if err != nil {
+		status := holdErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to place hold",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseData{
+		Status:  http.StatusCreated,
+		Message: "Hold placed successfully",
+		Data:    hold,
+	})
+}
+
+// Cancel handles cancelling a hold, passing a copy set aside for it to the next customer
+func (h *HoldHandler) Cancel(c *gin.Context) {
+	id, ok := parseHoldID(c)
+	if !ok {
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	hold, err := h.holdService.Cancel(id, user)
+	if err != nil {
+		status := holdErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to cancel hold",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Hold cancelled successfully",
+		Data:    hold,
+	})
+}
+
+// ExpirePickups handles expiring holds whose pickup deadline has passed
+func (h *HoldHandler) ExpirePickups(c *gin.Context) {
+	result, err := h.holdService.ExpirePickups()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to expire holds",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Expired holds processed successfully",
+		Data:    result,
+	})
+}
+
+func holdErrorStatus(err error) int {
+	if errors.Is(err, service.ErrHoldNotAllowed) {
+		return http.StatusConflict
+	}
+	return bookStockErrorStatus(err)
+}
+
+func parseHoldID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid hold ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
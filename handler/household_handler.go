@@ -0,0 +1,147 @@
+package handler
+
+import (
+	"errors"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/service"
+	"go-gin-simple-api/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type HouseholdHandler struct {
+	householdService service.HouseholdService
+}
+
+func NewHouseholdHandler(householdService service.HouseholdService) *HouseholdHandler {
+	return &HouseholdHandler{
+		householdService: householdService,
+	}
+}
+
+// GetHousehold handles retrieving the loans and charges of the household a customer belongs to
+func (h *HouseholdHandler) GetHousehold(c *gin.Context) {
+	customerID, ok := parseCustomerID(c)
+	if !ok {
+		return
+	}
+
+	h.writeHousehold(c, customerID)
+}
+
+// GetMyHousehold handles retrieving the household of the customer linked to the authenticated member,
+// who sees the loans and charges of their dependants
+func (h *HouseholdHandler) GetMyHousehold(c *gin.Context) {
+	customerID, ok := getLinkedCustomerID(c)
+	if !ok {
+		return
+	}
+
+	h.writeHousehold(c, customerID)
+}
+
+func (h *HouseholdHandler) writeHousehold(c *gin.Context, customerID uuid.UUID) {
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	household, err := h.householdService.GetHousehold(user, customerID)
+	if err != nil {
+		status := http.StatusNotFound
+		if errors.Is(err, service.ErrCustomerAccessDenied) {
+			status = http.StatusForbidden
+		}
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to retrieve household",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Household retrieved successfully",
+		Data:    household,
+	})
+}
+
+// SetGuardian handles making a customer the guardian of another
+func (h *HouseholdHandler) SetGuardian(c *gin.Context) {
+	customerID, ok := parseCustomerID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.GuardianRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	customer, err := h.householdService.SetGuardian(customerID, req)
+	if err != nil {
+		status := householdErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to set guardian",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Guardian set successfully",
+		Data:    customer,
+	})
+}
+
+// RemoveGuardian handles unlinking a dependant from their guardian
+func (h *HouseholdHandler) RemoveGuardian(c *gin.Context) {
+	customerID, ok := parseCustomerID(c)
+	if !ok {
+		return
+	}
+
+	customer, err := h.householdService.RemoveGuardian(customerID)
+	if err != nil {
+		status := householdErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to remove guardian",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Guardian removed successfully",
+		Data:    customer,
+	})
+}
+
+func householdErrorStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidHousehold) {
+		return http.StatusConflict
+	}
+	return http.StatusNotFound
+}
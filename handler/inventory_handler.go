@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/service"
+	"go-gin-simple-api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type InventoryHandler struct {
+	inventoryService service.InventoryService
+}
+
+func NewInventoryHandler(inventoryService service.InventoryService) *InventoryHandler {
+	return &InventoryHandler{
+		inventoryService: inventoryService,
+	}
+}
+
+// GetAll handles retrieving all inventory sessions with pagination, search, and filter
+func (h *InventoryHandler) GetAll(c *gin.Context) {
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	search := c.Query("search")
+	filterStr := c.Query("filter")
+
+	// Parse filters
+	filters := lib.ParseFilterString(filterStr)
+
+	result, err := h.inventoryService.GetAll(page, perPage, search, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve inventory sessions",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetByID handles retrieving an inventory session by ID
+func (h *InventoryHandler) GetByID(c *gin.Context) {
+	id, ok := parseInventoryID(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.inventoryService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Inventory session not found",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Inventory session retrieved successfully",
+		Data:    session,
+	})
+}
+
+// Create handles starting a new inventory session
+func (h *InventoryHandler) Create(c *gin.Context) {
+	var req dto.InventorySessionCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.inventoryService.Create(user, req)
+	if err != nil {
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to create inventory session",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseData{
+		Status:  http.StatusCreated,
+		Message: "Inventory session created successfully",
+		Data:    session,
+	})
+}
+
+// Scan handles recording a batch of scanned stock codes
+func (h *InventoryHandler) Scan(c *gin.Context) {
+	id, ok := parseInventoryID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.InventoryScanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.inventoryService.Scan(id, user, req)
+	if err != nil {
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to record scans",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Scans recorded successfully",
+		Data:    result,
+	})
+}
+
+// Close handles closing an inventory session
+func (h *InventoryHandler) Close(c *gin.Context) {
+	id, ok := parseInventoryID(c)
+	if !ok {
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	session, err := h.inventoryService.Close(id, user)
+	if err != nil {
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to close inventory session",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Inventory session closed successfully",
+		Data:    session,
+	})
+}
+
+// GetReport handles retrieving the reconciliation report of an inventory session
+func (h *InventoryHandler) GetReport(c *gin.Context) {
+	id, ok := parseInventoryID(c)
+	if !ok {
+		return
+	}
+
+	report, err := h.inventoryService.GetReport(id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve inventory report",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Inventory report retrieved successfully",
+		Data:    report,
+	})
+}
+
+// MarkMissingLost handles marking copies missing from an inventory session as Lost
+func (h *InventoryHandler) MarkMissingLost(c *gin.Context) {
+	id, ok := parseInventoryID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.InventoryMarkLostRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.inventoryService.MarkMissingLost(id, user, req)
+	if err != nil {
+		status := bookStockErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to mark missing copies as lost",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Missing copies marked as lost successfully",
+		Data:    result,
+	})
+}
+
+func parseInventoryID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid inventory session ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"errors"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/service"
+	"go-gin-simple-api/utils"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LabelHandler struct {
+	labelService service.LabelService
+}
+
+func NewLabelHandler(labelService service.LabelService) *LabelHandler {
+	return &LabelHandler{
+		labelService: labelService,
+	}
+}
+
+// GetBookStockLabel handles rendering a book stock label as PNG
+func (h *LabelHandler) GetBookStockLabel(c *gin.Context) {
+	code := c.Param("code")
+	format, ok := parseLabelFormat(c)
+	if !ok {
+		return
+	}
+
+	image, err := h.labelService.GetBookStockLabel(code, format)
+	if err != nil {
+		status := labelErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to render book stock label",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", image)
+}
+
+// GetCustomerLabel handles rendering a customer card label as PNG
+func (h *LabelHandler) GetCustomerLabel(c *gin.Context) {
+	code := c.Param("code")
+	format, ok := parseLabelFormat(c)
+	if !ok {
+		return
+	}
+
+	image, err := h.labelService.GetCustomerLabel(code, format)
+	if err != nil {
+		status := labelErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to render customer label",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.Data(http.StatusOK, "image/png", image)
+}
+
+// GetBookStockLabelSheet handles rendering a printable PDF sheet of book stock labels
+func (h *LabelHandler) GetBookStockLabelSheet(c *gin.Context) {
+	req, ok := bindLabelSheetRequest(c)
+	if !ok {
+		return
+	}
+
+	sheet, err := h.labelService.GetBookStockLabelSheet(req)
+	if err != nil {
+		status := labelErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to render book stock labels",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="book-stock-labels.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", sheet)
+}
+
+// GetCustomerLabelSheet handles rendering a printable PDF sheet of customer card labels
+func (h *LabelHandler) GetCustomerLabelSheet(c *gin.Context) {
+	req, ok := bindLabelSheetRequest(c)
+	if !ok {
+		return
+	}
+
+	sheet, err := h.labelService.GetCustomerLabelSheet(req)
+	if err != nil {
+		status := labelErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to render customer labels",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.Header("Content-Disposition", `attachment; filename="customer-labels.pdf"`)
+	c.Data(http.StatusOK, "application/pdf", sheet)
+}
+
+func labelErrorStatus(err error) int {
+	if errors.Is(err, service.ErrLabelNotFound) {
+		return http.StatusNotFound
+	}
+	if errors.Is(err, service.ErrInvalidLabelFormat) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
+
+// parseLabelFormat reads the format query parameter, defaulting to Code128
+func parseLabelFormat(c *gin.Context) (string, bool) {
+	format := c.DefaultQuery("format", lib.LabelFormatCode128)
+	if format != lib.LabelFormatCode128 && format != lib.LabelFormatQR {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid label format",
+			Error:   map[string]string{"format": "Field format must be one of: code128 qr"},
+		})
+		return "", false
+	}
+	return format, true
+}
+
+func bindLabelSheetRequest(c *gin.Context) (dto.LabelSheetRequest, bool) {
+	var req dto.LabelSheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return req, false
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return req, false
+	}
+
+	return req, true
+}
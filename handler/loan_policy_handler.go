@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/service"
+	"go-gin-simple-api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type LoanPolicyHandler struct {
+	loanPolicyService service.LoanPolicyService
+}
+
+func NewLoanPolicyHandler(loanPolicyService service.LoanPolicyService) *LoanPolicyHandler {
+	return &LoanPolicyHandler{
+		loanPolicyService: loanPolicyService,
+	}
+}
+
+// GetAll handles retrieving all loan policies with pagination, search, and filter
+func (h *LoanPolicyHandler) GetAll(c *gin.Context) {
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	search := c.Query("search")
+	filterStr := c.Query("filter")
+
+	// Parse filters
+	filters := lib.ParseFilterString(filterStr)
+
+	result, err := h.loanPolicyService.GetAll(page, perPage, search, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve loan policies",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetByID handles retrieving a loan policy by ID
+func (h *LoanPolicyHandler) GetByID(c *gin.Context) {
+	id, ok := parseLoanPolicyID(c)
+	if !ok {
+		return
+	}
+
+	policy, err := h.loanPolicyService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Loan policy not found",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Loan policy retrieved successfully",
+		Data:    policy,
+	})
+}
+
+// Create handles creating a new loan policy
+func (h *LoanPolicyHandler) Create(c *gin.Context) {
+	var req dto.LoanPolicyCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	policy, err := h.loanPolicyService.Create(req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to create loan policy",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseData{
+		Status:  http.StatusCreated,
+		Message: "Loan policy created successfully",
+		Data:    policy,
+	})
+}
+
+// Update handles updating a loan policy
+func (h *LoanPolicyHandler) Update(c *gin.Context) {
+	id, ok := parseLoanPolicyID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.LoanPolicyUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	policy, err := h.loanPolicyService.Update(id, req)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to update loan policy",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Loan policy updated successfully",
+		Data:    policy,
+	})
+}
+
+// Delete handles deleting a loan policy
+func (h *LoanPolicyHandler) Delete(c *gin.Context) {
+	id, ok := parseLoanPolicyID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.loanPolicyService.Delete(id); err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to delete loan policy",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseSuccess{
+		Status:  http.StatusOK,
+		Message: "Loan policy deleted successfully",
+	})
+}
+
+func parseLoanPolicyID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid loan policy ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
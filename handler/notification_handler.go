@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"errors"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/service"
+	"go-gin-simple-api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type NotificationHandler struct {
+	notificationService service.NotificationService
+}
+
+func NewNotificationHandler(notificationService service.NotificationService) *NotificationHandler {
+	return &NotificationHandler{
+		notificationService: notificationService,
+	}
+}
+
+// GetAll handles retrieving the notification delivery log with pagination, search, and filter
+func (h *NotificationHandler) GetAll(c *gin.Context) {
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	search := c.Query("search")
+	filterStr := c.Query("filter")
+
+	// Parse filters
+	filters := lib.ParseFilterString(filterStr)
+
+	result, err := h.notificationService.GetAll(page, perPage, search, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve notifications",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// SendPending handles sending every notice that is due now instead of waiting for the scheduler
+func (h *NotificationHandler) SendPending(c *gin.Context) {
+	result, err := h.notificationService.SendPending()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to send notifications",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Notifications sent successfully",
+		Data:    result,
+	})
+}
+
+// GetTemplates handles retrieving the wording of every notice on every channel
+func (h *NotificationHandler) GetTemplates(c *gin.Context) {
+	templates, err := h.notificationService.GetTemplates()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve notification templates",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Notification templates retrieved successfully",
+		Data:    templates,
+	})
+}
+
+// UpdateTemplate handles changing the wording of a notice on a channel
+func (h *NotificationHandler) UpdateTemplate(c *gin.Context) {
+	var req dto.NotificationTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	template, err := h.notificationService.UpdateTemplate(req)
+	if err != nil {
+		status := notificationErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to update notification template",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Notification template updated successfully",
+		Data:    template,
+	})
+}
+
+// GetByCustomerID handles retrieving the notices sent to a customer
+func (h *NotificationHandler) GetByCustomerID(c *gin.Context) {
+	customerID, ok := parseCustomerID(c)
+	if !ok || !authorizeCustomer(c, customerID) {
+		return
+	}
+	h.writeCustomerLog(c, customerID)
+}
+
+// GetMine handles retrieving the notices sent to the authenticated member
+func (h *NotificationHandler) GetMine(c *gin.Context) {
+	customerID, ok := getLinkedCustomerID(c)
+	if !ok {
+		return
+	}
+	h.writeCustomerLog(c, customerID)
+}
+
+// GetPreferences handles retrieving the channels a customer receives notices on
+func (h *NotificationHandler) GetPreferences(c *gin.Context) {
+	customerID, ok := parseCustomerID(c)
+	if !ok || !authorizeCustomer(c, customerID) {
+		return
+	}
+	h.writePreferences(c, customerID)
+}
+
+// GetMyPreferences handles retrieving the channels the authenticated member receives notices on
+func (h *NotificationHandler) GetMyPreferences(c *gin.Context) {
+	customerID, ok := getLinkedCustomerID(c)
+	if !ok {
+		return
+	}
+	h.writePreferences(c, customerID)
+}
+
+// UpdatePreferences handles replacing the channels a customer receives notices on
+func (h *NotificationHandler) UpdatePreferences(c *gin.Context) {
+	customerID, ok := parseCustomerID(c)
+	if !ok || !authorizeCustomer(c, customerID) {
+		return
+	}
+	h.updatePreferences(c, customerID)
+}
+
+// UpdateMyPreferences handles replacing the channels the authenticated member receives notices on
+func (h *NotificationHandler) UpdateMyPreferences(c *gin.Context) {
+	customerID, ok := getLinkedCustomerID(c)
+	if !ok {
+		return
+	}
+	h.updatePreferences(c, customerID)
+}
+
+func (h *NotificationHandler) writeCustomerLog(c *gin.Context, customerID uuid.UUID) {
+	notifications, err := h.notificationService.GetByCustomerID(customerID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve notifications",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Notifications retrieved successfully",
+		Data:    notifications,
+	})
+}
+
+func (h *NotificationHandler) writePreferences(c *gin.Context, customerID uuid.UUID) {
+	preferences, err := h.notificationService.GetPreferences(customerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Failed to retrieve notification preferences",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Notification preferences retrieved successfully",
+		Data:    preferences,
+	})
+}
+
+func (h *NotificationHandler) updatePreferences(c *gin.Context, customerID uuid.UUID) {
+	var req dto.NotificationPreferencesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	preferences, err := h.notificationService.UpdatePreferences(user, customerID, req)
+	if err != nil {
+		status := notificationErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to update notification preferences",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Notification preferences updated successfully",
+		Data:    preferences,
+	})
+}
+
+func notificationErrorStatus(err error) int {
+	if errors.Is(err, service.ErrInvalidNotificationSettings) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
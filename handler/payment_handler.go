@@ -0,0 +1,331 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/service"
+	"go-gin-simple-api/utils"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+type PaymentHandler struct {
+	paymentService service.PaymentService
+}
+
+func NewPaymentHandler(paymentService service.PaymentService) *PaymentHandler {
+	return &PaymentHandler{
+		paymentService: paymentService,
+	}
+}
+
+// GetAll handles retrieving all payments with pagination, search, and filter
+func (h *PaymentHandler) GetAll(c *gin.Context) {
+	// Parse pagination parameters
+	page, _ := strconv.Atoi(c.DefaultQuery("page", "1"))
+	perPage, _ := strconv.Atoi(c.DefaultQuery("per_page", "10"))
+	search := c.Query("search")
+	filterStr := c.Query("filter")
+
+	// Parse filters
+	filters := lib.ParseFilterString(filterStr)
+
+	result, err := h.paymentService.GetAll(page, perPage, search, filters)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{
+			Status:  http.StatusInternalServerError,
+			Message: "Failed to retrieve payments",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// GetByID handles retrieving a payment by ID
+func (h *PaymentHandler) GetByID(c *gin.Context) {
+	id, ok := parsePaymentID(c)
+	if !ok {
+		return
+	}
+
+	payment, err := h.paymentService.GetByID(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Payment not found",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Payment retrieved successfully",
+		Data:    payment,
+	})
+}
+
+// GetReceipt handles retrieving the receipt of a payment as JSON, or as a printable PDF with ?format=pdf
+func (h *PaymentHandler) GetReceipt(c *gin.Context) {
+	id, ok := parsePaymentID(c)
+	if !ok {
+		return
+	}
+
+	if c.Query("format") == "pdf" {
+		receipt, err := h.paymentService.GetReceiptPDF(id)
+		if err != nil {
+			c.JSON(http.StatusNotFound, dto.ResponseError{
+				Status:  http.StatusNotFound,
+				Message: "Failed to generate receipt",
+				Error:   map[string]string{"error": err.Error()},
+			})
+			return
+		}
+
+		c.Header("Content-Disposition", fmt.Sprintf(`inline; filename="receipt-%s.pdf"`, id))
+		c.Data(http.StatusOK, "application/pdf", receipt)
+		return
+	}
+
+	receipt, err := h.paymentService.GetReceipt(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Failed to retrieve receipt",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Receipt retrieved successfully",
+		Data:    receipt,
+	})
+}
+
+// Pay handles recording a full or partial payment of a charge
+func (h *PaymentHandler) Pay(c *gin.Context) {
+	chargeID, ok := parseChargeID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.PaymentCreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	payment, err := h.paymentService.Pay(chargeID, user, req)
+	if err != nil {
+		status := paymentErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to record payment",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusCreated, dto.ResponseData{
+		Status:  http.StatusCreated,
+		Message: "Payment recorded successfully",
+		Data:    payment,
+	})
+}
+
+// Adjust handles waiving or discounting a charge
+func (h *PaymentHandler) Adjust(c *gin.Context) {
+	chargeID, ok := parseChargeID(c)
+	if !ok {
+		return
+	}
+
+	var req dto.ChargeAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid request body",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	// Validate request
+	if validationErrors := utils.Validate(req); len(validationErrors) > 0 {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Validation failed",
+			Error:   validationErrors,
+		})
+		return
+	}
+
+	user, ok := getUserData(c)
+	if !ok {
+		return
+	}
+
+	balance, err := h.paymentService.Adjust(chargeID, user, req)
+	if err != nil {
+		status := paymentErrorStatus(err)
+		c.JSON(status, dto.ResponseError{
+			Status:  status,
+			Message: "Failed to adjust charge",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Charge adjusted successfully",
+		Data:    balance,
+	})
+}
+
+// GetChargeBalance handles retrieving the ledger and outstanding balance of a charge
+func (h *PaymentHandler) GetChargeBalance(c *gin.Context) {
+	chargeID, ok := parseChargeID(c)
+	if !ok {
+		return
+	}
+
+	balance, err := h.paymentService.GetChargeBalance(chargeID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Failed to retrieve charge balance",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Charge balance retrieved successfully",
+		Data:    balance,
+	})
+}
+
+// GetCustomerBalance handles retrieving the outstanding balance of a customer
+func (h *PaymentHandler) GetCustomerBalance(c *gin.Context) {
+	customerID, ok := parseCustomerID(c)
+	if !ok || !authorizeCustomer(c, customerID) {
+		return
+	}
+
+	balance, err := h.paymentService.GetCustomerBalance(customerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Failed to retrieve customer balance",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Customer balance retrieved successfully",
+		Data:    balance,
+	})
+}
+
+// GetMyCharges handles retrieving the charges and balance of the customer linked to the authenticated member
+func (h *PaymentHandler) GetMyCharges(c *gin.Context) {
+	customerID, ok := getLinkedCustomerID(c)
+	if !ok {
+		return
+	}
+
+	balance, err := h.paymentService.GetCustomerBalance(customerID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, dto.ResponseError{
+			Status:  http.StatusNotFound,
+			Message: "Failed to retrieve charges",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, dto.ResponseData{
+		Status:  http.StatusOK,
+		Message: "Charges retrieved successfully",
+		Data:    balance,
+	})
+}
+
+func paymentErrorStatus(err error) int {
+	if errors.Is(err, service.ErrPaymentNotAllowed) {
+		return http.StatusConflict
+	}
+	return http.StatusInternalServerError
+}
+
+func parsePaymentID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid payment ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func parseChargeID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid charge ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+func parseCustomerID(c *gin.Context) (uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, dto.ResponseError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid customer ID format",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return uuid.Nil, false
+	}
+	return id, true
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/service"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// getUserData returns the authenticated user set by the JWT middleware,
+// writing an error response when it is missing
+func getUserData(c *gin.Context) (dto.UserData, bool) {
+	userData, exists := c.Get("userData")
+	if !exists {
+		c.JSON(http.StatusUnauthorized, dto.ResponseError{Status: http.StatusUnauthorized, Message: "User data not found"})
+		return dto.UserData{}, false
+	}
+
+	user, ok := userData.(dto.UserData)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, dto.ResponseError{Status: http.StatusInternalServerError, Message: "Invalid user data"})
+		return dto.UserData{}, false
+	}
+
+	return user, true
+}
+
+// authorizeCustomer reports whether the authenticated user may see the records of a customer,
+// writing an error response when they may not
+func authorizeCustomer(c *gin.Context, customerID uuid.UUID) bool {
+	user, ok := getUserData(c)
+	if !ok {
+		return false
+	}
+
+	if err := service.CheckCustomerAccess(user, customerID); err != nil {
+		c.JSON(http.StatusForbidden, dto.ResponseError{
+			Status:  http.StatusForbidden,
+			Message: "Access denied",
+			Error:   map[string]string{"error": err.Error()},
+		})
+		return false
+	}
+
+	return true
+}
+
+// getLinkedCustomerID returns the customer record the authenticated member is linked to,
+// writing an error response when the account has none
+func getLinkedCustomerID(c *gin.Context) (uuid.UUID, bool) {
+	user, ok := getUserData(c)
+	if !ok {
+		return uuid.Nil, false
+	}
+
+	if user.CustomerID == nil {
+		c.JSON(http.StatusForbidden, dto.ResponseError{Status: http.StatusForbidden, Message: "User account is not linked to a customer"})
+		return uuid.Nil, false
+	}
+
+	return *user.CustomerID, true
+}
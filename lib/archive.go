@@ -0,0 +1,36 @@
+package lib
+
+import (
+	"archive/zip"
+	"bytes"
+	"time"
+)
+
+// ArchiveFile is a file to put in a ZIP archive
+type ArchiveFile struct {
+	Name string
+	Body []byte
+}
+
+// BuildZip packs the files into a ZIP archive, in the order given
+func BuildZip(files []ArchiveFile, modified time.Time) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := zip.NewWriter(&buf)
+	for _, file := range files {
+		entry, err := writer.CreateHeader(&zip.FileHeader{
+			Name:     file.Name,
+			Method:   zip.Deflate,
+			Modified: modified,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if _, err := entry.Write(file.Body); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
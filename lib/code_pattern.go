@@ -0,0 +1,147 @@
+package lib
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Supported code pattern tokens
+const (
+	CodeTokenBranch = "BRANCH"
+	CodeTokenYear   = "YEAR"
+	CodeTokenYY     = "YY"
+	CodeTokenMonth  = "MONTH"
+	CodeTokenSeq    = "SEQ"
+)
+
+// CodePattern is a parsed code pattern such as {BRANCH}-{YEAR}-{SEQ:6}
+type CodePattern struct {
+	raw      string
+	segments []codeSegment
+}
+
+// CodePatternValues holds the values substituted into a code pattern
+type CodePatternValues struct {
+	Branch string
+	Time   time.Time
+}
+
+type codeSegment struct {
+	literal string
+	token   string
+	width   int
+}
+
+// ParseCodePattern parses a pattern made of literals and {TOKEN} or {TOKEN:width} placeholders
+func ParseCodePattern(pattern string) (*CodePattern, error) {
+	p := &CodePattern{raw: pattern}
+	hasSeq := false
+
+	rest := pattern
+	for rest != "" {
+		start := strings.Index(rest, "{")
+		if start < 0 {
+			p.segments = append(p.segments, codeSegment{literal: rest})
+			break
+		}
+		if start > 0 {
+			p.segments = append(p.segments, codeSegment{literal: rest[:start]})
+		}
+
+		end := strings.Index(rest[start:], "}")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated token in code pattern %q", pattern)
+		}
+
+		segment, err := parseCodeToken(rest[start+1 : start+end])
+		if err != nil {
+			return nil, err
+		}
+		if segment.token == CodeTokenSeq {
+			if hasSeq {
+				return nil, errors.New("code pattern must contain exactly one {SEQ} token")
+			}
+			hasSeq = true
+		}
+		p.segments = append(p.segments, segment)
+		rest = rest[start+end+1:]
+	}
+
+	if !hasSeq {
+		return nil, errors.New("code pattern must contain exactly one {SEQ} token")
+	}
+
+	return p, nil
+}
+
+// String returns the raw pattern
+func (p *CodePattern) String() string {
+	return p.raw
+}
+
+// Scope renders every token except the sequence, identifying the sequence the codes are drawn from
+func (p *CodePattern) Scope(values CodePatternValues) string {
+	var sb strings.Builder
+	for _, segment := range p.segments {
+		if segment.token == CodeTokenSeq {
+			sb.WriteString("{" + CodeTokenSeq + "}")
+			continue
+		}
+		sb.WriteString(segment.render(values, 0))
+	}
+	return sb.String()
+}
+
+// Render renders the pattern with the given values and sequence number
+func (p *CodePattern) Render(values CodePatternValues, seq int64) string {
+	var sb strings.Builder
+	for _, segment := range p.segments {
+		sb.WriteString(segment.render(values, seq))
+	}
+	return sb.String()
+}
+
+func parseCodeToken(token string) (codeSegment, error) {
+	name, widthStr, hasWidth := strings.Cut(token, ":")
+	segment := codeSegment{token: strings.ToUpper(name)}
+
+	switch segment.token {
+	case CodeTokenBranch, CodeTokenYear, CodeTokenYY, CodeTokenMonth, CodeTokenSeq:
+	default:
+		return segment, fmt.Errorf("unknown code pattern token {%s}", token)
+	}
+
+	if hasWidth {
+		if segment.token != CodeTokenSeq {
+			return segment, fmt.Errorf("only {SEQ} accepts a width, got {%s}", token)
+		}
+		width, err := strconv.Atoi(widthStr)
+		if err != nil || width < 1 || width > 20 {
+			return segment, fmt.Errorf("invalid width in code pattern token {%s}", token)
+		}
+		segment.width = width
+	}
+
+	return segment, nil
+}
+
+func (s codeSegment) render(values CodePatternValues, seq int64) string {
+	switch s.token {
+	case "":
+		return s.literal
+	case CodeTokenBranch:
+		return strings.ToUpper(values.Branch)
+	case CodeTokenYear:
+		return values.Time.Format("2006")
+	case CodeTokenYY:
+		return values.Time.Format("06")
+	case CodeTokenMonth:
+		return values.Time.Format("01")
+	case CodeTokenSeq:
+		return fmt.Sprintf("%0*d", s.width, seq)
+	}
+	return ""
+}
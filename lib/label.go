@@ -0,0 +1,150 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+
+	"github.com/boombuler/barcode"
+	"github.com/boombuler/barcode/code128"
+	"github.com/boombuler/barcode/qr"
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Supported label symbologies
+const (
+	LabelFormatCode128 = "code128"
+	LabelFormatQR      = "qr"
+)
+
+// Label sheet layout (A4, 3 columns x 8 rows, sizes in millimetres)
+const (
+	labelColumns = 3
+	labelRows    = 8
+	labelWidth   = 70.0
+	labelHeight  = 37.0
+	labelMargin  = 0.5
+)
+
+// Label represents a single printable label
+type Label struct {
+	Code    string
+	Caption string
+}
+
+// GenerateBarcodePNG renders the given content as a Code128 or QR PNG image
+func GenerateBarcodePNG(content, format string, width, height int) ([]byte, error) {
+	code, err := encodeBarcode(content, format)
+	if err != nil {
+		return nil, err
+	}
+
+	// A barcode can not be scaled below its natural size
+	if width < code.Bounds().Dx() {
+		width = code.Bounds().Dx()
+	}
+	if height < code.Bounds().Dy() {
+		height = code.Bounds().Dy()
+	}
+
+	scaled, err := barcode.Scale(code, width, height)
+	if err != nil {
+		return nil, err
+	}
+
+	// Barcodes come out as 16-bit gray which PDF writers can not embed
+	gray := image.NewGray(scaled.Bounds())
+	draw.Draw(gray, gray.Bounds(), scaled, scaled.Bounds().Min, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, gray); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// GenerateLabelSheetPDF renders the given labels on A4 label sheets
+func GenerateLabelSheetPDF(labels []Label, format string) ([]byte, error) {
+	if len(labels) == 0 {
+		return nil, errors.New("no labels to print")
+	}
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(0, 0, 0)
+	pdf.SetAutoPageBreak(false, 0)
+	pdf.SetFont("Helvetica", "", 9)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+
+	perPage := labelColumns * labelRows
+	for i, label := range labels {
+		if i%perPage == 0 {
+			pdf.AddPage()
+		}
+
+		slot := i % perPage
+		x := float64(slot%labelColumns) * labelWidth
+		y := float64(slot/labelColumns)*labelHeight + labelMargin
+
+		img, err := GenerateBarcodePNG(label.Code, format, 600, 300)
+		if err != nil {
+			return nil, fmt.Errorf("failed to render label %s: %w", label.Code, err)
+		}
+
+		imageName := fmt.Sprintf("label-%d", i)
+		pdf.RegisterImageOptionsReader(imageName, gofpdf.ImageOptions{ImageType: "PNG"}, bytes.NewReader(img))
+
+		// QR codes are square, linear barcodes use the full label width
+		imageWidth, imageHeight := labelWidth-10, 18.0
+		if format == LabelFormatQR {
+			imageWidth, imageHeight = 22, 22
+		}
+		pdf.ImageOptions(imageName, x+(labelWidth-imageWidth)/2, y+3, imageWidth, imageHeight, false, gofpdf.ImageOptions{ImageType: "PNG"}, 0, "")
+
+		pdf.SetXY(x+2, y+imageHeight+4)
+		pdf.CellFormat(labelWidth-4, 4, label.Code, "", 2, "C", false, 0, "")
+		if label.Caption != "" {
+			pdf.SetX(x + 2)
+			pdf.CellFormat(labelWidth-4, 4, truncateCaption(pdf, translate(label.Caption), labelWidth-4), "", 0, "C", false, 0, "")
+		}
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func encodeBarcode(content, format string) (barcode.Barcode, error) {
+	switch format {
+	case LabelFormatCode128, "":
+		return code128.Encode(content)
+	case LabelFormatQR:
+		return qr.Encode(content, qr.M, qr.Auto)
+	default:
+		return nil, fmt.Errorf("unsupported label format: %s", format)
+	}
+}
+
+// truncateCaption shortens a caption so it fits on a single label line
+func truncateCaption(pdf *gofpdf.Fpdf, caption string, width float64) string {
+	if pdf.GetStringWidth(caption) <= width {
+		return caption
+	}
+
+	runes := []rune(caption)
+	for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"...") > width {
+		runes = runes[:len(runes)-1]
+	}
+
+	return string(runes) + "..."
+}
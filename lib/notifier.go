@@ -0,0 +1,261 @@
+package lib
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// NotificationMessage is a rendered notice ready to be delivered to one recipient
+type NotificationMessage struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	// Kind of notice, passed on to channels that forward structured data
+	Type string `json:"type"`
+}
+
+// NotificationChannel delivers notices over one medium. Name matches the channel customers
+// pick in their preferences.
+type NotificationChannel interface {
+	Name() string
+	Send(ctx context.Context, msg NotificationMessage) error
+}
+
+// EmailChannel sends notices through an SMTP server
+type EmailChannel struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+func (c *EmailChannel) Name() string {
+	return "Email"
+}
+
+func (c *EmailChannel) Send(ctx context.Context, msg NotificationMessage) error {
+	var auth smtp.Auth
+	if c.Username != "" {
+		auth = smtp.PlainAuth("", c.Username, c.Password, c.Host)
+	}
+
+	// Header values come from templates, so line breaks are dropped to keep them single headers
+	subject := strings.NewReplacer("\r", "", "\n", " ").Replace(msg.Subject)
+	to := strings.NewReplacer("\r", "", "\n", "").Replace(msg.To)
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", c.From)
+	fmt.Fprintf(&body, "To: %s\r\n", to)
+	fmt.Fprintf(&body, "Subject: %s\r\n", subject)
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	body.WriteString(msg.Body)
+
+	return smtp.SendMail(c.Host+":"+c.Port, auth, c.From, []string{to}, body.Bytes())
+}
+
+// SMSChannel sends notices through an HTTP SMS gateway that accepts a JSON body
+// with to, from and message fields
+type SMSChannel struct {
+	GatewayURL string
+	APIKey     string
+	Sender     string
+	Client     *http.Client
+}
+
+func (c *SMSChannel) Name() string {
+	return "SMS"
+}
+
+func (c *SMSChannel) Send(ctx context.Context, msg NotificationMessage) error {
+	payload, err := json.Marshal(map[string]string{
+		"to":      msg.To,
+		"from":    c.Sender,
+		"message": msg.Body,
+	})
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{}
+	if c.APIKey != "" {
+		headers["Authorization"] = "Bearer " + c.APIKey
+	}
+	return postJSON(ctx, c.Client, c.GatewayURL, payload, headers)
+}
+
+// ErrNonPublicAddress is returned when a customer's webhook points at a loopback, private or
+// link-local address, which would let it reach services inside the library's network
+var ErrNonPublicAddress = errors.New("webhook address is not a public address")
+
+// WebhookChannel posts notices as JSON to a URL: the customer's own endpoint, or the
+// default URL when they gave none. With a secret every request carries an
+// X-Signature header holding the hex HMAC-SHA256 of the body.
+// Client is only used for the default URL; customer endpoints always go through a client
+// that refuses to connect to non-public addresses.
+type WebhookChannel struct {
+	URL    string
+	Secret string
+	Client *http.Client
+}
+
+func (c *WebhookChannel) Name() string {
+	return "Webhook"
+}
+
+func (c *WebhookChannel) Send(ctx context.Context, msg NotificationMessage) error {
+	url, client := msg.To, publicClient
+	if url == "" {
+		url, client = c.URL, c.Client
+	} else if err := CheckPublicURL(url); err != nil {
+		return err
+	}
+	if url == "" {
+		return errors.New("no webhook URL")
+	}
+
+	payload, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	headers := map[string]string{}
+	if c.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(c.Secret))
+		mac.Write(payload)
+		headers["X-Signature"] = hex.EncodeToString(mac.Sum(nil))
+	}
+	return postJSON(ctx, client, url, payload, headers)
+}
+
+// CheckPublicURL checks that a webhook URL is http(s) and does not name a loopback, private
+// or link-local host. Host names are checked again when connecting, once they are resolved.
+func CheckPublicURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Hostname() == "" {
+		return errors.New("webhook address must be an http(s) URL")
+	}
+
+	host := strings.ToLower(strings.TrimSuffix(parsed.Hostname(), "."))
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrNonPublicAddress
+	}
+	if ip := net.ParseIP(host); ip != nil && !IsPublicIP(ip) {
+		return ErrNonPublicAddress
+	}
+	return nil
+}
+
+// IsPublicIP reports whether an address is reachable on the public internet, as opposed to
+// loopback, private, link-local (such as cloud metadata endpoints) or unspecified addresses
+func IsPublicIP(ip net.IP) bool {
+	return !(ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified())
+}
+
+// publicClient connects only to public addresses. The check runs on the resolved address of
+// every connection, so it also covers redirects and host names resolving to internal addresses.
+var publicClient = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		DialContext: (&net.Dialer{
+			Timeout: 10 * time.Second,
+			Control: func(network, address string, conn syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || !IsPublicIP(ip) {
+					return ErrNonPublicAddress
+				}
+				return nil
+			},
+		}).DialContext,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+}
+
+// MemoryChannel keeps notices in memory instead of delivering them. It stands in for
+// any channel by name, for tests and for running without real providers.
+type MemoryChannel struct {
+	ChannelName string
+	// Returned by Send when set, to simulate a failing provider
+	Err error
+
+	mu       sync.Mutex
+	messages []NotificationMessage
+}
+
+func NewMemoryChannel(name string) *MemoryChannel {
+	return &MemoryChannel{ChannelName: name}
+}
+
+func (c *MemoryChannel) Name() string {
+	return c.ChannelName
+}
+
+func (c *MemoryChannel) Send(ctx context.Context, msg NotificationMessage) error {
+	if c.Err != nil {
+		return c.Err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = append(c.messages, msg)
+	return nil
+}
+
+// Messages returns the notices sent so far
+func (c *MemoryChannel) Messages() []NotificationMessage {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]NotificationMessage(nil), c.messages...)
+}
+
+// Reset forgets the notices sent so far
+func (c *MemoryChannel) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.messages = nil
+}
+
+func postJSON(ctx context.Context, client *http.Client, url string, payload []byte, headers map[string]string) error {
+	if client == nil {
+		client = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with status %d", url, resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,77 @@
+package lib
+
+import (
+	"bytes"
+	"errors"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Receipt is a printable document made of labelled lines under a title
+type Receipt struct {
+	Title  string
+	Number string
+	Lines  []ReceiptLine
+	Footer string
+}
+
+// ReceiptLine is a single label and value row of a receipt
+type ReceiptLine struct {
+	Label string
+	Value string
+}
+
+// GenerateReceiptPDF renders a receipt on an A5 page, continuing on further pages when it is long
+func GenerateReceiptPDF(receipt Receipt) ([]byte, error) {
+	if len(receipt.Lines) == 0 {
+		return nil, errors.New("no receipt lines to print")
+	}
+
+	pdf := gofpdf.New("P", "mm", "A5", "")
+	pdf.SetMargins(12, 12, 12)
+	pdf.SetAutoPageBreak(true, 12)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pageWidth, _ := pdf.GetPageSize()
+	contentWidth := pageWidth - 24
+
+	pdf.SetFont("Helvetica", "B", 14)
+	pdf.CellFormat(contentWidth, 8, translate(receipt.Title), "", 1, "C", false, 0, "")
+	if receipt.Number != "" {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(contentWidth, 6, translate(receipt.Number), "", 1, "C", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	labelWidth := contentWidth * 0.4
+	for _, line := range receipt.Lines {
+		// A line without a value is a section heading
+		if line.Value == "" {
+			pdf.Ln(2)
+			pdf.SetFont("Helvetica", "B", 10)
+			pdf.CellFormat(contentWidth, 6, translate(line.Label), "B", 1, "L", false, 0, "")
+			continue
+		}
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(labelWidth, 6, translate(line.Label), "", 0, "L", false, 0, "")
+		pdf.MultiCell(contentWidth-labelWidth, 6, translate(line.Value), "", "R", false)
+	}
+
+	if receipt.Footer != "" {
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.MultiCell(contentWidth, 4, translate(receipt.Footer), "", "C", false)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
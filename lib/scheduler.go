@@ -0,0 +1,92 @@
+package lib
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Leader decides whether this instance should run scheduled jobs
+type Leader interface {
+	TryAcquire(ctx context.Context) (bool, error)
+	Release(ctx context.Context) error
+}
+
+// Job is a task run periodically by the scheduler
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func() error
+}
+
+// Scheduler runs jobs in the background on the instance that holds leadership,
+// so replicas of the server do not repeat each other's work
+type Scheduler struct {
+	leader  Leader
+	jobs    []Job
+	tick    time.Duration
+	lastRun map[string]time.Time
+}
+
+func NewScheduler(leader Leader, jobs ...Job) *Scheduler {
+	// Check for due jobs as often as the most frequent one needs
+	tick := time.Minute
+	for _, job := range jobs {
+		if job.Interval > 0 && job.Interval < tick {
+			tick = job.Interval
+		}
+	}
+
+	return &Scheduler{
+		leader:  leader,
+		jobs:    jobs,
+		tick:    tick,
+		lastRun: make(map[string]time.Time),
+	}
+}
+
+// Start runs the scheduler until the context is cancelled
+func (s *Scheduler) Start(ctx context.Context) {
+	go s.loop(ctx)
+}
+
+func (s *Scheduler) loop(ctx context.Context) {
+	ticker := time.NewTicker(s.tick)
+	defer ticker.Stop()
+
+	for {
+		s.runDue(ctx, time.Now())
+
+		select {
+		case <-ctx.Done():
+			if err := s.leader.Release(context.Background()); err != nil {
+				log.Printf("Scheduler: failed to release leadership: %v", err)
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runDue(ctx context.Context, now time.Time) {
+	leading, err := s.leader.TryAcquire(ctx)
+	if err != nil {
+		log.Printf("Scheduler: leader election failed: %v", err)
+		return
+	}
+	if !leading {
+		return
+	}
+
+	for _, job := range s.jobs {
+		// Half a tick of slack keeps ticker jitter from pushing a job back a whole tick
+		if last, ok := s.lastRun[job.Name]; ok && now.Sub(last) < job.Interval-s.tick/2 {
+			continue
+		}
+		s.lastRun[job.Name] = now
+
+		if err := job.Run(); err != nil {
+			log.Printf("Scheduler: job %s failed: %v", job.Name, err)
+		}
+	}
+}
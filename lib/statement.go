@@ -0,0 +1,121 @@
+package lib
+
+import (
+	"bytes"
+
+	"github.com/jung-kurt/gofpdf"
+)
+
+// Statement is a printable account statement: a summary of labelled lines followed by tables
+type Statement struct {
+	Title    string
+	Subtitle string
+	Summary  []ReceiptLine
+	Tables   []StatementTable
+	Footer   string
+}
+
+// StatementTable is a titled table of a statement; Empty is printed when it has no rows
+type StatementTable struct {
+	Heading string
+	Columns []StatementColumn
+	Rows    [][]string
+	Empty   string
+}
+
+// StatementColumn sets the header and share of the page width of a table column
+// Align is "L" or "R"
+type StatementColumn struct {
+	Title string
+	Width float64
+	Align string
+}
+
+// GenerateStatementPDF renders a statement on A4 pages, repeating table headers after each page break
+func GenerateStatementPDF(statement Statement) ([]byte, error) {
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.SetMargins(15, 15, 15)
+	pdf.SetAutoPageBreak(true, 15)
+	translate := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AddPage()
+
+	pageWidth, pageHeight := pdf.GetPageSize()
+	contentWidth := pageWidth - 30
+
+	pdf.SetFont("Helvetica", "B", 16)
+	pdf.CellFormat(contentWidth, 9, translate(statement.Title), "", 1, "L", false, 0, "")
+	if statement.Subtitle != "" {
+		pdf.SetFont("Helvetica", "", 10)
+		pdf.CellFormat(contentWidth, 6, translate(statement.Subtitle), "", 1, "L", false, 0, "")
+	}
+	pdf.Ln(4)
+
+	labelWidth := contentWidth * 0.35
+	pdf.SetFont("Helvetica", "", 10)
+	for _, line := range statement.Summary {
+		pdf.CellFormat(labelWidth, 6, translate(line.Label), "", 0, "L", false, 0, "")
+		pdf.CellFormat(contentWidth-labelWidth, 6, translate(line.Value), "", 1, "L", false, 0, "")
+	}
+
+	for _, table := range statement.Tables {
+		pdf.Ln(5)
+		pdf.SetFont("Helvetica", "B", 11)
+		pdf.CellFormat(contentWidth, 7, translate(table.Heading), "", 1, "L", false, 0, "")
+
+		header := func() {
+			pdf.SetFont("Helvetica", "B", 9)
+			pdf.SetFillColor(230, 230, 230)
+			for _, column := range table.Columns {
+				pdf.CellFormat(contentWidth*column.Width, 6, translate(column.Title), "B", 0, column.Align, true, 0, "")
+			}
+			pdf.Ln(-1)
+			pdf.SetFont("Helvetica", "", 9)
+		}
+		header()
+
+		if len(table.Rows) == 0 {
+			pdf.SetFont("Helvetica", "I", 9)
+			pdf.CellFormat(contentWidth, 6, translate(table.Empty), "", 1, "L", false, 0, "")
+			continue
+		}
+
+		for _, row := range table.Rows {
+			// Break before the row so it is never split from its header
+			if pdf.GetY()+6 > pageHeight-15 {
+				pdf.AddPage()
+				header()
+			}
+			for i, column := range table.Columns {
+				value := ""
+				if i < len(row) {
+					value = row[i]
+				}
+				width := contentWidth * column.Width
+				// Long descriptions are cut to the column rather than wrapped, keeping rows one line high
+				for runes := []rune(value); len(runes) > 0 && pdf.GetStringWidth(translate(value)) > width-2; {
+					runes = runes[:len(runes)-1]
+					value = string(runes)
+				}
+				pdf.CellFormat(width, 6, translate(value), "", 0, column.Align, false, 0, "")
+			}
+			pdf.Ln(-1)
+		}
+	}
+
+	if statement.Footer != "" {
+		pdf.Ln(6)
+		pdf.SetFont("Helvetica", "I", 8)
+		pdf.MultiCell(contentWidth, 4, translate(statement.Footer), "", "C", false)
+	}
+
+	if err := pdf.Error(); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
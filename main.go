@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"go-gin-simple-api/config"
 	"go-gin-simple-api/handler"
 	"go-gin-simple-api/lib"
@@ -8,10 +9,15 @@ import (
 	"go-gin-simple-api/repository"
 	"go-gin-simple-api/service"
 	"log"
+	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 )
 
+// schedulerLockKey identifies the Postgres advisory lock held by the replica running background jobs
+const schedulerLockKey int64 = 0x6c696272617279
+
 func main() {
 	// Load configuration
 	cfg, err := config.LoadConfig()
@@ -30,6 +36,116 @@ func main() {
 		log.Fatalf("Failed to connect to cloudinary: %v", err)
 	}
 
+	stockCodePattern, err := lib.ParseCodePattern(cfg.StockCodePattern)
+	if err != nil {
+		log.Fatalf("Invalid stock code pattern: %v", err)
+	}
+
+	receiptPattern, err := lib.ParseCodePattern(cfg.ReceiptPattern)
+	if err != nil {
+		log.Fatalf("Invalid receipt number pattern: %v", err)
+	}
+
+	holdPickupDays, err := strconv.Atoi(cfg.HoldPickupDays)
+	if err != nil || holdPickupDays < 1 {
+		log.Fatalf("Invalid hold pickup days: %q", cfg.HoldPickupDays)
+	}
+
+	closedWeekdays, err := service.ParseWeeklyClosedDays(cfg.ClosedWeekdays)
+	if err != nil {
+		log.Fatalf("Invalid library closed weekdays: %v", err)
+	}
+
+	membershipMonths, err := strconv.Atoi(cfg.MembershipMonths)
+	if err != nil || membershipMonths < 1 {
+		log.Fatalf("Invalid membership period months: %q", cfg.MembershipMonths)
+	}
+
+	retentionInactiveDays, err := strconv.Atoi(cfg.RetentionInactiveDays)
+	if err != nil || retentionInactiveDays < 0 {
+		log.Fatalf("Invalid retention inactive days: %q", cfg.RetentionInactiveDays)
+	}
+
+	processingFee, err := strconv.ParseFloat(cfg.ProcessingFee, 64)
+	if err != nil || processingFee < 0 {
+		log.Fatalf("Invalid replacement processing fee: %q", cfg.ProcessingFee)
+	}
+
+	eligibilityRules := service.EligibilityRules{}
+	if cfg.MaxUnpaidFines != "" {
+		maxUnpaidFines, err := strconv.ParseFloat(cfg.MaxUnpaidFines, 64)
+		if err != nil || maxUnpaidFines < 0 {
+			log.Fatalf("Invalid maximum unpaid fines: %q", cfg.MaxUnpaidFines)
+		}
+		eligibilityRules.MaxUnpaidFines = &maxUnpaidFines
+	}
+	if eligibilityRules.BlockOverdue, err = strconv.ParseBool(cfg.BlockOverdue); err != nil {
+		log.Fatalf("Invalid block overdue flag: %q", cfg.BlockOverdue)
+	}
+	if eligibilityRules.BlockExpiredMembership, err = strconv.ParseBool(cfg.BlockExpiredMembership); err != nil {
+		log.Fatalf("Invalid block expired membership flag: %q", cfg.BlockExpiredMembership)
+	}
+
+	schedulerEnabled, err := strconv.ParseBool(cfg.SchedulerEnabled)
+	if err != nil {
+		log.Fatalf("Invalid scheduler enabled flag: %q", cfg.SchedulerEnabled)
+	}
+	overdueCheckInterval, err := time.ParseDuration(cfg.OverdueCheckInterval)
+	if err != nil || overdueCheckInterval <= 0 {
+		log.Fatalf("Invalid overdue check interval: %q", cfg.OverdueCheckInterval)
+	}
+	holdExpiryInterval, err := time.ParseDuration(cfg.HoldExpiryInterval)
+	if err != nil || holdExpiryInterval <= 0 {
+		log.Fatalf("Invalid hold expiry interval: %q", cfg.HoldExpiryInterval)
+	}
+	notificationInterval, err := time.ParseDuration(cfg.NotificationInterval)
+	if err != nil || notificationInterval <= 0 {
+		log.Fatalf("Invalid notification interval: %q", cfg.NotificationInterval)
+	}
+	membershipExpiryInterval, err := time.ParseDuration(cfg.MembershipExpiryInterval)
+	if err != nil || membershipExpiryInterval <= 0 {
+		log.Fatalf("Invalid membership expiry interval: %q", cfg.MembershipExpiryInterval)
+	}
+	retentionCheckInterval, err := time.ParseDuration(cfg.RetentionCheckInterval)
+	if err != nil || retentionCheckInterval <= 0 {
+		log.Fatalf("Invalid retention check interval: %q", cfg.RetentionCheckInterval)
+	}
+
+	notificationSchedule := service.NotificationSchedule{}
+	if notificationSchedule.DueReminderDays, err = strconv.Atoi(cfg.DueReminderDays); err != nil || notificationSchedule.DueReminderDays < 0 {
+		log.Fatalf("Invalid due reminder days: %q", cfg.DueReminderDays)
+	}
+	if notificationSchedule.OverdueIntervalDays, err = strconv.Atoi(cfg.OverdueNoticeIntervalDays); err != nil || notificationSchedule.OverdueIntervalDays < 0 {
+		log.Fatalf("Invalid overdue notice interval days: %q", cfg.OverdueNoticeIntervalDays)
+	}
+	if notificationSchedule.MaxOverdueNotices, err = strconv.Atoi(cfg.MaxOverdueNotices); err != nil || notificationSchedule.MaxOverdueNotices < 0 {
+		log.Fatalf("Invalid maximum overdue notices: %q", cfg.MaxOverdueNotices)
+	}
+	if notificationSchedule.MaxAttempts, err = strconv.Atoi(cfg.NotificationMaxAttempts); err != nil || notificationSchedule.MaxAttempts < 1 {
+		log.Fatalf("Invalid notification max attempts: %q", cfg.NotificationMaxAttempts)
+	}
+
+	// Webhooks are always available since customers may be given their own URL
+	notificationChannels := []lib.NotificationChannel{
+		&lib.WebhookChannel{URL: cfg.WebhookURL, Secret: cfg.WebhookSecret},
+	}
+	if cfg.SMTPHost != "" {
+		notificationChannels = append(notificationChannels, &lib.EmailChannel{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+		})
+	}
+	if cfg.SMSGatewayURL != "" {
+		notificationChannels = append(notificationChannels, &lib.SMSChannel{
+			GatewayURL: cfg.SMSGatewayURL,
+			APIKey:     cfg.SMSAPIKey,
+			Sender:     cfg.SMSSender,
+		})
+	}
+
 	// Setup repositories
 	authRepo := repository.NewAuthRepository(db)
 	bookRepo := repository.NewBookRepository(db)
@@ -38,16 +154,107 @@ func main() {
 	customerRepo := repository.NewCustomerRepository(db)
 	chargeRepo := repository.NewChargeRepository(db)
 	bookTransactionRepo := repository.NewBookTransactionRepository(db)
+	codeSequenceRepo := repository.NewCodeSequenceRepository(db)
+	inventoryRepo := repository.NewInventoryRepository(db)
+	branchRepo := repository.NewBranchRepository(db)
+	branchTransferRepo := repository.NewBranchTransferRepository(db)
+	loanPolicyRepo := repository.NewLoanPolicyRepository(db)
+	holdRepo := repository.NewHoldRepository(db)
+	paymentRepo := repository.NewPaymentRepository(db)
+	notificationRepo := repository.NewNotificationRepository(db)
+	calendarRepo := repository.NewCalendarRepository(db)
+	customerMergeRepo := repository.NewCustomerMergeRepository(db)
+	customerPrivacyRepo := repository.NewCustomerPrivacyRepository(db)
+	unitOfWork := repository.NewUnitOfWork(db)
 
 	// Setup services
 	// cloudinaryService := lib.NewCloudinaryService(cfg)
-	authService := service.NewAuthService(authRepo)
+	authService := service.NewAuthService(authRepo, customerRepo, branchRepo)
 	bookService := service.NewBookService(bookRepo, mediaRepo)
 	mediaService := service.NewMediaService(mediaRepo, bookRepo, cloudinary)
-	bookStockService := service.NewBookStockService(bookStockRepo, bookRepo)
-	customerService := service.NewCustomerService(customerRepo, bookTransactionRepo)
-	chargeService := service.NewChargeService(chargeRepo, bookTransactionRepo, authRepo)
-	bookTransactionService := service.NewBookTransactionService(bookTransactionRepo, bookRepo, bookStockRepo, customerRepo)
+	bookStockService := service.NewBookStockService(bookStockRepo, bookRepo, branchRepo, codeSequenceRepo, unitOfWork, stockCodePattern, cfg.StockCodeBranch)
+	calendarService := service.NewCalendarService(calendarRepo, closedWeekdays)
+	customerService := service.NewCustomerService(customerRepo, bookTransactionRepo, membershipMonths)
+	customerMergeService := service.NewCustomerMergeService(customerMergeRepo, customerRepo, authRepo, unitOfWork)
+	chargeService := service.NewChargeService(chargeRepo, bookTransactionRepo, authRepo, unitOfWork, calendarService)
+	bookTransactionService := service.NewBookTransactionService(bookTransactionRepo, bookRepo, bookStockRepo, customerRepo, unitOfWork, holdPickupDays, calendarService, eligibilityRules, processingFee)
+	labelService := service.NewLabelService(bookStockRepo, customerRepo)
+	inventoryService := service.NewInventoryService(inventoryRepo, bookStockRepo, unitOfWork)
+	branchService := service.NewBranchService(branchRepo)
+	branchTransferService := service.NewBranchTransferService(branchTransferRepo, branchRepo, unitOfWork)
+	loanPolicyService := service.NewLoanPolicyService(loanPolicyRepo)
+	holdService := service.NewHoldService(holdRepo, bookRepo, bookStockRepo, unitOfWork, holdPickupDays)
+	notificationService := service.NewNotificationService(notificationRepo, bookTransactionRepo, holdRepo, customerRepo, notificationChannels, notificationSchedule)
+	paymentService := service.NewPaymentService(paymentRepo, chargeRepo, customerRepo, authRepo, branchRepo, codeSequenceRepo, unitOfWork, receiptPattern, cfg.StockCodeBranch)
+	customerStatementService := service.NewCustomerStatementService(customerRepo, bookTransactionRepo, chargeRepo)
+	householdService := service.NewHouseholdService(customerRepo, bookTransactionRepo, chargeRepo, unitOfWork)
+	customerPrivacyService := service.NewCustomerPrivacyService(customerRepo, bookTransactionRepo, chargeRepo, paymentRepo, holdRepo, notificationRepo, customerPrivacyRepo, unitOfWork, retentionInactiveDays)
+
+	// Setup background jobs
+	if schedulerEnabled {
+		jobs := []lib.Job{
+			{
+				Name:     "mark-overdue",
+				Interval: overdueCheckInterval,
+				Run: func() error {
+					count, err := bookTransactionService.MarkOverdue()
+					if err == nil && count > 0 {
+						log.Printf("Scheduler: marked %d loans overdue", count)
+					}
+					return err
+				},
+			},
+			{
+				Name:     "expire-holds",
+				Interval: holdExpiryInterval,
+				Run: func() error {
+					result, err := holdService.ExpirePickups()
+					if result != nil && result.Expired > 0 {
+						log.Printf("Scheduler: expired %d holds, %d copies passed on", result.Expired, result.Reassigned)
+					}
+					return err
+				},
+			},
+			{
+				Name:     "expire-memberships",
+				Interval: membershipExpiryInterval,
+				Run: func() error {
+					count, err := customerService.ExpireMemberships()
+					if err == nil && count > 0 {
+						log.Printf("Scheduler: expired %d memberships", count)
+					}
+					return err
+				},
+			},
+			{
+				Name:     "send-notifications",
+				Interval: notificationInterval,
+				Run: func() error {
+					result, err := notificationService.SendPending()
+					if result != nil && result.DueReminders+result.OverdueNotices+result.HoldReady+result.Failed > 0 {
+						log.Printf("Scheduler: sent %d due reminders, %d overdue notices and %d hold-ready notices, %d deliveries failed",
+							result.DueReminders, result.OverdueNotices, result.HoldReady, result.Failed)
+					}
+					return err
+				},
+			},
+		}
+		if retentionInactiveDays > 0 {
+			jobs = append(jobs, lib.Job{
+				Name:     "anonymize-inactive",
+				Interval: retentionCheckInterval,
+				Run: func() error {
+					result, err := customerPrivacyService.AnonymizeInactive()
+					if result != nil && result.Anonymized+len(result.Skipped) > 0 {
+						log.Printf("Scheduler: anonymized %d inactive customers, %d skipped", result.Anonymized, len(result.Skipped))
+					}
+					return err
+				},
+			})
+		}
+		scheduler := lib.NewScheduler(repository.NewLeaderLock(db, schedulerLockKey), jobs...)
+		scheduler.Start(context.Background())
+	}
 
 	// Setup handlers
 	authHandler := handler.NewAuthHandler(authService)
@@ -55,8 +262,21 @@ func main() {
 	mediaHandler := handler.NewMediaHandler(mediaService)
 	bookStockHandler := handler.NewBookStockHandler(bookStockService)
 	customerHandler := handler.NewCustomerHandler(customerService)
+	customerMergeHandler := handler.NewCustomerMergeHandler(customerMergeService)
+	customerPrivacyHandler := handler.NewCustomerPrivacyHandler(customerPrivacyService)
+	customerStatementHandler := handler.NewCustomerStatementHandler(customerStatementService)
+	householdHandler := handler.NewHouseholdHandler(householdService)
 	chargeHandler := handler.NewChargeHandler(chargeService)
 	bookTransactionHandler := handler.NewBookTransactionHandler(bookTransactionService)
+	labelHandler := handler.NewLabelHandler(labelService)
+	inventoryHandler := handler.NewInventoryHandler(inventoryService)
+	branchHandler := handler.NewBranchHandler(branchService)
+	branchTransferHandler := handler.NewBranchTransferHandler(branchTransferService)
+	loanPolicyHandler := handler.NewLoanPolicyHandler(loanPolicyService)
+	holdHandler := handler.NewHoldHandler(holdService)
+	paymentHandler := handler.NewPaymentHandler(paymentService)
+	notificationHandler := handler.NewNotificationHandler(notificationService)
+	calendarHandler := handler.NewCalendarHandler(calendarService)
 
 	// Setup router
 	router := gin.Default()
@@ -69,6 +289,24 @@ func main() {
 	// API routes with middleware
 	api.Use(middleware.JWTAuth(authRepo))
 
+	// User routes (admin only)
+	userRoute := api.Group("/users", middleware.RoleAuth("admin"))
+	userRoute.PUT("/:id/customer", authHandler.LinkCustomer)
+	userRoute.PUT("/:id/branch", middleware.AllBranches(), authHandler.AssignBranch)
+
+	// Self-service routes for members linked to a customer
+	meRoute := api.Group("/me")
+	meRoute.GET("/loans", bookTransactionHandler.GetMyLoans)
+	meRoute.GET("/charges", paymentHandler.GetMyCharges)
+	meRoute.GET("/statement", customerStatementHandler.GetMyStatement)
+	meRoute.GET("/household", householdHandler.GetMyHousehold)
+	meRoute.GET("/holds", holdHandler.GetMyHolds)
+	meRoute.POST("/loans/:id/renew", bookTransactionHandler.Renew)
+	meRoute.GET("/notifications", notificationHandler.GetMine)
+	meRoute.GET("/notification-preferences", notificationHandler.GetMyPreferences)
+	meRoute.PUT("/notification-preferences", notificationHandler.UpdateMyPreferences)
+	meRoute.GET("/export", customerPrivacyHandler.ExportMine)
+
 	// Book routes
 	bookRoute := api.Group("/books")
 	bookRoute.GET("/", bookHandler.GetBooks)
@@ -91,52 +329,167 @@ func main() {
 	bookStock.GET("/:code", bookStockHandler.GetByCode)
 	bookStock.GET("/book/:book_id", bookStockHandler.GetByBookID)
 	bookStock.GET("/book/:book_id/available", bookStockHandler.GetAvailableByBookID)
+	bookStock.GET("/book/:book_id/availability", bookStockHandler.GetAvailabilityByBookID)
+	bookStock.GET("/:code/label", labelHandler.GetBookStockLabel)
+	bookStock.GET("/:code/history", bookStockHandler.GetStatusHistory)
 
 	// Protected routes (admin only)
 	bookStock.POST("", middleware.RoleAuth("admin"), bookStockHandler.Create)
+	bookStock.POST("/bulk", middleware.RoleAuth("admin"), bookStockHandler.BulkCreate)
 	bookStock.PUT("/:code", middleware.RoleAuth("admin"), bookStockHandler.Update)
 	bookStock.DELETE("/:code", middleware.RoleAuth("admin"), bookStockHandler.Delete)
 	bookStock.PATCH("/:code/status", middleware.RoleAuth("admin"), bookStockHandler.UpdateStatus)
+	bookStock.POST("/:code/found", middleware.RoleAuth("admin"), bookStockHandler.MarkFound)
+	bookStock.POST("/labels", middleware.RoleAuth("admin"), labelHandler.GetBookStockLabelSheet)
 
 	// Customer routes
 	customerRoute := api.Group("/customers")
-	customerRoute.GET("", customerHandler.GetAll)
+	// Listing is for staff, members may only read their own record and their dependants'
+	customerRoute.GET("", middleware.RoleAuth("admin"), customerHandler.GetAll)
 	customerRoute.GET("/:id", customerHandler.GetByID)
 	customerRoute.GET("/:id/transactions", customerHandler.GetByIDWithTransactions)
 	customerRoute.GET("/code/:code", customerHandler.GetByCode)
+	customerRoute.GET("/code/:code/label", middleware.RoleAuth("admin"), labelHandler.GetCustomerLabel)
+	customerRoute.GET("/:id/balance", paymentHandler.GetCustomerBalance)
+	customerRoute.GET("/:id/statement", customerStatementHandler.GetStatement)
+	customerRoute.GET("/:id/household", householdHandler.GetHousehold)
+	customerRoute.GET("/:id/eligibility", bookTransactionHandler.CheckEligibility)
+	customerRoute.GET("/:id/overrides", middleware.RoleAuth("admin"), bookTransactionHandler.GetEligibilityOverrides)
+	customerRoute.GET("/:id/notifications", notificationHandler.GetByCustomerID)
+	customerRoute.GET("/:id/notification-preferences", notificationHandler.GetPreferences)
+	customerRoute.PUT("/:id/notification-preferences", notificationHandler.UpdatePreferences)
+	customerRoute.GET("/:id/export", customerPrivacyHandler.Export)
 
 	// Protected customer routes (admin only)
 	customerRoute.POST("", middleware.RoleAuth("admin"), customerHandler.Create)
 	customerRoute.PUT("/:id", middleware.RoleAuth("admin"), customerHandler.Update)
+	customerRoute.POST("/:id/renew-membership", middleware.RoleAuth("admin"), customerHandler.RenewMembership)
+	customerRoute.PUT("/:id/guardian", middleware.RoleAuth("admin"), householdHandler.SetGuardian)
+	customerRoute.DELETE("/:id/guardian", middleware.RoleAuth("admin"), householdHandler.RemoveGuardian)
+
+	// Duplicate detection and merging (admin only)
+	customerRoute.GET("/duplicates", middleware.RoleAuth("admin"), customerMergeHandler.GetDuplicates)
+	customerRoute.GET("/:id/duplicates", middleware.RoleAuth("admin"), customerMergeHandler.GetDuplicatesOf)
+	customerRoute.POST("/merge", middleware.RoleAuth("admin"), customerMergeHandler.Merge)
+	customerRoute.GET("/merges", middleware.RoleAuth("admin"), customerMergeHandler.GetAll)
+	customerRoute.GET("/merges/:id", middleware.RoleAuth("admin"), customerMergeHandler.GetByID)
 	customerRoute.DELETE("/:id", middleware.RoleAuth("admin"), customerHandler.Delete)
+	customerRoute.POST("/labels", middleware.RoleAuth("admin"), labelHandler.GetCustomerLabelSheet)
+
+	// Personal data retention (admin only)
+	customerRoute.POST("/:id/anonymize", middleware.RoleAuth("admin"), customerPrivacyHandler.Anonymize)
+	customerRoute.POST("/anonymize-inactive", middleware.RoleAuth("admin"), customerPrivacyHandler.AnonymizeInactive)
 
 	// Charge routes
 	chargeRoute := api.Group("/charges")
-	chargeRoute.GET("", chargeHandler.GetAll)
-	chargeRoute.GET("/:id", chargeHandler.GetByID)
-	chargeRoute.GET("/transaction/:transaction_id", chargeHandler.GetByBookTransactionID)
-	chargeRoute.GET("/user/:user_id", chargeHandler.GetByUserID)
+	// Charges of every customer are staff only, members see their own under /me/charges
+	chargeRoute.GET("", middleware.RoleAuth("admin"), chargeHandler.GetAll)
+	chargeRoute.GET("/:id", middleware.RoleAuth("admin"), chargeHandler.GetByID)
+	chargeRoute.GET("/transaction/:transaction_id", middleware.RoleAuth("admin"), chargeHandler.GetByBookTransactionID)
+	chargeRoute.GET("/user/:user_id", middleware.RoleAuth("admin"), chargeHandler.GetByUserID)
+	chargeRoute.GET("/:id/balance", middleware.RoleAuth("admin"), paymentHandler.GetChargeBalance)
 
 	// Protected charge routes
-	chargeRoute.POST("", chargeHandler.Create) // This already checks userData
+	chargeRoute.POST("", middleware.RoleAuth("admin"), chargeHandler.Create)
 	chargeRoute.PUT("/:id", middleware.RoleAuth("admin"), chargeHandler.Update)
 	chargeRoute.DELETE("/:id", middleware.RoleAuth("admin"), chargeHandler.Delete)
+	chargeRoute.POST("/preview", middleware.RoleAuth("admin"), chargeHandler.Preview)
+	chargeRoute.POST("/:id/payments", middleware.RoleAuth("admin"), paymentHandler.Pay)
+	chargeRoute.POST("/:id/adjustments", middleware.RoleAuth("admin"), paymentHandler.Adjust)
+
+	// Payment routes (admin only)
+	paymentRoute := api.Group("/payments", middleware.RoleAuth("admin"))
+	paymentRoute.GET("", paymentHandler.GetAll)
+	paymentRoute.GET("/:id", paymentHandler.GetByID)
+	paymentRoute.GET("/:id/receipt", paymentHandler.GetReceipt)
 
 	// Book transaction routes
+	// Members only see loans of the customer their account is linked to
 	transactionRoute := api.Group("/transactions")
-	transactionRoute.GET("", bookTransactionHandler.GetAll)
 	transactionRoute.GET("/:id", bookTransactionHandler.GetByID)
 	transactionRoute.GET("/customer/:customer_id", bookTransactionHandler.GetByCustomerID)
-	transactionRoute.GET("/book/:book_id", bookTransactionHandler.GetByBookID)
-	transactionRoute.GET("/stock/:stock_code", bookTransactionHandler.GetByStockCode)
-	transactionRoute.GET("/overdue", bookTransactionHandler.GetOverdueTransactions)
+
+	// Listings across customers (admin only)
+	transactionRoute.GET("", middleware.RoleAuth("admin"), bookTransactionHandler.GetAll)
+	transactionRoute.GET("/book/:book_id", middleware.RoleAuth("admin"), bookTransactionHandler.GetByBookID)
+	transactionRoute.GET("/stock/:stock_code", middleware.RoleAuth("admin"), bookTransactionHandler.GetByStockCode)
+	transactionRoute.GET("/overdue", middleware.RoleAuth("admin"), bookTransactionHandler.GetOverdueTransactions)
+	transactionRoute.GET("/:id/renewals", middleware.RoleAuth("admin"), bookTransactionHandler.GetRenewals)
+	transactionRoute.GET("/:id/events", middleware.RoleAuth("admin"), bookTransactionHandler.GetEvents)
 
 	// Protected book transaction routes (admin only)
 	transactionRoute.POST("", middleware.RoleAuth("admin"), bookTransactionHandler.Create)
+	transactionRoute.POST("/bulk", middleware.RoleAuth("admin"), bookTransactionHandler.BulkCheckout)
+	transactionRoute.POST("/bulk-return", middleware.RoleAuth("admin"), bookTransactionHandler.BulkReturn)
 	transactionRoute.PUT("/:id", middleware.RoleAuth("admin"), bookTransactionHandler.Update)
 	transactionRoute.DELETE("/:id", middleware.RoleAuth("admin"), bookTransactionHandler.Delete)
 	transactionRoute.PATCH("/:id/status", middleware.RoleAuth("admin"), bookTransactionHandler.UpdateStatus)
 	transactionRoute.POST("/:id/return", middleware.RoleAuth("admin"), bookTransactionHandler.ReturnBook)
+	transactionRoute.POST("/:id/renew", middleware.RoleAuth("admin"), bookTransactionHandler.Renew)
+	transactionRoute.POST("/:id/loss", middleware.RoleAuth("admin"), bookTransactionHandler.ReportLoss)
+	transactionRoute.POST("/:id/corrections", middleware.RoleAuth("admin"), bookTransactionHandler.Correct)
+
+	// Notification routes (admin only)
+	notificationRoute := api.Group("/notifications", middleware.RoleAuth("admin"))
+	notificationRoute.GET("", notificationHandler.GetAll)
+	notificationRoute.POST("/send", notificationHandler.SendPending)
+	notificationRoute.GET("/templates", notificationHandler.GetTemplates)
+	notificationRoute.PUT("/templates", notificationHandler.UpdateTemplate)
+
+	// Inventory routes (admin only)
+	inventoryRoute := api.Group("/inventories", middleware.RoleAuth("admin"))
+	inventoryRoute.GET("", inventoryHandler.GetAll)
+	inventoryRoute.GET("/:id", inventoryHandler.GetByID)
+	inventoryRoute.GET("/:id/report", inventoryHandler.GetReport)
+	inventoryRoute.POST("", inventoryHandler.Create)
+	inventoryRoute.POST("/:id/scans", inventoryHandler.Scan)
+	inventoryRoute.POST("/:id/close", inventoryHandler.Close)
+	inventoryRoute.POST("/:id/mark-lost", inventoryHandler.MarkMissingLost)
+
+	// Branch routes
+	branchRoute := api.Group("/branches")
+	branchRoute.GET("", branchHandler.GetAll)
+	branchRoute.GET("/:id", branchHandler.GetByID)
+
+	// Protected branch routes (admin not bound to a branch)
+	branchRoute.POST("", middleware.RoleAuth("admin"), middleware.AllBranches(), branchHandler.Create)
+	branchRoute.PUT("/:id", middleware.RoleAuth("admin"), middleware.AllBranches(), branchHandler.Update)
+	branchRoute.DELETE("/:id", middleware.RoleAuth("admin"), middleware.AllBranches(), branchHandler.Delete)
+
+	// Calendar routes, changes by admins (branch staff for their own branch only)
+	calendarRoute := api.Group("/calendar")
+	calendarRoute.GET("/opening-hours", calendarHandler.GetOpeningHours)
+	calendarRoute.GET("/closures", calendarHandler.GetClosures)
+	calendarRoute.GET("/days", calendarHandler.GetDays)
+	calendarRoute.PUT("/opening-hours", middleware.RoleAuth("admin"), calendarHandler.SetOpeningHours)
+	calendarRoute.POST("/closures", middleware.RoleAuth("admin"), calendarHandler.CreateClosure)
+	calendarRoute.DELETE("/closures/:id", middleware.RoleAuth("admin"), calendarHandler.DeleteClosure)
+
+	// Transfer routes (admin only)
+	transferRoute := api.Group("/transfers", middleware.RoleAuth("admin"))
+	transferRoute.GET("", branchTransferHandler.GetAll)
+	transferRoute.GET("/:id", branchTransferHandler.GetByID)
+	transferRoute.POST("", branchTransferHandler.Create)
+	transferRoute.POST("/:id/ship", branchTransferHandler.Ship)
+	transferRoute.POST("/:id/receive", branchTransferHandler.Receive)
+	transferRoute.POST("/:id/cancel", branchTransferHandler.Cancel)
+
+	// Loan policy routes (admin only)
+	loanPolicyRoute := api.Group("/loan-policies", middleware.RoleAuth("admin"))
+	loanPolicyRoute.GET("", loanPolicyHandler.GetAll)
+	loanPolicyRoute.GET("/:id", loanPolicyHandler.GetByID)
+	loanPolicyRoute.POST("", loanPolicyHandler.Create)
+	loanPolicyRoute.PUT("/:id", loanPolicyHandler.Update)
+	loanPolicyRoute.DELETE("/:id", loanPolicyHandler.Delete)
+
+	// Hold routes (admin only, members see their own holds under /me/holds)
+	holdRoute := api.Group("/holds", middleware.RoleAuth("admin"))
+	holdRoute.GET("", holdHandler.GetAll)
+	holdRoute.GET("/:id", holdHandler.GetByID)
+	holdRoute.GET("/book/:book_id", holdHandler.GetByBookID)
+	holdRoute.POST("", holdHandler.Create)
+	holdRoute.POST("/:id/cancel", holdHandler.Cancel)
+	holdRoute.POST("/expire", holdHandler.ExpirePickups)
 
 	// User routes
 	// api.GET("/users", middleware.RoleAuth("admin"), userHandler.GetUsers)
@@ -41,7 +41,8 @@ func JWTAuth(r repository.AuthRepository) gin.HandlerFunc {
 			return
 		}
 
-		if user, errExist := r.FindByEmail(userData.Email); errExist != nil || user.ID != userData.ID {
+		user, errExist := r.FindByEmail(userData.Email)
+		if errExist != nil || user.ID != userData.ID {
 			c.JSON(http.StatusNotFound, dto.ResponseError{
 				Status:  http.StatusNotFound,
 				Message: "User not found",
@@ -50,6 +51,19 @@ func JWTAuth(r repository.AuthRepository) gin.HandlerFunc {
 			return
 		}
 
+		// Branch assignments and customer links are read from the database so changes apply without a new token
+		userData.BranchID = user.BranchID
+		userData.CustomerID = user.CustomerID
+		if user.CustomerID != nil {
+			dependantIDs, err := r.FindDependantIDs(*user.CustomerID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, dto.ResponseError{Status: http.StatusInternalServerError, Message: "Failed to load dependants"})
+				c.Abort()
+				return
+			}
+			userData.DependantIDs = dependantIDs
+		}
+
 		// Set user data in context for use in handlers
 		c.Set("userData", userData)
 		c.Next()
@@ -90,3 +104,30 @@ func RoleAuth(roles ...string) gin.HandlerFunc {
 		c.Next()
 	}
 }
+
+// AllBranches only lets through staff that are not bound to a single branch
+func AllBranches() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userData, exists := c.Get("userData")
+		if !exists {
+			c.JSON(http.StatusUnauthorized, dto.ResponseError{Status: http.StatusUnauthorized, Message: "User data not found"})
+			c.Abort()
+			return
+		}
+
+		user, ok := userData.(dto.UserData)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, dto.ResponseError{Status: http.StatusInternalServerError, Message: "Invalid user data"})
+			c.Abort()
+			return
+		}
+
+		if user.BranchID != nil {
+			c.JSON(http.StatusForbidden, dto.ResponseError{Status: http.StatusForbidden, Message: "Access denied for branch staff"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
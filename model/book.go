@@ -11,6 +11,7 @@ type Book struct {
 	ID               uuid.UUID         `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
 	Title            string            `gorm:"size:255;not null" json:"title"`
 	Description      string            `gorm:"type:text" json:"description"`
+	MaterialType     string            `gorm:"size:50;not null;default:''" json:"material_type"`
 	CoverID          *uuid.UUID        `json:"cover_id"`
 	Cover            *Media            `gorm:"foreignKey:CoverID" json:"cover,omitempty"`
 	CreatedAt        time.Time         `json:"created_at"`
@@ -1,14 +1,22 @@
 package model
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 )
 
 type BookStock struct {
-	Code             string            `gorm:"primaryKey;size:50" json:"code"`
-	BookID           uuid.UUID         `gorm:"not null" json:"book_id"`
-	Book             Book              `gorm:"foreignKey:BookID" json:"book"`
-	Status           string            `gorm:"size:50;not null" json:"status"` // Available, Borrowed, Damaged, Lost
+	Code            string     `gorm:"primaryKey;size:50" json:"code"`
+	BookID          uuid.UUID  `gorm:"not null" json:"book_id"`
+	Book            Book       `gorm:"foreignKey:BookID" json:"book"`
+	Status          string     `gorm:"size:50;not null" json:"status"` // Available, Borrowed, Damaged, Lost, InRepair, Withdrawn, InTransit, OnHold
+	OwnerBranchID   *uuid.UUID `gorm:"type:uuid;index" json:"owner_branch_id"`
+	OwnerBranch     *Branch    `gorm:"foreignKey:OwnerBranchID" json:"owner_branch,omitempty"`
+	CurrentBranchID *uuid.UUID `gorm:"type:uuid;index" json:"current_branch_id"`
+	CurrentBranch   *Branch    `gorm:"foreignKey:CurrentBranchID" json:"current_branch,omitempty"`
+	// What it costs to replace the copy, charged when it is lost or damaged on loan
+	Price            float64           `gorm:"not null;default:0" json:"price"`
 	BookTransactions []BookTransaction `gorm:"foreignKey:StockCode;references:Code" json:"book_transactions,omitempty"`
 }
 
@@ -17,4 +25,31 @@ const (
 	StatusBorrowed  = "Borrowed"
 	StatusDamaged   = "Damaged"
 	StatusLost      = "Lost"
+	StatusInRepair  = "InRepair"
+	StatusWithdrawn = "Withdrawn"
+	StatusInTransit = "InTransit"
+	StatusOnHold    = "OnHold"
+)
+
+// Actions that move a book stock from one status to another
+const (
+	StockActionUpdate   = "Update"   // Manual change by staff
+	StockActionCheckout = "Checkout" // Copy lent out through a transaction
+	StockActionReturn   = "Return"   // Copy came back through a transaction
+	StockActionFound    = "Found"    // Lost copy turned up again
+	StockActionTransfer = "Transfer" // Copy shipped to or received at another branch
+	StockActionHold     = "Hold"     // Copy set aside for or released from a hold
+	StockActionLoss     = "Loss"     // Copy reported lost or damaged while on loan
 )
+
+// BookStockStatusHistory records every status change of a book stock
+type BookStockStatusHistory struct {
+	ID         uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	StockCode  string     `gorm:"size:50;not null;index" json:"stock_code"`
+	FromStatus string     `gorm:"size:50;not null" json:"from_status"`
+	ToStatus   string     `gorm:"size:50;not null" json:"to_status"`
+	Action     string     `gorm:"size:50;not null" json:"action"`
+	Reason     string     `gorm:"type:text" json:"reason"`
+	UserID     *uuid.UUID `json:"user_id"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
@@ -14,15 +14,53 @@ type BookTransaction struct {
 	BookStock  BookStock  `gorm:"foreignKey:StockCode;references:Code" json:"book_stock,omitempty"`
 	CustomerID uuid.UUID  `gorm:"not null" json:"customer_id"`
 	Customer   Customer   `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+	BranchID   *uuid.UUID `gorm:"type:uuid;index" json:"branch_id"` // Branch the copy was lent from, whose calendar sets due dates
 	DueDate    time.Time  `json:"due_date"`
-	Status     string     `gorm:"size:50;not null" json:"status"` // Borrowed, Returned, Overdue
+	Status     string     `gorm:"size:50;not null" json:"status"` // Borrowed, Returned, Overdue, Lost, Damaged
 	BorrowedAt *time.Time `json:"borrowed_at"`
 	ReturnAt   *time.Time `json:"return_at"`
 	Charges    []Charge   `gorm:"foreignKey:BookTransactionID" json:"charges,omitempty"`
+	// Loan terms applied at checkout, copied so later policy changes do not affect the loan
+	LoanPolicyID    *uuid.UUID    `gorm:"type:uuid" json:"loan_policy_id"`
+	LoanPolicy      *LoanPolicy   `gorm:"foreignKey:LoanPolicyID" json:"loan_policy,omitempty"`
+	LoanPeriodDays  int           `gorm:"not null;default:7" json:"loan_period_days"`
+	DailyLateFee    float64       `gorm:"not null;default:0" json:"daily_late_fee"`
+	MaxRenewals     int           `gorm:"not null;default:0" json:"max_renewals"`
+	GracePeriodDays int           `gorm:"not null;default:0" json:"grace_period_days"`
+	MaxLateFee      float64       `gorm:"not null;default:0" json:"max_late_fee"`
+	SkipClosedDays  bool          `gorm:"not null;default:false" json:"skip_closed_days"`
+	RenewalCount    int           `gorm:"not null;default:0" json:"renewal_count"`
+	Renewals        []LoanRenewal `gorm:"foreignKey:BookTransactionID" json:"renewals,omitempty"`
 }
 
 const (
 	StatusBTBorrowed = "Borrowed"
 	StatusBTReturned = "Returned"
 	StatusBTOverdue  = "Overdue"
+	StatusBTLost     = "Lost"
+	StatusBTDamaged  = "Damaged"
 )
+
+// Actions that move a book transaction from one status to another
+const (
+	LoanActionCheckout   = "Checkout"   // Loan opened at the desk
+	LoanActionReturn     = "Return"     // Copy came back
+	LoanActionOverdue    = "Overdue"    // Due date passed, marked by the scheduler
+	LoanActionUpdate     = "Update"     // Manual change by staff on an open loan
+	LoanActionLoss       = "Loss"       // Copy reported lost or damaged
+	LoanActionFound      = "Found"      // Copy reported lost was returned after all
+	LoanActionCorrection = "Correction" // Explicit fix of a closed loan
+)
+
+// BookTransactionEvent records every status change and correction of a book transaction
+type BookTransactionEvent struct {
+	ID                uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	BookTransactionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"book_transaction_id"`
+	FromStatus        string     `gorm:"size:50;not null" json:"from_status"`
+	ToStatus          string     `gorm:"size:50;not null" json:"to_status"`
+	Action            string     `gorm:"size:50;not null" json:"action"`
+	Reason            string     `gorm:"type:text" json:"reason"`
+	UserID            *uuid.UUID `json:"user_id"`
+	User              *User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
@@ -0,0 +1,44 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type Branch struct {
+	ID        uuid.UUID      `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	Code      string         `gorm:"size:10;not null;unique" json:"code"`
+	Name      string         `gorm:"size:255;not null" json:"name"`
+	Address   string         `gorm:"type:text" json:"address"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+// BranchTransfer moves a book stock from one branch to another
+type BranchTransfer struct {
+	ID           uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	StockCode    string     `gorm:"size:50;not null;index" json:"stock_code"`
+	BookStock    BookStock  `gorm:"foreignKey:StockCode;references:Code" json:"book_stock,omitempty"`
+	FromBranchID uuid.UUID  `gorm:"type:uuid;not null" json:"from_branch_id"`
+	FromBranch   Branch     `gorm:"foreignKey:FromBranchID" json:"from_branch,omitempty"`
+	ToBranchID   uuid.UUID  `gorm:"type:uuid;not null" json:"to_branch_id"`
+	ToBranch     Branch     `gorm:"foreignKey:ToBranchID" json:"to_branch,omitempty"`
+	Status       string     `gorm:"size:50;not null" json:"status"` // Requested, InTransit, Received, Cancelled
+	Notes        string     `gorm:"type:text" json:"notes"`
+	RequestedBy  uuid.UUID  `gorm:"type:uuid;not null" json:"requested_by"`
+	RequestedAt  time.Time  `json:"requested_at"`
+	ShippedBy    *uuid.UUID `gorm:"type:uuid" json:"shipped_by"`
+	ShippedAt    *time.Time `json:"shipped_at"`
+	ReceivedBy   *uuid.UUID `gorm:"type:uuid" json:"received_by"`
+	ReceivedAt   *time.Time `json:"received_at"`
+}
+
+const (
+	StatusTransferRequested = "Requested"
+	StatusTransferInTransit = "InTransit"
+	StatusTransferReceived  = "Received"
+	StatusTransferCancelled = "Cancelled"
+)
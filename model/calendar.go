@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OpeningHours are the hours a branch is open on one weekday. Rows without a branch apply
+// library-wide; a branch row replaces the library-wide row for that weekday.
+type OpeningHours struct {
+	ID       uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	BranchID *uuid.UUID `gorm:"type:uuid;index" json:"branch_id"`
+	Branch   *Branch    `gorm:"foreignKey:BranchID" json:"branch,omitempty"`
+	Weekday  int        `gorm:"not null" json:"weekday"`                    // 0 is Sunday, as time.Weekday
+	OpensAt  string     `gorm:"size:5;not null;default:''" json:"opens_at"` // HH:MM
+	ClosesAt string     `gorm:"size:5;not null;default:''" json:"closes_at"`
+	Closed   bool       `gorm:"not null;default:false" json:"closed"`
+}
+
+// Closure closes a branch, or every branch when it has none, from StartDate to EndDate inclusive.
+// Dates are kept as YYYY-MM-DD so a holiday is the same day whatever the time zone.
+type Closure struct {
+	ID        uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	BranchID  *uuid.UUID `gorm:"type:uuid;index" json:"branch_id"`
+	Branch    *Branch    `gorm:"foreignKey:BranchID" json:"branch,omitempty"`
+	StartDate string     `gorm:"size:10;not null;index" json:"start_date"`
+	EndDate   string     `gorm:"size:10;not null;index" json:"end_date"`
+	Reason    string     `gorm:"size:255;not null" json:"reason"`
+	UserID    uuid.UUID  `gorm:"type:uuid;not null" json:"user_id"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// CalendarDateLayout is the layout of closure dates
+const CalendarDateLayout = "2006-01-02"
@@ -10,10 +10,35 @@ type Charge struct {
 	ID                uuid.UUID       `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
 	BookTransactionID uuid.UUID       `gorm:"not null" json:"book_transaction_id"`
 	BookTransaction   BookTransaction `gorm:"foreignKey:BookTransactionID" json:"book_transaction,omitempty"`
+	Type              string          `gorm:"size:50;not null;default:'LateFee'" json:"type"` // LateFee, Replacement
 	DaysLate          int             `gorm:"not null" json:"days_late"`
 	DailyLateFee      float64         `gorm:"not null" json:"daily_late_fee"`
 	Total             float64         `gorm:"not null" json:"total"`
 	UserID            uuid.UUID       `gorm:"not null" json:"user_id"`
 	User              User            `gorm:"foreignKey:UserID" json:"user,omitempty"`
 	CreatedAt         time.Time       `json:"created_at"`
+	// Set once the loan is closed; a frozen charge no longer changes with time or edits
+	FrozenAt *time.Time `json:"frozen_at"`
+	// Replacement charges for lost or damaged copies: the copy's price plus a processing fee
+	ReplacementCost float64 `gorm:"not null;default:0" json:"replacement_cost"`
+	ProcessingFee   float64 `gorm:"not null;default:0" json:"processing_fee"`
+	// Set when a lost copy turned up again and the replacement cost was credited back
+	ReversedAt *time.Time `json:"reversed_at"`
+	// Ledger of what was paid, forgiven and paid back
+	Payments    []Payment          `gorm:"foreignKey:ChargeID" json:"payments,omitempty"`
+	Adjustments []ChargeAdjustment `gorm:"foreignKey:ChargeID" json:"adjustments,omitempty"`
+	Refunds     []Refund           `gorm:"foreignKey:ChargeID" json:"refunds,omitempty"`
 }
+
+const (
+	ChargeTypeLateFee     = "LateFee"
+	ChargeTypeReplacement = "Replacement"
+)
+
+// Payment status of a charge, derived from its ledger
+const (
+	ChargeStatusUnpaid        = "Unpaid"
+	ChargeStatusPartiallyPaid = "PartiallyPaid"
+	ChargeStatusPaid          = "Paid"
+	ChargeStatusWaived        = "Waived"
+)
@@ -0,0 +1,11 @@
+package model
+
+import (
+	"time"
+)
+
+type CodeSequence struct {
+	Name      string    `gorm:"primaryKey;size:100" json:"name"`
+	Value     int64     `gorm:"not null;default:0" json:"value"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -8,11 +8,33 @@ import (
 )
 
 type Customer struct {
-	ID               uuid.UUID         `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
-	Code             string            `gorm:"size:50;not null;unique" json:"code"`
-	Name             string            `gorm:"size:255;not null" json:"name"`
-	CreatedAt        time.Time         `json:"created_at"`
-	UpdatedAt        time.Time         `json:"updated_at"`
-	DeletedAt        gorm.DeletedAt    `gorm:"index" json:"-"`
-	BookTransactions []BookTransaction `gorm:"foreignKey:CustomerID" json:"book_transactions,omitempty"`
+	ID       uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	Code     string    `gorm:"size:50;not null;unique" json:"code"`
+	Name     string    `gorm:"size:255;not null" json:"name"`
+	Category string    `gorm:"size:50;not null;default:''" json:"category"` // e.g. Student, Staff, Public; matched by loan policies
+	// Contact details
+	Email       string     `gorm:"size:255;not null;default:'';index" json:"email"`
+	Phone       string     `gorm:"size:20;not null;default:''" json:"phone"`
+	Address     string     `gorm:"type:text;not null;default:''" json:"address"`
+	DateOfBirth *time.Time `gorm:"type:date" json:"date_of_birth"`
+	// Membership; memberships without an expiry date never expire
+	MembershipStartedAt *time.Time        `json:"membership_started_at"`
+	MembershipExpiresAt *time.Time        `gorm:"index" json:"membership_expires_at"`
+	Status              string            `gorm:"size:20;not null;default:'Active';index" json:"status"` // Active, Suspended, Expired, Anonymized
+	StatusReason        string            `gorm:"size:255;not null;default:''" json:"status_reason"`
+	AnonymizedAt        *time.Time        `json:"anonymized_at"` // Personal data scrubbed; loans stay for statistics
+	CreatedAt           time.Time         `json:"created_at"`
+	UpdatedAt           time.Time         `json:"updated_at"`
+	DeletedAt           gorm.DeletedAt    `gorm:"index" json:"-"`
+	BookTransactions    []BookTransaction `gorm:"foreignKey:CustomerID" json:"book_transactions,omitempty"`
+	// Responsible adult of a dependant such as a child; guardians cannot have a guardian themselves
+	GuardianID *uuid.UUID `gorm:"type:uuid;index" json:"guardian_id"`
+	Guardian   *Customer  `gorm:"foreignKey:GuardianID" json:"guardian,omitempty"`
 }
+
+const (
+	CustomerStatusActive     = "Active"
+	CustomerStatusSuspended  = "Suspended"  // Set by staff, blocks borrowing until lifted
+	CustomerStatusExpired    = "Expired"    // Membership past its expiry date, until renewed
+	CustomerStatusAnonymized = "Anonymized" // Personal data erased, the record only counts in statistics
+)
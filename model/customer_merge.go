@@ -0,0 +1,38 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CustomerMerge records a duplicate customer folded into the customer that survives it.
+// The merged customer is soft deleted; Snapshot keeps it as it was, as JSON.
+type CustomerMerge struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	SurvivorID uuid.UUID `gorm:"type:uuid;not null;index" json:"survivor_id"`
+	Survivor   *Customer `gorm:"foreignKey:SurvivorID" json:"survivor,omitempty"`
+	MergedID   uuid.UUID `gorm:"type:uuid;not null;index" json:"merged_id"`
+	MergedCode string    `gorm:"size:50;not null" json:"merged_code"`
+	MergedName string    `gorm:"size:255;not null" json:"merged_name"`
+	Snapshot   string    `gorm:"type:text;not null" json:"snapshot"`
+	// Records moved to the survivor
+	Transactions int       `gorm:"not null;default:0" json:"transactions"`
+	Charges      int       `gorm:"not null;default:0" json:"charges"` // Follow their book transactions
+	Payments     int       `gorm:"not null;default:0" json:"payments"`
+	Refunds      int       `gorm:"not null;default:0" json:"refunds"`
+	Holds        int       `gorm:"not null;default:0" json:"holds"`
+	Reason       string    `gorm:"size:500;not null" json:"reason"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	User         *User     `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// CustomerMergeCounts are the records moved from one customer to another
+type CustomerMergeCounts struct {
+	Transactions int
+	Charges      int
+	Payments     int
+	Refunds      int
+	Holds        int
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// EligibilityOverride records a loan lent despite failed checkout eligibility checks
+type EligibilityOverride struct {
+	ID                uuid.UUID       `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	BookTransactionID uuid.UUID       `gorm:"type:uuid;not null;index" json:"book_transaction_id"`
+	BookTransaction   BookTransaction `gorm:"foreignKey:BookTransactionID" json:"book_transaction,omitempty"`
+	CustomerID        uuid.UUID       `gorm:"type:uuid;not null;index" json:"customer_id"`
+	Customer          Customer        `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+	// Comma separated codes of the checks that failed
+	ReasonCodes string    `gorm:"size:255;not null" json:"reason_codes"`
+	Reason      string    `gorm:"size:500;not null" json:"reason"`
+	UserID      uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	User        User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
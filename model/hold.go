@@ -0,0 +1,33 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Hold is a customer's place in the queue for a book. Holds are served first come, first served:
+// a returned copy is set aside for the oldest pending hold, which then has until the pickup
+// deadline to borrow it.
+type Hold struct {
+	ID             uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	BookID         uuid.UUID  `gorm:"type:uuid;not null;index" json:"book_id"`
+	Book           Book       `gorm:"foreignKey:BookID" json:"book,omitempty"`
+	CustomerID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"customer_id"`
+	Customer       Customer   `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+	Status         string     `gorm:"size:50;not null;index" json:"status"` // Pending, Ready, Fulfilled, Cancelled, Expired
+	StockCode      *string    `gorm:"size:50;index" json:"stock_code"`
+	ReadyAt        *time.Time `json:"ready_at"`
+	PickupDeadline *time.Time `json:"pickup_deadline"`
+	ClosedAt       *time.Time `json:"closed_at"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+const (
+	StatusHoldPending   = "Pending"
+	StatusHoldReady     = "Ready"
+	StatusHoldFulfilled = "Fulfilled"
+	StatusHoldCancelled = "Cancelled"
+	StatusHoldExpired   = "Expired"
+)
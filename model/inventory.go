@@ -0,0 +1,35 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// InventorySession is a stock-taking round in which staff scan the copies found on the shelves
+type InventorySession struct {
+	ID        uuid.UUID       `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	Name      string          `gorm:"size:255;not null" json:"name"`
+	Notes     string          `gorm:"type:text" json:"notes"`
+	Status    string          `gorm:"size:50;not null" json:"status"` // Open, Closed
+	BranchID  *uuid.UUID      `gorm:"type:uuid" json:"branch_id"`     // Only copies located at this branch are expected, nil for all
+	UserID    uuid.UUID       `gorm:"not null" json:"user_id"`
+	User      User            `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	StartedAt time.Time       `json:"started_at"`
+	ClosedAt  *time.Time      `json:"closed_at"`
+	Scans     []InventoryScan `gorm:"foreignKey:SessionID" json:"scans,omitempty"`
+}
+
+// InventoryScan is a single stock code scanned during an inventory session
+type InventoryScan struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	SessionID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_inventory_scans_session_code" json:"session_id"`
+	Code      string    `gorm:"size:50;not null;uniqueIndex:idx_inventory_scans_session_code" json:"code"`
+	UserID    uuid.UUID `gorm:"not null" json:"user_id"`
+	ScannedAt time.Time `json:"scanned_at"`
+}
+
+const (
+	StatusInventoryOpen   = "Open"
+	StatusInventoryClosed = "Closed"
+)
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// LoanPolicy sets loan terms for a customer category and material type.
+// An empty category or material type matches any value.
+type LoanPolicy struct {
+	ID               uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	Name             string    `gorm:"size:255;not null" json:"name"`
+	CustomerCategory string    `gorm:"size:50;not null;default:'';index:idx_loan_policies_scope" json:"customer_category"`
+	MaterialType     string    `gorm:"size:50;not null;default:'';index:idx_loan_policies_scope" json:"material_type"`
+	LoanPeriodDays   int       `gorm:"not null" json:"loan_period_days"`
+	MaxLoans         int       `gorm:"not null;default:0" json:"max_loans"` // 0 means unlimited
+	DailyLateFee     float64   `gorm:"not null;default:0" json:"daily_late_fee"`
+	MaxRenewals      int       `gorm:"not null;default:0" json:"max_renewals"`
+	// Late fee terms: the first GracePeriodDays late days are free, the fee of a loan never exceeds
+	// MaxLateFee (0 means no cap) and SkipClosedDays leaves days the library is closed uncharged
+	GracePeriodDays int            `gorm:"not null;default:0" json:"grace_period_days"`
+	MaxLateFee      float64        `gorm:"not null;default:0" json:"max_late_fee"`
+	SkipClosedDays  bool           `gorm:"not null;default:false" json:"skip_closed_days"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LoanRenewal records an extension of a loan's due date
+type LoanRenewal struct {
+	ID                uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	BookTransactionID uuid.UUID  `gorm:"type:uuid;not null;index" json:"book_transaction_id"`
+	PreviousDueDate   time.Time  `gorm:"not null" json:"previous_due_date"`
+	NewDueDate        time.Time  `gorm:"not null" json:"new_due_date"`
+	UserID            *uuid.UUID `gorm:"type:uuid" json:"user_id"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Notification is an entry in the delivery log: one notice to a customer over one channel.
+// Reference identifies what the notice is about, so each event is notified once per channel.
+type Notification struct {
+	ID                uuid.UUID  `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	CustomerID        uuid.UUID  `gorm:"type:uuid;not null;index" json:"customer_id"`
+	Customer          *Customer  `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+	Type              string     `gorm:"size:50;not null;index" json:"type"` // DueReminder, OverdueNotice, HoldReady
+	Channel           string     `gorm:"size:20;not null;default:''" json:"channel"`
+	Recipient         string     `gorm:"size:255;not null;default:''" json:"recipient"`
+	Reference         string     `gorm:"size:255;not null;index" json:"reference"`
+	BookTransactionID *uuid.UUID `gorm:"type:uuid;index" json:"book_transaction_id"`
+	HoldID            *uuid.UUID `gorm:"type:uuid;index" json:"hold_id"`
+	Subject           string     `gorm:"size:255;not null;default:''" json:"subject"`
+	Body              string     `gorm:"type:text;not null;default:''" json:"body"`
+	Status            string     `gorm:"size:20;not null" json:"status"` // Sent, Failed, Skipped
+	Error             string     `gorm:"type:text;not null;default:''" json:"error"`
+	CreatedAt         time.Time  `json:"created_at"`
+}
+
+// NotificationPreference is a channel a customer wants notices on, with the address to use
+type NotificationPreference struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	CustomerID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_notification_preference" json:"customer_id"`
+	Channel    string    `gorm:"size:20;not null;uniqueIndex:idx_notification_preference" json:"channel"`
+	Address    string    `gorm:"size:255;not null;default:''" json:"address"` // Email address, phone number or webhook URL
+	Enabled    bool      `gorm:"not null;default:true" json:"enabled"`
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// NotificationTemplate replaces the built-in wording of a notice on one channel.
+// Subject and body are Go text templates.
+type NotificationTemplate struct {
+	ID        uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	Type      string    `gorm:"size:50;not null;uniqueIndex:idx_notification_template" json:"type"`
+	Channel   string    `gorm:"size:20;not null;uniqueIndex:idx_notification_template" json:"channel"`
+	Subject   string    `gorm:"size:255;not null;default:''" json:"subject"`
+	Body      string    `gorm:"type:text;not null" json:"body"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+const (
+	NotificationTypeDueReminder   = "DueReminder"
+	NotificationTypeOverdueNotice = "OverdueNotice"
+	NotificationTypeHoldReady     = "HoldReady"
+)
+
+const (
+	NotificationChannelEmail   = "Email"
+	NotificationChannelSMS     = "SMS"
+	NotificationChannelWebhook = "Webhook"
+)
+
+const (
+	NotificationStatusSent    = "Sent"
+	NotificationStatusFailed  = "Failed"
+	NotificationStatusSkipped = "Skipped"
+)
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Payment is money received against a charge. A charge may be paid in several parts.
+type Payment struct {
+	ID            uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	ChargeID      uuid.UUID `gorm:"type:uuid;not null;index" json:"charge_id"`
+	Charge        Charge    `gorm:"foreignKey:ChargeID" json:"charge,omitempty"`
+	CustomerID    uuid.UUID `gorm:"type:uuid;not null;index" json:"customer_id"`
+	Customer      Customer  `gorm:"foreignKey:CustomerID" json:"customer,omitempty"`
+	Amount        float64   `gorm:"not null" json:"amount"`
+	Method        string    `gorm:"size:50;not null" json:"method"` // Cash, Card, Transfer, Other
+	ReceiptNumber string    `gorm:"size:50;not null;uniqueIndex" json:"receipt_number"`
+	Note          string    `gorm:"size:500" json:"note"`
+	UserID        uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	User          User      `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+const (
+	PaymentMethodCash     = "Cash"
+	PaymentMethodCard     = "Card"
+	PaymentMethodTransfer = "Transfer"
+	PaymentMethodOther    = "Other"
+)
+
+// ChargeAdjustment lowers what is owed on a charge without a payment: a waiver forgives the
+// whole outstanding balance, a discount a given amount. Both need a reason and an approver.
+// A reversal credits back the replacement cost of a lost copy that was returned after all.
+type ChargeAdjustment struct {
+	ID           uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	ChargeID     uuid.UUID `gorm:"type:uuid;not null;index" json:"charge_id"`
+	Type         string    `gorm:"size:50;not null" json:"type"` // Waiver, Discount, Reversal
+	Amount       float64   `gorm:"not null" json:"amount"`
+	Reason       string    `gorm:"size:500;not null" json:"reason"`
+	ApprovedByID uuid.UUID `gorm:"type:uuid;not null" json:"approved_by_id"`
+	ApprovedBy   User      `gorm:"foreignKey:ApprovedByID" json:"approved_by,omitempty"`
+	UserID       uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+const (
+	AdjustmentWaiver   = "Waiver"
+	AdjustmentDiscount = "Discount"
+	AdjustmentReversal = "Reversal"
+)
+
+// Refund is money paid back to a customer who paid more on a charge than they owe after a reversal
+type Refund struct {
+	ID         uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4()" json:"id"`
+	ChargeID   uuid.UUID `gorm:"type:uuid;not null;index" json:"charge_id"`
+	CustomerID uuid.UUID `gorm:"type:uuid;not null;index" json:"customer_id"`
+	Amount     float64   `gorm:"not null" json:"amount"`
+	Method     string    `gorm:"size:50;not null" json:"method"` // Cash, Card, Transfer, Other
+	Reason     string    `gorm:"size:500;not null" json:"reason"`
+	UserID     uuid.UUID `gorm:"type:uuid;not null" json:"user_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -7,11 +7,13 @@ import (
 )
 
 type User struct {
-	ID        uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4()" db:"id"`
-	Name      string       `gorm:"size:100;not null" db:"name"`
-	Email     string       `gorm:"size:100;uniqueIndex;not null" db:"email"`
-	Password  string       `gorm:"size:255;not null" db:"password"`
-	Role      string       `gorm:"size:50;not null;default:user" db:"role"`
-	CreatedAt sql.NullTime `gorm:"autoCreateTime" db:"created_at"`
-	UpdatedAt sql.NullTime `gorm:"autoUpdateTime" db:"updated_at"`
+	ID         uuid.UUID    `gorm:"type:uuid;default:uuid_generate_v4()" db:"id"`
+	Name       string       `gorm:"size:100;not null" db:"name"`
+	Email      string       `gorm:"size:100;uniqueIndex;not null" db:"email"`
+	Password   string       `gorm:"size:255;not null" db:"password"`
+	Role       string       `gorm:"size:50;not null;default:user" db:"role"`
+	BranchID   *uuid.UUID   `gorm:"type:uuid" db:"branch_id"`               // Staff bound to a branch, nil for all branches
+	CustomerID *uuid.UUID   `gorm:"type:uuid;uniqueIndex" db:"customer_id"` // Member account linked to its customer record, nil for staff
+	CreatedAt  sql.NullTime `gorm:"autoCreateTime" db:"created_at"`
+	UpdatedAt  sql.NullTime `gorm:"autoUpdateTime" db:"updated_at"`
 }
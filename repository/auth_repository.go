@@ -4,12 +4,17 @@ import (
 	"errors"
 	"go-gin-simple-api/model"
 
+	"github.com/google/uuid"
 	"gorm.io/gorm"
 )
 
 type AuthRepository interface {
 	FindByEmail(email string) (*model.User, error)
+	FindByID(id uuid.UUID) (*model.User, error)
+	FindByCustomerID(customerID uuid.UUID) (*model.User, error)
+	FindDependantIDs(customerID uuid.UUID) ([]uuid.UUID, error)
 	Create(user *model.User) error
+	Update(user *model.User) error
 }
 
 type authRepository struct {
@@ -31,6 +36,42 @@ func (r *authRepository) FindByEmail(email string) (*model.User, error) {
 	return &user, nil
 }
 
+func (r *authRepository) FindByID(id uuid.UUID) (*model.User, error) {
+	var user model.User
+	if err := r.db.First(&user, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindByCustomerID returns the member account linked to a customer record
+func (r *authRepository) FindByCustomerID(customerID uuid.UUID) (*model.User, error) {
+	var user model.User
+	if err := r.db.First(&user, "customer_id = ?", customerID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+	return &user, nil
+}
+
+// FindDependantIDs returns the customers a member's linked customer is the guardian of
+func (r *authRepository) FindDependantIDs(customerID uuid.UUID) ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := r.db.Model(&model.Customer{}).Where("guardian_id = ?", customerID).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
 func (r *authRepository) Create(user *model.User) error {
 	return r.db.Create(user).Error
 }
+
+func (r *authRepository) Update(user *model.User) error {
+	return r.db.Save(user).Error
+}
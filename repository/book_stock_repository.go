@@ -5,20 +5,36 @@ import (
 	"go-gin-simple-api/lib"
 	"go-gin-simple-api/model"
 	"strings"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookStockRepository interface {
 	FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.BookStock, int64, error)
 	FindByCode(code string) (*model.BookStock, error)
+	FindByCodeForUpdate(code string) (*model.BookStock, error)
+	FindByCodes(codes []string) ([]model.BookStock, error)
 	FindByBookID(bookID uuid.UUID) ([]model.BookStock, error)
-	FindAvailableByBookID(bookID uuid.UUID) ([]model.BookStock, error)
+	FindAvailableByBookID(bookID uuid.UUID, branchID *uuid.UUID) ([]model.BookStock, error)
+	CountByBookIDPerBranch(bookID uuid.UUID) ([]BranchStockCount, error)
 	Create(bookStock *model.BookStock) error
-	Update(bookStock *model.BookStock) error
+	CreateBatch(bookStocks []model.BookStock) error
+	UpdateFields(code string, fields map[string]interface{}) error
 	Delete(code string) error
 	UpdateStatus(code, status string) error
+	UpdateCurrentBranch(code string, branchID uuid.UUID) error
+	CreateStatusHistory(history *model.BookStockStatusHistory) error
+	FindStatusHistoryByCode(code string) ([]model.BookStockStatusHistory, error)
+}
+
+// BranchStockCount holds the number of copies of a book located at a branch
+type BranchStockCount struct {
+	CurrentBranchID *uuid.UUID
+	Available       int64
+	Total           int64
 }
 
 type bookStockRepository struct {
@@ -33,7 +49,7 @@ func (r *bookStockRepository) FindAll(page, perPage int, search string, filter l
 	var bookStocks []model.BookStock
 	var total int64
 
-	query := r.db.Model(&model.BookStock{}).Preload("Book.Cover")
+	query := r.db.Model(&model.BookStock{}).Preload("Book.Cover").Preload("CurrentBranch")
 
 	// Join with Book to enable searching by book title
 	query = query.Joins("LEFT JOIN books ON book_stocks.book_id = books.id")
@@ -101,7 +117,24 @@ func (r *bookStockRepository) FindAll(page, perPage int, search string, filter l
 
 func (r *bookStockRepository) FindByCode(code string) (*model.BookStock, error) {
 	var bookStock model.BookStock
-	if err := r.db.Preload("Book").Preload("Book.Cover").First(&bookStock, "code = ?", code).Error; err != nil {
+	if err := r.db.Preload("Book").Preload("Book.Cover").Preload("CurrentBranch").First(&bookStock, "code = ?", code).Error; err != nil {
+		return nil, err
+	}
+	return &bookStock, nil
+}
+
+func (r *bookStockRepository) FindByCodes(codes []string) ([]model.BookStock, error) {
+	var bookStocks []model.BookStock
+	if err := r.db.Preload("Book").Where("code IN ?", codes).Find(&bookStocks).Error; err != nil {
+		return nil, err
+	}
+	return bookStocks, nil
+}
+
+// FindByCodeForUpdate loads a book stock and locks its row until the surrounding database transaction ends
+func (r *bookStockRepository) FindByCodeForUpdate(code string) (*model.BookStock, error) {
+	var bookStock model.BookStock
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&bookStock, "code = ?", code).Error; err != nil {
 		return nil, err
 	}
 	return &bookStock, nil
@@ -109,26 +142,47 @@ func (r *bookStockRepository) FindByCode(code string) (*model.BookStock, error)
 
 func (r *bookStockRepository) FindByBookID(bookID uuid.UUID) ([]model.BookStock, error) {
 	var bookStocks []model.BookStock
-	if err := r.db.Preload("Book").Preload("Book.Cover").Where("book_id = ?", bookID).Find(&bookStocks).Error; err != nil {
+	if err := r.db.Preload("Book").Preload("Book.Cover").Preload("CurrentBranch").Where("book_id = ?", bookID).Find(&bookStocks).Error; err != nil {
 		return nil, err
 	}
 	return bookStocks, nil
 }
 
-func (r *bookStockRepository) FindAvailableByBookID(bookID uuid.UUID) ([]model.BookStock, error) {
+func (r *bookStockRepository) FindAvailableByBookID(bookID uuid.UUID, branchID *uuid.UUID) ([]model.BookStock, error) {
 	var bookStocks []model.BookStock
-	if err := r.db.Preload("Book").Preload("Book.Cover").Where("book_id = ? AND status = ?", bookID, model.StatusAvailable).Find(&bookStocks).Error; err != nil {
+	query := r.db.Preload("Book").Preload("Book.Cover").Preload("CurrentBranch").Where("book_id = ? AND status = ?", bookID, model.StatusAvailable)
+	if branchID != nil {
+		query = query.Where("current_branch_id = ?", *branchID)
+	}
+	if err := query.Find(&bookStocks).Error; err != nil {
 		return nil, err
 	}
 	return bookStocks, nil
 }
 
+func (r *bookStockRepository) CountByBookIDPerBranch(bookID uuid.UUID) ([]BranchStockCount, error) {
+	var counts []BranchStockCount
+	if err := r.db.Model(&model.BookStock{}).
+		Select("current_branch_id, COUNT(*) FILTER (WHERE status = ?) AS available, COUNT(*) AS total", model.StatusAvailable).
+		Where("book_id = ?", bookID).
+		Group("current_branch_id").
+		Scan(&counts).Error; err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
 func (r *bookStockRepository) Create(bookStock *model.BookStock) error {
 	return r.db.Create(bookStock).Error
 }
 
-func (r *bookStockRepository) Update(bookStock *model.BookStock) error {
-	return r.db.Save(bookStock).Error
+func (r *bookStockRepository) CreateBatch(bookStocks []model.BookStock) error {
+	return r.db.Create(&bookStocks).Error
+}
+
+// UpdateFields writes only the given columns, so concurrent status and location changes are kept
+func (r *bookStockRepository) UpdateFields(code string, fields map[string]interface{}) error {
+	return r.db.Model(&model.BookStock{}).Where("code = ?", code).Updates(fields).Error
 }
 
 func (r *bookStockRepository) Delete(code string) error {
@@ -142,3 +196,20 @@ func (r *bookStockRepository) UpdateStatus(code, status string) error {
 
 	return r.db.Model(&model.BookStock{}).Where("code = ?", code).Updates(updates).Error
 }
+
+func (r *bookStockRepository) UpdateCurrentBranch(code string, branchID uuid.UUID) error {
+	return r.db.Model(&model.BookStock{}).Where("code = ?", code).Update("current_branch_id", branchID).Error
+}
+
+func (r *bookStockRepository) CreateStatusHistory(history *model.BookStockStatusHistory) error {
+	history.CreatedAt = time.Now()
+	return r.db.Create(history).Error
+}
+
+func (r *bookStockRepository) FindStatusHistoryByCode(code string) ([]model.BookStockStatusHistory, error) {
+	var histories []model.BookStockStatusHistory
+	if err := r.db.Where("stock_code = ?", code).Order("created_at DESC").Find(&histories).Error; err != nil {
+		return nil, err
+	}
+	return histories, nil
+}
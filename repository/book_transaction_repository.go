@@ -9,21 +9,34 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type BookTransactionRepository interface {
 	FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.BookTransaction, int64, error)
 	FindByID(id uuid.UUID) (*model.BookTransaction, error)
+	FindByIDForUpdate(id uuid.UUID) (*model.BookTransaction, error)
 	FindByCustomerID(customerID uuid.UUID) ([]model.BookTransaction, error)
+	FindHistoryByCustomerID(customerID uuid.UUID, from, to time.Time, page, perPage int) ([]model.BookTransaction, int64, error)
 	FindByBookID(bookID uuid.UUID) ([]model.BookTransaction, error)
 	FindByStockCode(stockCode string) ([]model.BookTransaction, error)
 	FindActiveByStockCode(stockCode string) (*model.BookTransaction, error)
+	CountOverdueByCustomerID(customerID uuid.UUID, now time.Time) (int64, error)
+	CreateEligibilityOverride(override *model.EligibilityOverride) error
+	FindEligibilityOverridesByCustomerID(customerID uuid.UUID) ([]model.EligibilityOverride, error)
+	CountActiveByCustomerID(customerID uuid.UUID, materialType string) (int64, error)
 	Create(transaction *model.BookTransaction) error
 	Update(transaction *model.BookTransaction) error
 	Delete(id uuid.UUID) error
 	UpdateStatus(id uuid.UUID, status string) error
 	ReturnBook(id uuid.UUID, returnAt time.Time) error
 	FindOverdueTransactions() ([]model.BookTransaction, error)
+	FindDueBetween(from, to time.Time) ([]model.BookTransaction, error)
+	MarkOverdue(now time.Time) ([]uuid.UUID, error)
+	CreateEvents(events []model.BookTransactionEvent) error
+	FindEventsByTransactionID(transactionID uuid.UUID) ([]model.BookTransactionEvent, error)
+	CreateRenewal(renewal *model.LoanRenewal) error
+	FindRenewalsByTransactionID(transactionID uuid.UUID) ([]model.LoanRenewal, error)
 }
 
 type bookTransactionRepository struct {
@@ -96,7 +109,7 @@ func (r *bookTransactionRepository) FindAll(page, perPage int, search string, fi
 	}
 
 	// Preload relationships
-	query = query.Preload("Book").Preload("Book.Cover").Preload("BookStock").Preload("Customer").Preload("Charges")
+	query = query.Preload("Book").Preload("Book.Cover").Preload("BookStock").Preload("Customer").Preload("Charges").Preload("Charges.Payments").Preload("Charges.Adjustments").Preload("Charges.Refunds")
 
 	// Execute query
 	if err := query.Find(&transactions).Error; err != nil {
@@ -108,7 +121,16 @@ func (r *bookTransactionRepository) FindAll(page, perPage int, search string, fi
 
 func (r *bookTransactionRepository) FindByID(id uuid.UUID) (*model.BookTransaction, error) {
 	var transaction model.BookTransaction
-	if err := r.db.Preload("Book").Preload("Book.Cover").Preload("BookStock").Preload("Customer").Preload("Charges").First(&transaction, "id = ?", id).Error; err != nil {
+	if err := r.db.Preload("Book").Preload("Book.Cover").Preload("BookStock").Preload("Customer").Preload("Charges").Preload("Charges.Payments").Preload("Charges.Adjustments").Preload("Charges.Refunds").Preload("LoanPolicy").First(&transaction, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &transaction, nil
+}
+
+// FindByIDForUpdate loads a transaction and locks its row until the surrounding database transaction ends
+func (r *bookTransactionRepository) FindByIDForUpdate(id uuid.UUID) (*model.BookTransaction, error) {
+	var transaction model.BookTransaction
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&transaction, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &transaction, nil
@@ -122,6 +144,34 @@ func (r *bookTransactionRepository) FindByCustomerID(customerID uuid.UUID) ([]mo
 	return transactions, nil
 }
 
+// FindHistoryByCustomerID returns the loans a customer borrowed in [from, to), newest first, with pagination
+func (r *bookTransactionRepository) FindHistoryByCustomerID(customerID uuid.UUID, from, to time.Time, page, perPage int) ([]model.BookTransaction, int64, error) {
+	var transactions []model.BookTransaction
+	var total int64
+
+	query := r.db.Model(&model.BookTransaction{}).
+		Where("book_transactions.customer_id = ? AND book_transactions.borrowed_at >= ? AND book_transactions.borrowed_at < ?", customerID, from, to)
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	offset := (page - 1) * perPage
+	if page > 0 && perPage > 0 {
+		query = query.Offset(offset).Limit(perPage)
+	}
+
+	if err := query.Preload("Book").Preload("Book.Cover").Preload("BookStock").
+		Order("book_transactions.borrowed_at DESC").
+		Find(&transactions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return transactions, total, nil
+}
+
 func (r *bookTransactionRepository) FindByBookID(bookID uuid.UUID) ([]model.BookTransaction, error) {
 	var transactions []model.BookTransaction
 	if err := r.db.Preload("Book").Preload("Book.Cover").Preload("BookStock").Preload("Customer").Where("book_id = ?", bookID).Find(&transactions).Error; err != nil {
@@ -146,12 +196,50 @@ func (r *bookTransactionRepository) FindActiveByStockCode(stockCode string) (*mo
 	return &transaction, nil
 }
 
+// CountActiveByCustomerID counts a customer's open loans, limited to one material type unless it is empty
+func (r *bookTransactionRepository) CountActiveByCustomerID(customerID uuid.UUID, materialType string) (int64, error) {
+	var count int64
+	query := r.db.Model(&model.BookTransaction{}).
+		Where("book_transactions.customer_id = ? AND book_transactions.status IN ?", customerID, []string{model.StatusBTBorrowed, model.StatusBTOverdue})
+	if materialType != "" {
+		query = query.Joins("JOIN books ON books.id = book_transactions.book_id").Where("books.material_type = ?", materialType)
+	}
+	if err := query.Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountOverdueByCustomerID counts a customer's open loans past their due date, marked Overdue or not
+func (r *bookTransactionRepository) CountOverdueByCustomerID(customerID uuid.UUID, now time.Time) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.BookTransaction{}).
+		Where("customer_id = ? AND (status = ? OR (status = ? AND due_date < ?))", customerID, model.StatusBTOverdue, model.StatusBTBorrowed, now).
+		Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *bookTransactionRepository) CreateEligibilityOverride(override *model.EligibilityOverride) error {
+	override.CreatedAt = time.Now()
+	return r.db.Omit(clause.Associations).Create(override).Error
+}
+
+func (r *bookTransactionRepository) FindEligibilityOverridesByCustomerID(customerID uuid.UUID) ([]model.EligibilityOverride, error) {
+	var overrides []model.EligibilityOverride
+	if err := r.db.Preload("Customer").Preload("User").Where("customer_id = ?", customerID).Order("created_at DESC").Find(&overrides).Error; err != nil {
+		return nil, err
+	}
+	return overrides, nil
+}
+
 func (r *bookTransactionRepository) Create(transaction *model.BookTransaction) error {
 	return r.db.Create(transaction).Error
 }
 
 func (r *bookTransactionRepository) Update(transaction *model.BookTransaction) error {
-	return r.db.Save(transaction).Error
+	return r.db.Omit(clause.Associations).Save(transaction).Error
 }
 
 func (r *bookTransactionRepository) Delete(id uuid.UUID) error {
@@ -170,15 +258,74 @@ func (r *bookTransactionRepository) ReturnBook(id uuid.UUID, returnAt time.Time)
 	return r.db.Model(&model.BookTransaction{}).Where("id = ?", id).Updates(updates).Error
 }
 
+// FindDueBetween returns the open loans that are not yet overdue and fall due in [from, to)
+func (r *bookTransactionRepository) FindDueBetween(from, to time.Time) ([]model.BookTransaction, error) {
+	var transactions []model.BookTransaction
+
+	if err := r.db.Preload("Book").Preload("Customer").
+		Where("status = ? AND due_date >= ? AND due_date < ?", model.StatusBTBorrowed, from, to).
+		Order("due_date").
+		Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+
+	return transactions, nil
+}
+
 func (r *bookTransactionRepository) FindOverdueTransactions() ([]model.BookTransaction, error) {
 	var transactions []model.BookTransaction
 	now := time.Now()
 
 	if err := r.db.Preload("Book").Preload("Book.Cover").Preload("BookStock").Preload("Customer").
-		Where("status = 'Borrowed' AND due_date < ?", now).
+		Where("status IN ? AND due_date < ?", []string{model.StatusBTBorrowed, model.StatusBTOverdue}, now).
+		Order("due_date").
 		Find(&transactions).Error; err != nil {
 		return nil, err
 	}
 
 	return transactions, nil
 }
+
+// MarkOverdue moves borrowed loans whose due date has passed to Overdue and returns the IDs of the loans it changed
+func (r *bookTransactionRepository) MarkOverdue(now time.Time) ([]uuid.UUID, error) {
+	var transactions []model.BookTransaction
+	if err := r.db.Model(&transactions).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "id"}}}).
+		Where("status = ? AND due_date < ?", model.StatusBTBorrowed, now).
+		Update("status", model.StatusBTOverdue).Error; err != nil {
+		return nil, err
+	}
+
+	ids := make([]uuid.UUID, 0, len(transactions))
+	for _, transaction := range transactions {
+		ids = append(ids, transaction.ID)
+	}
+	return ids, nil
+}
+
+func (r *bookTransactionRepository) CreateEvents(events []model.BookTransactionEvent) error {
+	if len(events) == 0 {
+		return nil
+	}
+	return r.db.Omit(clause.Associations).Create(&events).Error
+}
+
+func (r *bookTransactionRepository) FindEventsByTransactionID(transactionID uuid.UUID) ([]model.BookTransactionEvent, error) {
+	var events []model.BookTransactionEvent
+	if err := r.db.Preload("User").Where("book_transaction_id = ?", transactionID).Order("created_at").Find(&events).Error; err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+func (r *bookTransactionRepository) CreateRenewal(renewal *model.LoanRenewal) error {
+	return r.db.Create(renewal).Error
+}
+
+func (r *bookTransactionRepository) FindRenewalsByTransactionID(transactionID uuid.UUID) ([]model.LoanRenewal, error) {
+	var renewals []model.LoanRenewal
+	if err := r.db.Where("book_transaction_id = ?", transactionID).Order("created_at").Find(&renewals).Error; err != nil {
+		return nil, err
+	}
+	return renewals, nil
+}
@@ -0,0 +1,149 @@
+package repository
+
+import (
+	"fmt"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type BranchRepository interface {
+	FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.Branch, int64, error)
+	FindByID(id uuid.UUID) (*model.Branch, error)
+	FindByIDs(ids []uuid.UUID) ([]model.Branch, error)
+	FindByCode(code string) (*model.Branch, error)
+	Create(branch *model.Branch) error
+	Update(branch *model.Branch) error
+	Delete(id uuid.UUID) error
+	IsBranchUsed(id uuid.UUID) (bool, error)
+}
+
+type branchRepository struct {
+	db *gorm.DB
+}
+
+func NewBranchRepository(db *gorm.DB) BranchRepository {
+	return &branchRepository{db}
+}
+
+func (r *branchRepository) FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.Branch, int64, error) {
+	var branches []model.Branch
+	var total int64
+
+	query := r.db.Model(&model.Branch{})
+
+	// Apply search if provided
+	if search != "" {
+		query = query.Where("code LIKE ? OR name LIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	// Apply filters
+	if len(filter) > 0 {
+		for _, f := range filter {
+			switch f.Operator {
+			case lib.IsEqual:
+				query = query.Where(fmt.Sprintf("%s = ?", f.Field), f.Value)
+			case lib.IsNotEqual:
+				query = query.Where(fmt.Sprintf("%s != ?", f.Field), f.Value)
+			case lib.IsGreaterThan:
+				query = query.Where(fmt.Sprintf("%s > ?", f.Field), f.Value)
+			case lib.IsGreaterEqual:
+				query = query.Where(fmt.Sprintf("%s >= ?", f.Field), f.Value)
+			case lib.IsLessThan:
+				query = query.Where(fmt.Sprintf("%s < ?", f.Field), f.Value)
+			case lib.IsLessEqual:
+				query = query.Where(fmt.Sprintf("%s <= ?", f.Field), f.Value)
+			case lib.IsContain:
+				query = query.Where(fmt.Sprintf("%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsBeginWith:
+				query = query.Where(fmt.Sprintf("%s LIKE ?", f.Field), fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsEndWith:
+				query = query.Where(fmt.Sprintf("%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value))
+			case lib.IsIn:
+				if values, ok := f.Value.([]interface{}); ok {
+					query = query.Where(fmt.Sprintf("%s IN ?", f.Field), values)
+				} else if str, ok := f.Value.(string); ok {
+					values := strings.Split(str, ",")
+					query = query.Where(fmt.Sprintf("%s IN ?", f.Field), values)
+				}
+			}
+		}
+	}
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	offset := (page - 1) * perPage
+	if page > 0 && perPage > 0 {
+		query = query.Offset(offset).Limit(perPage)
+	}
+
+	// Execute query
+	if err := query.Order("code").Find(&branches).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return branches, total, nil
+}
+
+func (r *branchRepository) FindByID(id uuid.UUID) (*model.Branch, error) {
+	var branch model.Branch
+	if err := r.db.First(&branch, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (r *branchRepository) FindByIDs(ids []uuid.UUID) ([]model.Branch, error) {
+	var branches []model.Branch
+	if err := r.db.Where("id IN ?", ids).Find(&branches).Error; err != nil {
+		return nil, err
+	}
+	return branches, nil
+}
+
+func (r *branchRepository) FindByCode(code string) (*model.Branch, error) {
+	var branch model.Branch
+	if err := r.db.First(&branch, "code = ?", code).Error; err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (r *branchRepository) Create(branch *model.Branch) error {
+	branch.CreatedAt = time.Now()
+	branch.UpdatedAt = time.Now()
+	return r.db.Create(branch).Error
+}
+
+func (r *branchRepository) Update(branch *model.Branch) error {
+	branch.UpdatedAt = time.Now()
+	return r.db.Save(branch).Error
+}
+
+func (r *branchRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.Branch{}, "id = ?", id).Error
+}
+
+// IsBranchUsed checks if a branch owns or holds any book stock or has staff assigned
+func (r *branchRepository) IsBranchUsed(id uuid.UUID) (bool, error) {
+	var count int64
+	if err := r.db.Model(&model.BookStock{}).Where("owner_branch_id = ? OR current_branch_id = ?", id, id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	if count > 0 {
+		return true, nil
+	}
+
+	if err := r.db.Model(&model.User{}).Where("branch_id = ?", id).Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
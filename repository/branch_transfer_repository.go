@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"fmt"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type BranchTransferRepository interface {
+	FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.BranchTransfer, int64, error)
+	FindByID(id uuid.UUID) (*model.BranchTransfer, error)
+	FindByIDForUpdate(id uuid.UUID) (*model.BranchTransfer, error)
+	FindOpenByStockCode(stockCode string) (*model.BranchTransfer, error)
+	Create(transfer *model.BranchTransfer) error
+	Update(transfer *model.BranchTransfer) error
+}
+
+type branchTransferRepository struct {
+	db *gorm.DB
+}
+
+func NewBranchTransferRepository(db *gorm.DB) BranchTransferRepository {
+	return &branchTransferRepository{db}
+}
+
+func (r *branchTransferRepository) FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.BranchTransfer, int64, error) {
+	var transfers []model.BranchTransfer
+	var total int64
+
+	query := r.db.Model(&model.BranchTransfer{})
+
+	// Apply search if provided
+	if search != "" {
+		query = query.Where("branch_transfers.stock_code LIKE ? OR branch_transfers.status LIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	// Apply filters
+	if len(filter) > 0 {
+		for _, f := range filter {
+			switch f.Operator {
+			case lib.IsEqual:
+				query = query.Where(fmt.Sprintf("branch_transfers.%s = ?", f.Field), f.Value)
+			case lib.IsNotEqual:
+				query = query.Where(fmt.Sprintf("branch_transfers.%s != ?", f.Field), f.Value)
+			case lib.IsGreaterThan:
+				query = query.Where(fmt.Sprintf("branch_transfers.%s > ?", f.Field), f.Value)
+			case lib.IsGreaterEqual:
+				query = query.Where(fmt.Sprintf("branch_transfers.%s >= ?", f.Field), f.Value)
+			case lib.IsLessThan:
+				query = query.Where(fmt.Sprintf("branch_transfers.%s < ?", f.Field), f.Value)
+			case lib.IsLessEqual:
+				query = query.Where(fmt.Sprintf("branch_transfers.%s <= ?", f.Field), f.Value)
+			case lib.IsContain:
+				query = query.Where(fmt.Sprintf("branch_transfers.%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsBeginWith:
+				query = query.Where(fmt.Sprintf("branch_transfers.%s LIKE ?", f.Field), fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsEndWith:
+				query = query.Where(fmt.Sprintf("branch_transfers.%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value))
+			case lib.IsIn:
+				if values, ok := f.Value.([]interface{}); ok {
+					query = query.Where(fmt.Sprintf("branch_transfers.%s IN ?", f.Field), values)
+				} else if str, ok := f.Value.(string); ok {
+					values := strings.Split(str, ",")
+					query = query.Where(fmt.Sprintf("branch_transfers.%s IN ?", f.Field), values)
+				}
+			}
+		}
+	}
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	offset := (page - 1) * perPage
+	if page > 0 && perPage > 0 {
+		query = query.Offset(offset).Limit(perPage)
+	}
+
+	// Preload relationships
+	query = query.Preload("BookStock.Book").Preload("FromBranch").Preload("ToBranch")
+
+	// Execute query
+	if err := query.Order("requested_at DESC").Find(&transfers).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return transfers, total, nil
+}
+
+func (r *branchTransferRepository) FindByID(id uuid.UUID) (*model.BranchTransfer, error) {
+	var transfer model.BranchTransfer
+	if err := r.db.Preload("BookStock.Book").Preload("FromBranch").Preload("ToBranch").First(&transfer, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// FindByIDForUpdate loads a transfer and locks its row until the surrounding database transaction ends
+func (r *branchTransferRepository) FindByIDForUpdate(id uuid.UUID) (*model.BranchTransfer, error) {
+	var transfer model.BranchTransfer
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Preload("FromBranch").Preload("ToBranch").First(&transfer, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+// FindOpenByStockCode returns the transfer of a copy that has been requested or shipped but not received
+func (r *branchTransferRepository) FindOpenByStockCode(stockCode string) (*model.BranchTransfer, error) {
+	var transfer model.BranchTransfer
+	if err := r.db.Where("stock_code = ? AND status IN ?", stockCode, []string{model.StatusTransferRequested, model.StatusTransferInTransit}).First(&transfer).Error; err != nil {
+		return nil, err
+	}
+	return &transfer, nil
+}
+
+func (r *branchTransferRepository) Create(transfer *model.BranchTransfer) error {
+	return r.db.Create(transfer).Error
+}
+
+func (r *branchTransferRepository) Update(transfer *model.BranchTransfer) error {
+	return r.db.Omit("BookStock", "FromBranch", "ToBranch").Save(transfer).Error
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"go-gin-simple-api/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CalendarRepository interface {
+	FindOpeningHours(branchID *uuid.UUID) ([]model.OpeningHours, error)
+	ReplaceOpeningHours(branchID *uuid.UUID, hours []model.OpeningHours) error
+	FindClosuresBetween(branchID *uuid.UUID, from, to string) ([]model.Closure, error)
+	FindClosureByID(id uuid.UUID) (*model.Closure, error)
+	CreateClosure(closure *model.Closure) error
+	DeleteClosure(id uuid.UUID) error
+}
+
+type calendarRepository struct {
+	db *gorm.DB
+}
+
+func NewCalendarRepository(db *gorm.DB) CalendarRepository {
+	return &calendarRepository{db}
+}
+
+// FindOpeningHours returns the library-wide opening hours together with those of the branch, if any
+func (r *calendarRepository) FindOpeningHours(branchID *uuid.UUID) ([]model.OpeningHours, error) {
+	var hours []model.OpeningHours
+	query := r.db.Where("branch_id IS NULL")
+	if branchID != nil {
+		query = r.db.Where("branch_id IS NULL OR branch_id = ?", *branchID)
+	}
+	if err := query.Order("weekday").Find(&hours).Error; err != nil {
+		return nil, err
+	}
+	return hours, nil
+}
+
+// ReplaceOpeningHours swaps the opening hours of a branch, or the library-wide ones, for the given ones
+func (r *calendarRepository) ReplaceOpeningHours(branchID *uuid.UUID, hours []model.OpeningHours) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		query := tx.Where("branch_id IS NULL")
+		if branchID != nil {
+			query = tx.Where("branch_id = ?", *branchID)
+		}
+		if err := query.Delete(&model.OpeningHours{}).Error; err != nil {
+			return err
+		}
+		if len(hours) == 0 {
+			return nil
+		}
+		return tx.Create(&hours).Error
+	})
+}
+
+// FindClosuresBetween returns the closures overlapping [from, to] that apply to the branch,
+// library-wide ones included. Without a branch only library-wide closures are returned.
+func (r *calendarRepository) FindClosuresBetween(branchID *uuid.UUID, from, to string) ([]model.Closure, error) {
+	var closures []model.Closure
+	query := r.db.Preload("Branch").Where("start_date <= ? AND end_date >= ?", to, from)
+	if branchID != nil {
+		query = query.Where("branch_id IS NULL OR branch_id = ?", *branchID)
+	} else {
+		query = query.Where("branch_id IS NULL")
+	}
+	if err := query.Order("start_date").Find(&closures).Error; err != nil {
+		return nil, err
+	}
+	return closures, nil
+}
+
+func (r *calendarRepository) FindClosureByID(id uuid.UUID) (*model.Closure, error) {
+	var closure model.Closure
+	if err := r.db.Preload("Branch").First(&closure, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &closure, nil
+}
+
+func (r *calendarRepository) CreateClosure(closure *model.Closure) error {
+	return r.db.Create(closure).Error
+}
+
+func (r *calendarRepository) DeleteClosure(id uuid.UUID) error {
+	return r.db.Delete(&model.Closure{}, "id = ?", id).Error
+}
@@ -9,6 +9,7 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type ChargeRepository interface {
@@ -16,6 +17,8 @@ type ChargeRepository interface {
 	FindByID(id uuid.UUID) (*model.Charge, error)
 	FindByBookTransactionID(bookTransactionID uuid.UUID) ([]model.Charge, error)
 	FindByUserID(userID uuid.UUID) ([]model.Charge, error)
+	FindByCustomerID(customerID uuid.UUID) ([]model.Charge, error)
+	FindByIDForUpdate(id uuid.UUID) (*model.Charge, error)
 	Create(charge *model.Charge) error
 	Update(charge *model.Charge) error
 	Delete(id uuid.UUID) error
@@ -90,7 +93,7 @@ func (r *chargeRepository) FindAll(page, perPage int, search string, filter lib.
 	}
 
 	// Preload relationships
-	query = query.Preload("BookTransaction.Book").Preload("BookTransaction.Customer").Preload("User")
+	query = query.Preload("BookTransaction.Book").Preload("BookTransaction.Customer").Preload("User").Preload("Payments").Preload("Adjustments").Preload("Refunds")
 
 	// Execute query
 	if err := query.Find(&charges).Error; err != nil {
@@ -102,7 +105,7 @@ func (r *chargeRepository) FindAll(page, perPage int, search string, filter lib.
 
 func (r *chargeRepository) FindByID(id uuid.UUID) (*model.Charge, error) {
 	var charge model.Charge
-	if err := r.db.Preload("BookTransaction").Preload("BookTransaction.Book").Preload("BookTransaction.Book.Cover").Preload("BookTransaction.Customer").Preload("User").First(&charge, "id = ?", id).Error; err != nil {
+	if err := r.db.Preload("BookTransaction").Preload("BookTransaction.Book").Preload("BookTransaction.Book.Cover").Preload("BookTransaction.Customer").Preload("User").Preload("Payments").Preload("Adjustments").Preload("Refunds").First(&charge, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &charge, nil
@@ -110,7 +113,7 @@ func (r *chargeRepository) FindByID(id uuid.UUID) (*model.Charge, error) {
 
 func (r *chargeRepository) FindByBookTransactionID(bookTransactionID uuid.UUID) ([]model.Charge, error) {
 	var charges []model.Charge
-	if err := r.db.Preload("BookTransaction.Book").Preload("BookTransaction.Book.Cover").Preload("BookTransaction.Customer").Preload("User").Where("book_transaction_id = ?", bookTransactionID).Find(&charges).Error; err != nil {
+	if err := r.db.Preload("BookTransaction.Book").Preload("BookTransaction.Book.Cover").Preload("BookTransaction.Customer").Preload("User").Preload("Payments").Preload("Adjustments").Preload("Refunds").Where("book_transaction_id = ?", bookTransactionID).Find(&charges).Error; err != nil {
 		return nil, err
 	}
 	return charges, nil
@@ -118,19 +121,51 @@ func (r *chargeRepository) FindByBookTransactionID(bookTransactionID uuid.UUID)
 
 func (r *chargeRepository) FindByUserID(userID uuid.UUID) ([]model.Charge, error) {
 	var charges []model.Charge
-	if err := r.db.Preload("BookTransaction.Book").Preload("BookTransaction.Book.Cover").Preload("BookTransaction.Customer").Preload("User").Where("user_id = ?", userID).Find(&charges).Error; err != nil {
+	if err := r.db.Preload("BookTransaction.Book").Preload("BookTransaction.Book.Cover").Preload("BookTransaction.Customer").Preload("User").Preload("Payments").Preload("Adjustments").Preload("Refunds").Where("user_id = ?", userID).Find(&charges).Error; err != nil {
 		return nil, err
 	}
 	return charges, nil
 }
 
+// FindByCustomerID returns every charge on the loans of a customer, oldest first
+func (r *chargeRepository) FindByCustomerID(customerID uuid.UUID) ([]model.Charge, error) {
+	var charges []model.Charge
+	if err := r.db.Preload("BookTransaction.Book").Preload("Payments").Preload("Adjustments").Preload("Refunds").
+		Joins("JOIN book_transactions ON book_transactions.id = charges.book_transaction_id").
+		Where("book_transactions.customer_id = ?", customerID).
+		Order("charges.created_at").
+		Find(&charges).Error; err != nil {
+		return nil, err
+	}
+	return charges, nil
+}
+
+// FindByIDForUpdate loads a charge with its ledger and locks its row until the surrounding database transaction ends
+func (r *chargeRepository) FindByIDForUpdate(id uuid.UUID) (*model.Charge, error) {
+	var charge model.Charge
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&charge, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	// Load the ledger once the row is locked, so it cannot change underneath
+	if err := r.db.Where("charge_id = ?", id).Find(&charge.Payments).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("charge_id = ?", id).Find(&charge.Adjustments).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("charge_id = ?", id).Find(&charge.Refunds).Error; err != nil {
+		return nil, err
+	}
+	return &charge, nil
+}
+
 func (r *chargeRepository) Create(charge *model.Charge) error {
 	charge.CreatedAt = time.Now()
 	return r.db.Create(charge).Error
 }
 
 func (r *chargeRepository) Update(charge *model.Charge) error {
-	return r.db.Save(charge).Error
+	return r.db.Omit(clause.Associations).Save(charge).Error
 }
 
 func (r *chargeRepository) Delete(id uuid.UUID) error {
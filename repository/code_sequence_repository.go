@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+type CodeSequenceRepository interface {
+	NextRange(name string, count int) (int64, error)
+}
+
+type codeSequenceRepository struct {
+	db *gorm.DB
+}
+
+func NewCodeSequenceRepository(db *gorm.DB) CodeSequenceRepository {
+	return &codeSequenceRepository{db}
+}
+
+// NextRange reserves count consecutive values of the named sequence and returns the first one.
+// The upsert takes a row lock, so concurrent callers never receive overlapping ranges.
+func (r *codeSequenceRepository) NextRange(name string, count int) (int64, error) {
+	var last int64
+	err := r.db.Raw(`
+		INSERT INTO code_sequences (name, value, updated_at) VALUES (?, ?, NOW())
+		ON CONFLICT (name) DO UPDATE SET value = code_sequences.value + EXCLUDED.value, updated_at = NOW()
+		RETURNING value`, name, count).Scan(&last).Error
+	if err != nil {
+		return 0, err
+	}
+	return last - int64(count) + 1, nil
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"fmt"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CustomerMergeRepository interface {
+	FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.CustomerMerge, int64, error)
+	FindByID(id uuid.UUID) (*model.CustomerMerge, error)
+	Create(merge *model.CustomerMerge) error
+	Reassign(fromID, toID uuid.UUID) (model.CustomerMergeCounts, error)
+}
+
+type customerMergeRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerMergeRepository(db *gorm.DB) CustomerMergeRepository {
+	return &customerMergeRepository{db}
+}
+
+func (r *customerMergeRepository) FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.CustomerMerge, int64, error) {
+	var merges []model.CustomerMerge
+	var total int64
+
+	query := r.db.Model(&model.CustomerMerge{})
+
+	// Apply search if provided
+	if search != "" {
+		query = query.Where("customer_merges.merged_code LIKE ? OR customer_merges.merged_name LIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	// Apply filters
+	if len(filter) > 0 {
+		for _, f := range filter {
+			switch f.Operator {
+			case lib.IsEqual:
+				query = query.Where(fmt.Sprintf("customer_merges.%s = ?", f.Field), f.Value)
+			case lib.IsNotEqual:
+				query = query.Where(fmt.Sprintf("customer_merges.%s != ?", f.Field), f.Value)
+			case lib.IsGreaterThan:
+				query = query.Where(fmt.Sprintf("customer_merges.%s > ?", f.Field), f.Value)
+			case lib.IsGreaterEqual:
+				query = query.Where(fmt.Sprintf("customer_merges.%s >= ?", f.Field), f.Value)
+			case lib.IsLessThan:
+				query = query.Where(fmt.Sprintf("customer_merges.%s < ?", f.Field), f.Value)
+			case lib.IsLessEqual:
+				query = query.Where(fmt.Sprintf("customer_merges.%s <= ?", f.Field), f.Value)
+			case lib.IsContain:
+				query = query.Where(fmt.Sprintf("customer_merges.%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsBeginWith:
+				query = query.Where(fmt.Sprintf("customer_merges.%s LIKE ?", f.Field), fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsEndWith:
+				query = query.Where(fmt.Sprintf("customer_merges.%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value))
+			case lib.IsIn:
+				if values, ok := f.Value.([]interface{}); ok {
+					query = query.Where(fmt.Sprintf("customer_merges.%s IN ?", f.Field), values)
+				} else if str, ok := f.Value.(string); ok {
+					values := strings.Split(str, ",")
+					query = query.Where(fmt.Sprintf("customer_merges.%s IN ?", f.Field), values)
+				}
+			}
+		}
+	}
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	offset := (page - 1) * perPage
+	if page > 0 && perPage > 0 {
+		query = query.Offset(offset).Limit(perPage)
+	}
+
+	// Execute query
+	if err := query.Preload("Survivor").Preload("User").Order("created_at DESC").Find(&merges).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return merges, total, nil
+}
+
+func (r *customerMergeRepository) FindByID(id uuid.UUID) (*model.CustomerMerge, error) {
+	var merge model.CustomerMerge
+	if err := r.db.Preload("Survivor").Preload("User").First(&merge, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &merge, nil
+}
+
+func (r *customerMergeRepository) Create(merge *model.CustomerMerge) error {
+	return r.db.Omit("Survivor", "User").Create(merge).Error
+}
+
+// Reassign moves every record of one customer to another. Pending holds on titles the other
+// customer already waits for are cancelled rather than queued twice, and notification
+// preferences for channels the other customer has set are dropped.
+// Run it inside a unit of work so a merge moves everything or nothing.
+func (r *customerMergeRepository) Reassign(fromID, toID uuid.UUID) (model.CustomerMergeCounts, error) {
+	var counts model.CustomerMergeCounts
+
+	// Charges hang off book transactions, so they move along with them
+	var charges int64
+	if err := r.db.Model(&model.Charge{}).
+		Where("book_transaction_id IN (?)", r.db.Model(&model.BookTransaction{}).Select("id").Where("customer_id = ?", fromID)).
+		Count(&charges).Error; err != nil {
+		return counts, err
+	}
+	counts.Charges = int(charges)
+
+	result := r.db.Model(&model.BookTransaction{}).Where("customer_id = ?", fromID).Update("customer_id", toID)
+	if result.Error != nil {
+		return counts, result.Error
+	}
+	counts.Transactions = int(result.RowsAffected)
+
+	result = r.db.Model(&model.Payment{}).Where("customer_id = ?", fromID).Update("customer_id", toID)
+	if result.Error != nil {
+		return counts, result.Error
+	}
+	counts.Payments = int(result.RowsAffected)
+
+	result = r.db.Model(&model.Refund{}).Where("customer_id = ?", fromID).Update("customer_id", toID)
+	if result.Error != nil {
+		return counts, result.Error
+	}
+	counts.Refunds = int(result.RowsAffected)
+
+	if err := r.db.Model(&model.Hold{}).
+		Where("customer_id = ? AND status = ? AND book_id IN (?)", fromID, model.StatusHoldPending,
+			r.db.Model(&model.Hold{}).Select("book_id").Where("customer_id = ? AND status IN ?", toID, []string{model.StatusHoldPending, model.StatusHoldReady})).
+		Updates(map[string]interface{}{"status": model.StatusHoldCancelled, "closed_at": gorm.Expr("NOW()")}).Error; err != nil {
+		return counts, err
+	}
+	result = r.db.Model(&model.Hold{}).Where("customer_id = ?", fromID).Update("customer_id", toID)
+	if result.Error != nil {
+		return counts, result.Error
+	}
+	counts.Holds = int(result.RowsAffected)
+
+	if err := r.db.Model(&model.EligibilityOverride{}).Where("customer_id = ?", fromID).Update("customer_id", toID).Error; err != nil {
+		return counts, err
+	}
+	if err := r.db.Model(&model.Notification{}).Where("customer_id = ?", fromID).Update("customer_id", toID).Error; err != nil {
+		return counts, err
+	}
+
+	if err := r.db.Where("customer_id = ? AND channel IN (?)", fromID,
+		r.db.Model(&model.NotificationPreference{}).Select("channel").Where("customer_id = ?", toID)).
+		Delete(&model.NotificationPreference{}).Error; err != nil {
+		return counts, err
+	}
+	if err := r.db.Model(&model.NotificationPreference{}).Where("customer_id = ?", fromID).Update("customer_id", toID).Error; err != nil {
+		return counts, err
+	}
+
+	// Dependants pass to the other customer; the service makes sure households stay one level deep
+	if err := r.db.Model(&model.Customer{}).Where("guardian_id = ? AND id <> ?", fromID, toID).Update("guardian_id", toID).Error; err != nil {
+		return counts, err
+	}
+
+	// A member account follows its customer; the service makes sure only one of the two has one
+	if err := r.db.Model(&model.User{}).Where("customer_id = ?", fromID).Update("customer_id", toID).Error; err != nil {
+		return counts, err
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"go-gin-simple-api/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CustomerPrivacyRepository interface {
+	FindInactive(cutoff time.Time) ([]model.Customer, error)
+	ScrubRelated(customerID uuid.UUID) error
+}
+
+type customerPrivacyRepository struct {
+	db *gorm.DB
+}
+
+func NewCustomerPrivacyRepository(db *gorm.DB) CustomerPrivacyRepository {
+	return &customerPrivacyRepository{db}
+}
+
+// FindInactive returns customers not yet anonymized who joined before the cutoff, whose membership
+// (if it expires) ended before it, and who have borrowed, returned and paid nothing since
+func (r *customerPrivacyRepository) FindInactive(cutoff time.Time) ([]model.Customer, error) {
+	var customers []model.Customer
+	if err := r.db.
+		Where("customers.anonymized_at IS NULL AND customers.created_at < ?", cutoff).
+		Where("customers.membership_expires_at IS NULL OR customers.membership_expires_at < ?", cutoff).
+		Where("NOT EXISTS (?)", r.db.Model(&model.BookTransaction{}).Select("1").
+			Where("book_transactions.customer_id = customers.id").
+			Where("book_transactions.status IN ? OR book_transactions.borrowed_at >= ? OR book_transactions.return_at >= ?",
+				[]string{model.StatusBTBorrowed, model.StatusBTOverdue}, cutoff, cutoff)).
+		Where("NOT EXISTS (?)", r.db.Model(&model.Payment{}).Select("1").
+			Where("payments.customer_id = customers.id AND payments.created_at >= ?", cutoff)).
+		Order("customers.created_at").
+		Find(&customers).Error; err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
+
+// ScrubRelated erases the personal data kept about a customer outside the customer record:
+// what notices said and where they went, notification preferences, the link to a member
+// account and the snapshots of duplicates merged into the customer
+func (r *customerPrivacyRepository) ScrubRelated(customerID uuid.UUID) error {
+	if err := r.db.Model(&model.Notification{}).Where("customer_id = ?", customerID).
+		Updates(map[string]interface{}{"recipient": "", "subject": "", "body": ""}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Where("customer_id = ?", customerID).Delete(&model.NotificationPreference{}).Error; err != nil {
+		return err
+	}
+	if err := r.db.Model(&model.User{}).Where("customer_id = ?", customerID).Update("customer_id", nil).Error; err != nil {
+		return err
+	}
+	return r.db.Model(&model.CustomerMerge{}).Where("survivor_id = ?", customerID).
+		Updates(map[string]interface{}{"merged_code": "", "merged_name": "", "snapshot": "{}"}).Error
+}
@@ -9,15 +9,20 @@ import (
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type CustomerRepository interface {
 	FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.Customer, int64, error)
 	FindByID(id uuid.UUID) (*model.Customer, error)
+	FindByIDForUpdate(id uuid.UUID) (*model.Customer, error)
 	FindByCode(code string) (*model.Customer, error)
+	FindByCodes(codes []string) ([]model.Customer, error)
+	FindDependants(guardianID uuid.UUID) ([]model.Customer, error)
 	Create(customer *model.Customer) error
 	Update(customer *model.Customer) error
 	Delete(id uuid.UUID) error
+	ExpireMemberships(now time.Time) (int64, error)
 }
 
 type customerRepository struct {
@@ -34,12 +39,13 @@ func (r *customerRepository) FindAll(page, perPage int, search string, filter li
 
 	query := r.db.Model(&model.Customer{})
 
-	// Apply search if provided
+	// Apply search if provided, matching contact details too
 	if search != "" {
-		query = query.Where("code LIKE ? OR name LIKE ?", "%"+search+"%", "%"+search+"%")
+		query = query.Where("code LIKE ? OR name LIKE ? OR email LIKE ? OR phone LIKE ?",
+			"%"+search+"%", "%"+search+"%", "%"+strings.ToLower(search)+"%", "%"+search+"%")
 	}
 
-	// Apply filters
+	// Apply filters, e.g. status:Suspended:equals, category:Student,Staff:in or membership_expires_at:2025-01-01:lessthan
 	if len(filter) > 0 {
 		for _, f := range filter {
 			switch f.Operator {
@@ -99,6 +105,15 @@ func (r *customerRepository) FindByID(id uuid.UUID) (*model.Customer, error) {
 	return &customer, nil
 }
 
+// FindByIDForUpdate loads a customer and locks its row until the surrounding database transaction ends
+func (r *customerRepository) FindByIDForUpdate(id uuid.UUID) (*model.Customer, error) {
+	var customer model.Customer
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&customer, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
 func (r *customerRepository) FindByCode(code string) (*model.Customer, error) {
 	var customer model.Customer
 	if err := r.db.First(&customer, "code = ?", code).Error; err != nil {
@@ -107,6 +122,14 @@ func (r *customerRepository) FindByCode(code string) (*model.Customer, error) {
 	return &customer, nil
 }
 
+func (r *customerRepository) FindByCodes(codes []string) ([]model.Customer, error) {
+	var customers []model.Customer
+	if err := r.db.Where("code IN ?", codes).Find(&customers).Error; err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
+
 func (r *customerRepository) Create(customer *model.Customer) error {
 	customer.CreatedAt = time.Now()
 	customer.UpdatedAt = time.Now()
@@ -118,6 +141,23 @@ func (r *customerRepository) Update(customer *model.Customer) error {
 	return r.db.Save(customer).Error
 }
 
+// FindDependants returns the customers a guardian is responsible for
+func (r *customerRepository) FindDependants(guardianID uuid.UUID) ([]model.Customer, error) {
+	var customers []model.Customer
+	if err := r.db.Where("guardian_id = ?", guardianID).Order("name").Find(&customers).Error; err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
+
 func (r *customerRepository) Delete(id uuid.UUID) error {
 	return r.db.Delete(&model.Customer{}, "id = ?", id).Error
 }
+
+// ExpireMemberships moves active customers whose membership expired before now to Expired
+func (r *customerRepository) ExpireMemberships(now time.Time) (int64, error) {
+	result := r.db.Model(&model.Customer{}).
+		Where("status = ? AND membership_expires_at < ?", model.CustomerStatusActive, now).
+		Update("status", model.CustomerStatusExpired)
+	return result.RowsAffected, result.Error
+}
@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"fmt"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type HoldRepository interface {
+	FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.Hold, int64, error)
+	FindByID(id uuid.UUID) (*model.Hold, error)
+	FindByIDForUpdate(id uuid.UUID) (*model.Hold, error)
+	FindByBookID(bookID uuid.UUID, statuses []string) ([]model.Hold, error)
+	FindByCustomerID(customerID uuid.UUID) ([]model.Hold, error)
+	FindActiveByCustomerAndBook(customerID, bookID uuid.UUID) (*model.Hold, error)
+	FindReadyByStockCodeForUpdate(stockCode string) (*model.Hold, error)
+	FindNextPendingForUpdate(bookID uuid.UUID) (*model.Hold, error)
+	FindExpiredReady(now time.Time) ([]model.Hold, error)
+	FindReady() ([]model.Hold, error)
+	CountPendingByBookID(bookID uuid.UUID) (int64, error)
+	CountPendingBefore(bookID uuid.UUID, createdAt time.Time) (int64, error)
+	Create(hold *model.Hold) error
+	Update(hold *model.Hold) error
+}
+
+type holdRepository struct {
+	db *gorm.DB
+}
+
+func NewHoldRepository(db *gorm.DB) HoldRepository {
+	return &holdRepository{db}
+}
+
+func (r *holdRepository) FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.Hold, int64, error) {
+	var holds []model.Hold
+	var total int64
+
+	query := r.db.Model(&model.Hold{})
+
+	// Apply search if provided
+	if search != "" {
+		query = query.Where("holds.status LIKE ? OR holds.stock_code LIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	// Apply filters
+	if len(filter) > 0 {
+		for _, f := range filter {
+			switch f.Operator {
+			case lib.IsEqual:
+				query = query.Where(fmt.Sprintf("holds.%s = ?", f.Field), f.Value)
+			case lib.IsNotEqual:
+				query = query.Where(fmt.Sprintf("holds.%s != ?", f.Field), f.Value)
+			case lib.IsGreaterThan:
+				query = query.Where(fmt.Sprintf("holds.%s > ?", f.Field), f.Value)
+			case lib.IsGreaterEqual:
+				query = query.Where(fmt.Sprintf("holds.%s >= ?", f.Field), f.Value)
+			case lib.IsLessThan:
+				query = query.Where(fmt.Sprintf("holds.%s < ?", f.Field), f.Value)
+			case lib.IsLessEqual:
+				query = query.Where(fmt.Sprintf("holds.%s <= ?", f.Field), f.Value)
+			case lib.IsContain:
+				query = query.Where(fmt.Sprintf("holds.%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsBeginWith:
+				query = query.Where(fmt.Sprintf("holds.%s LIKE ?", f.Field), fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsEndWith:
+				query = query.Where(fmt.Sprintf("holds.%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value))
+			case lib.IsIn:
+				if values, ok := f.Value.([]interface{}); ok {
+					query = query.Where(fmt.Sprintf("holds.%s IN ?", f.Field), values)
+				} else if str, ok := f.Value.(string); ok {
+					values := strings.Split(str, ",")
+					query = query.Where(fmt.Sprintf("holds.%s IN ?", f.Field), values)
+				}
+			}
+		}
+	}
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	offset := (page - 1) * perPage
+	if page > 0 && perPage > 0 {
+		query = query.Offset(offset).Limit(perPage)
+	}
+
+	// Execute query
+	if err := query.Preload("Book").Preload("Customer").Order("created_at").Find(&holds).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return holds, total, nil
+}
+
+func (r *holdRepository) FindByID(id uuid.UUID) (*model.Hold, error) {
+	var hold model.Hold
+	if err := r.db.Preload("Book").Preload("Customer").First(&hold, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// FindByIDForUpdate loads a hold and locks its row until the surrounding database transaction ends
+func (r *holdRepository) FindByIDForUpdate(id uuid.UUID) (*model.Hold, error) {
+	var hold model.Hold
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).First(&hold, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// FindByBookID returns the holds of a book with one of the statuses in queue order
+func (r *holdRepository) FindByBookID(bookID uuid.UUID, statuses []string) ([]model.Hold, error) {
+	var holds []model.Hold
+	if err := r.db.Preload("Book").Preload("Customer").Where("book_id = ? AND status IN ?", bookID, statuses).Order("created_at").Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+// FindByCustomerID returns every hold a customer placed, newest first
+func (r *holdRepository) FindByCustomerID(customerID uuid.UUID) ([]model.Hold, error) {
+	var holds []model.Hold
+	if err := r.db.Preload("Book").Preload("Customer").Where("customer_id = ?", customerID).Order("created_at DESC").Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+// FindActiveByCustomerAndBook returns the customer's pending or ready hold on a book
+func (r *holdRepository) FindActiveByCustomerAndBook(customerID, bookID uuid.UUID) (*model.Hold, error) {
+	var hold model.Hold
+	if err := r.db.Where("customer_id = ? AND book_id = ? AND status IN ?", customerID, bookID, []string{model.StatusHoldPending, model.StatusHoldReady}).First(&hold).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// FindReadyByStockCodeForUpdate locks and returns the hold a copy has been set aside for
+func (r *holdRepository) FindReadyByStockCodeForUpdate(stockCode string) (*model.Hold, error) {
+	var hold model.Hold
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).Where("stock_code = ? AND status = ?", stockCode, model.StatusHoldReady).First(&hold).Error; err != nil {
+		return nil, err
+	}
+	return &hold, nil
+}
+
+// FindNextPendingForUpdate locks and returns the oldest pending hold of a book, or nil when nobody is waiting
+func (r *holdRepository) FindNextPendingForUpdate(bookID uuid.UUID) (*model.Hold, error) {
+	var holds []model.Hold
+	if err := r.db.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("book_id = ? AND status = ?", bookID, model.StatusHoldPending).
+		Order("created_at").
+		Limit(1).
+		Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	if len(holds) == 0 {
+		return nil, nil
+	}
+	return &holds[0], nil
+}
+
+// FindExpiredReady returns ready holds whose pickup deadline has passed
+func (r *holdRepository) FindExpiredReady(now time.Time) ([]model.Hold, error) {
+	var holds []model.Hold
+	if err := r.db.Where("status = ? AND pickup_deadline < ?", model.StatusHoldReady, now).
+		Order("pickup_deadline").
+		Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+// FindReady returns the holds whose copy is waiting for pickup
+func (r *holdRepository) FindReady() ([]model.Hold, error) {
+	var holds []model.Hold
+	if err := r.db.Preload("Book").Preload("Customer").Where("status = ?", model.StatusHoldReady).Order("ready_at").Find(&holds).Error; err != nil {
+		return nil, err
+	}
+	return holds, nil
+}
+
+func (r *holdRepository) CountPendingByBookID(bookID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.Hold{}).Where("book_id = ? AND status = ?", bookID, model.StatusHoldPending).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// CountPendingBefore counts the pending holds of a book placed before the given time
+func (r *holdRepository) CountPendingBefore(bookID uuid.UUID, createdAt time.Time) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.Hold{}).Where("book_id = ? AND status = ? AND created_at < ?", bookID, model.StatusHoldPending, createdAt).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (r *holdRepository) Create(hold *model.Hold) error {
+	hold.CreatedAt = time.Now()
+	hold.UpdatedAt = time.Now()
+	return r.db.Create(hold).Error
+}
+
+func (r *holdRepository) Update(hold *model.Hold) error {
+	hold.UpdatedAt = time.Now()
+	return r.db.Omit(clause.Associations).Save(hold).Error
+}
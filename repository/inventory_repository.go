@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"fmt"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type InventoryRepository interface {
+	FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.InventorySession, int64, error)
+	FindByID(id uuid.UUID) (*model.InventorySession, error)
+	Create(session *model.InventorySession) error
+	Update(session *model.InventorySession) error
+	CreateScans(scans []model.InventoryScan) (int64, error)
+	CountScans(sessionID uuid.UUID) (int64, error)
+	FindMissingStocks(sessionID uuid.UUID, branchID *uuid.UUID, expectedStatuses []string) ([]model.BookStock, error)
+	FindScannedStocksByStatus(sessionID uuid.UUID, status string) ([]model.BookStock, error)
+	FindUnknownCodes(sessionID uuid.UUID) ([]string, error)
+}
+
+type inventoryRepository struct {
+	db *gorm.DB
+}
+
+func NewInventoryRepository(db *gorm.DB) InventoryRepository {
+	return &inventoryRepository{db}
+}
+
+func (r *inventoryRepository) FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.InventorySession, int64, error) {
+	var sessions []model.InventorySession
+	var total int64
+
+	query := r.db.Model(&model.InventorySession{})
+
+	// Apply search if provided
+	if search != "" {
+		query = query.Where("name LIKE ? OR notes LIKE ?", "%"+search+"%", "%"+search+"%")
+	}
+
+	// Apply filters
+	if len(filter) > 0 {
+		for _, f := range filter {
+			switch f.Operator {
+			case lib.IsEqual:
+				query = query.Where(fmt.Sprintf("%s = ?", f.Field), f.Value)
+			case lib.IsNotEqual:
+				query = query.Where(fmt.Sprintf("%s != ?", f.Field), f.Value)
+			case lib.IsGreaterThan:
+				query = query.Where(fmt.Sprintf("%s > ?", f.Field), f.Value)
+			case lib.IsGreaterEqual:
+				query = query.Where(fmt.Sprintf("%s >= ?", f.Field), f.Value)
+			case lib.IsLessThan:
+				query = query.Where(fmt.Sprintf("%s < ?", f.Field), f.Value)
+			case lib.IsLessEqual:
+				query = query.Where(fmt.Sprintf("%s <= ?", f.Field), f.Value)
+			case lib.IsContain:
+				query = query.Where(fmt.Sprintf("%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsBeginWith:
+				query = query.Where(fmt.Sprintf("%s LIKE ?", f.Field), fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsEndWith:
+				query = query.Where(fmt.Sprintf("%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value))
+			case lib.IsIn:
+				if values, ok := f.Value.([]interface{}); ok {
+					query = query.Where(fmt.Sprintf("%s IN ?", f.Field), values)
+				} else if str, ok := f.Value.(string); ok {
+					values := strings.Split(str, ",")
+					query = query.Where(fmt.Sprintf("%s IN ?", f.Field), values)
+				}
+			}
+		}
+	}
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	offset := (page - 1) * perPage
+	if page > 0 && perPage > 0 {
+		query = query.Offset(offset).Limit(perPage)
+	}
+
+	// Execute query
+	if err := query.Preload("User").Order("started_at DESC").Find(&sessions).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return sessions, total, nil
+}
+
+func (r *inventoryRepository) FindByID(id uuid.UUID) (*model.InventorySession, error) {
+	var session model.InventorySession
+	if err := r.db.Preload("User").First(&session, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *inventoryRepository) Create(session *model.InventorySession) error {
+	return r.db.Create(session).Error
+}
+
+func (r *inventoryRepository) Update(session *model.InventorySession) error {
+	return r.db.Omit("User").Save(session).Error
+}
+
+// CreateScans stores scans, ignoring codes already scanned in the same session, and returns how many were new
+func (r *inventoryRepository) CreateScans(scans []model.InventoryScan) (int64, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&scans)
+	return result.RowsAffected, result.Error
+}
+
+func (r *inventoryRepository) CountScans(sessionID uuid.UUID) (int64, error) {
+	var count int64
+	if err := r.db.Model(&model.InventoryScan{}).Where("session_id = ?", sessionID).Count(&count).Error; err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// FindMissingStocks returns copies expected on the shelves that were not scanned in the session
+func (r *inventoryRepository) FindMissingStocks(sessionID uuid.UUID, branchID *uuid.UUID, expectedStatuses []string) ([]model.BookStock, error) {
+	var bookStocks []model.BookStock
+	scanned := r.db.Model(&model.InventoryScan{}).Select("code").Where("session_id = ?", sessionID)
+	query := r.db.Preload("Book").Where("status IN ? AND code NOT IN (?)", expectedStatuses, scanned)
+	if branchID != nil {
+		query = query.Where("current_branch_id = ?", *branchID)
+	}
+	if err := query.Order("code").Find(&bookStocks).Error; err != nil {
+		return nil, err
+	}
+	return bookStocks, nil
+}
+
+// FindScannedStocksByStatus returns scanned copies whose recorded status is the given one
+func (r *inventoryRepository) FindScannedStocksByStatus(sessionID uuid.UUID, status string) ([]model.BookStock, error) {
+	var bookStocks []model.BookStock
+	scanned := r.db.Model(&model.InventoryScan{}).Select("code").Where("session_id = ?", sessionID)
+	if err := r.db.Preload("Book").Where("status = ? AND code IN (?)", status, scanned).Order("code").Find(&bookStocks).Error; err != nil {
+		return nil, err
+	}
+	return bookStocks, nil
+}
+
+// FindUnknownCodes returns scanned codes that do not match any book stock
+func (r *inventoryRepository) FindUnknownCodes(sessionID uuid.UUID) ([]string, error) {
+	var codes []string
+	known := r.db.Model(&model.BookStock{}).Select("code")
+	if err := r.db.Model(&model.InventoryScan{}).Where("session_id = ? AND code NOT IN (?)", sessionID, known).Order("code").Pluck("code", &codes).Error; err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
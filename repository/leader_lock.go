@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+
+	"gorm.io/gorm"
+)
+
+// LeaderLock elects a single leader among server replicas sharing a database.
+// It is backed by a session-level Postgres advisory lock held on a dedicated connection,
+// so leadership passes to another replica as soon as the leader's connection goes away.
+type LeaderLock interface {
+	// TryAcquire reports whether this instance holds the lock, taking it when it is free
+	TryAcquire(ctx context.Context) (bool, error)
+	// Release gives up the lock if this instance holds it
+	Release(ctx context.Context) error
+}
+
+type leaderLock struct {
+	db   *gorm.DB
+	key  int64
+	mu   sync.Mutex
+	conn *sql.Conn
+}
+
+func NewLeaderLock(db *gorm.DB, key int64) LeaderLock {
+	return &leaderLock{db: db, key: key}
+}
+
+func (l *leaderLock) TryAcquire(ctx context.Context) (bool, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	// The lock lives as long as the session that took it
+	if l.conn != nil {
+		if err := l.conn.PingContext(ctx); err == nil {
+			return true, nil
+		}
+		l.conn.Close()
+		l.conn = nil
+	}
+
+	sqlDB, err := l.db.DB()
+	if err != nil {
+		return false, err
+	}
+	conn, err := sqlDB.Conn(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", l.key).Scan(&acquired); err != nil {
+		conn.Close()
+		return false, err
+	}
+	if !acquired {
+		conn.Close()
+		return false, nil
+	}
+
+	l.conn = conn
+	return true, nil
+}
+
+func (l *leaderLock) Release(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.conn == nil {
+		return nil
+	}
+	_, err := l.conn.ExecContext(ctx, "SELECT pg_advisory_unlock($1)", l.key)
+	l.conn.Close()
+	l.conn = nil
+	return err
+}
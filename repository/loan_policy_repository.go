@@ -0,0 +1,144 @@
+package repository
+
+import (
+	"fmt"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type LoanPolicyRepository interface {
+	FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.LoanPolicy, int64, error)
+	FindByID(id uuid.UUID) (*model.LoanPolicy, error)
+	FindByScope(customerCategory, materialType string) (*model.LoanPolicy, error)
+	FindMatching(customerCategory, materialType string) (*model.LoanPolicy, error)
+	Create(policy *model.LoanPolicy) error
+	Update(policy *model.LoanPolicy) error
+	Delete(id uuid.UUID) error
+}
+
+type loanPolicyRepository struct {
+	db *gorm.DB
+}
+
+func NewLoanPolicyRepository(db *gorm.DB) LoanPolicyRepository {
+	return &loanPolicyRepository{db}
+}
+
+func (r *loanPolicyRepository) FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.LoanPolicy, int64, error) {
+	var policies []model.LoanPolicy
+	var total int64
+
+	query := r.db.Model(&model.LoanPolicy{})
+
+	// Apply search if provided
+	if search != "" {
+		query = query.Where("name LIKE ? OR customer_category LIKE ? OR material_type LIKE ?", "%"+search+"%", "%"+search+"%", "%"+search+"%")
+	}
+
+	// Apply filters
+	if len(filter) > 0 {
+		for _, f := range filter {
+			switch f.Operator {
+			case lib.IsEqual:
+				query = query.Where(fmt.Sprintf("%s = ?", f.Field), f.Value)
+			case lib.IsNotEqual:
+				query = query.Where(fmt.Sprintf("%s != ?", f.Field), f.Value)
+			case lib.IsGreaterThan:
+				query = query.Where(fmt.Sprintf("%s > ?", f.Field), f.Value)
+			case lib.IsGreaterEqual:
+				query = query.Where(fmt.Sprintf("%s >= ?", f.Field), f.Value)
+			case lib.IsLessThan:
+				query = query.Where(fmt.Sprintf("%s < ?", f.Field), f.Value)
+			case lib.IsLessEqual:
+				query = query.Where(fmt.Sprintf("%s <= ?", f.Field), f.Value)
+			case lib.IsContain:
+				query = query.Where(fmt.Sprintf("%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsBeginWith:
+				query = query.Where(fmt.Sprintf("%s LIKE ?", f.Field), fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsEndWith:
+				query = query.Where(fmt.Sprintf("%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value))
+			case lib.IsIn:
+				if values, ok := f.Value.([]interface{}); ok {
+					query = query.Where(fmt.Sprintf("%s IN ?", f.Field), values)
+				} else if str, ok := f.Value.(string); ok {
+					values := strings.Split(str, ",")
+					query = query.Where(fmt.Sprintf("%s IN ?", f.Field), values)
+				}
+			}
+		}
+	}
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	offset := (page - 1) * perPage
+	if page > 0 && perPage > 0 {
+		query = query.Offset(offset).Limit(perPage)
+	}
+
+	// Execute query
+	if err := query.Order("customer_category, material_type").Find(&policies).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return policies, total, nil
+}
+
+func (r *loanPolicyRepository) FindByID(id uuid.UUID) (*model.LoanPolicy, error) {
+	var policy model.LoanPolicy
+	if err := r.db.First(&policy, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// FindByScope returns the policy defined for exactly this category and material type
+func (r *loanPolicyRepository) FindByScope(customerCategory, materialType string) (*model.LoanPolicy, error) {
+	var policy model.LoanPolicy
+	if err := r.db.Where("customer_category = ? AND material_type = ?", customerCategory, materialType).First(&policy).Error; err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// FindMatching returns the most specific policy that applies to a customer category and material type.
+// Policies naming both win over those naming only the category, which win over those naming
+// only the material type, which win over the catch-all policy. It returns nil when none applies.
+func (r *loanPolicyRepository) FindMatching(customerCategory, materialType string) (*model.LoanPolicy, error) {
+	var policies []model.LoanPolicy
+	if err := r.db.
+		Where("customer_category IN ?", []string{customerCategory, ""}).
+		Where("material_type IN ?", []string{materialType, ""}).
+		Order("customer_category <> '' DESC, material_type <> '' DESC").
+		Limit(1).
+		Find(&policies).Error; err != nil {
+		return nil, err
+	}
+	if len(policies) == 0 {
+		return nil, nil
+	}
+	return &policies[0], nil
+}
+
+func (r *loanPolicyRepository) Create(policy *model.LoanPolicy) error {
+	policy.CreatedAt = time.Now()
+	policy.UpdatedAt = time.Now()
+	return r.db.Create(policy).Error
+}
+
+func (r *loanPolicyRepository) Update(policy *model.LoanPolicy) error {
+	policy.UpdatedAt = time.Now()
+	return r.db.Save(policy).Error
+}
+
+func (r *loanPolicyRepository) Delete(id uuid.UUID) error {
+	return r.db.Delete(&model.LoanPolicy{}, "id = ?", id).Error
+}
@@ -0,0 +1,153 @@
+package repository
+
+import (
+	"fmt"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"strings"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type NotificationRepository interface {
+	FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.Notification, int64, error)
+	FindByCustomerID(customerID uuid.UUID) ([]model.Notification, error)
+	CountByReference(reference, channel string, statuses []string) (int64, error)
+	Create(notification *model.Notification) error
+	FindPreferencesByCustomerID(customerID uuid.UUID) ([]model.NotificationPreference, error)
+	ReplacePreferences(customerID uuid.UUID, preferences []model.NotificationPreference) error
+	FindTemplates() ([]model.NotificationTemplate, error)
+	SaveTemplate(template *model.NotificationTemplate) error
+}
+
+type notificationRepository struct {
+	db *gorm.DB
+}
+
+func NewNotificationRepository(db *gorm.DB) NotificationRepository {
+	return &notificationRepository{db}
+}
+
+func (r *notificationRepository) FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.Notification, int64, error) {
+	var notifications []model.Notification
+	var total int64
+
+	query := r.db.Model(&model.Notification{})
+
+	// Apply search if provided
+	if search != "" {
+		query = query.Where("notifications.recipient LIKE ? OR notifications.subject LIKE ? OR notifications.reference LIKE ?", "%"+search+"%", "%"+search+"%", "%"+search+"%")
+	}
+
+	// Apply filters
+	if len(filter) > 0 {
+		for _, f := range filter {
+			switch f.Operator {
+			case lib.IsEqual:
+				query = query.Where(fmt.Sprintf("notifications.%s = ?", f.Field), f.Value)
+			case lib.IsNotEqual:
+				query = query.Where(fmt.Sprintf("notifications.%s != ?", f.Field), f.Value)
+			case lib.IsGreaterThan:
+				query = query.Where(fmt.Sprintf("notifications.%s > ?", f.Field), f.Value)
+			case lib.IsGreaterEqual:
+				query = query.Where(fmt.Sprintf("notifications.%s >= ?", f.Field), f.Value)
+			case lib.IsLessThan:
+				query = query.Where(fmt.Sprintf("notifications.%s < ?", f.Field), f.Value)
+			case lib.IsLessEqual:
+				query = query.Where(fmt.Sprintf("notifications.%s <= ?", f.Field), f.Value)
+			case lib.IsContain:
+				query = query.Where(fmt.Sprintf("notifications.%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsBeginWith:
+				query = query.Where(fmt.Sprintf("notifications.%s LIKE ?", f.Field), fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsEndWith:
+				query = query.Where(fmt.Sprintf("notifications.%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value))
+			case lib.IsIn:
+				if values, ok := f.Value.([]interface{}); ok {
+					query = query.Where(fmt.Sprintf("notifications.%s IN ?", f.Field), values)
+				} else if str, ok := f.Value.(string); ok {
+					values := strings.Split(str, ",")
+					query = query.Where(fmt.Sprintf("notifications.%s IN ?", f.Field), values)
+				}
+			}
+		}
+	}
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	offset := (page - 1) * perPage
+	if page > 0 && perPage > 0 {
+		query = query.Offset(offset).Limit(perPage)
+	}
+
+	// Execute query
+	if err := query.Preload("Customer").Order("created_at DESC").Find(&notifications).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return notifications, total, nil
+}
+
+// FindByCustomerID returns the delivery log of a customer, newest first
+func (r *notificationRepository) FindByCustomerID(customerID uuid.UUID) ([]model.Notification, error) {
+	var notifications []model.Notification
+	if err := r.db.Where("customer_id = ?", customerID).Order("created_at DESC").Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+// CountByReference counts the log entries of a notice on a channel with one of the statuses
+func (r *notificationRepository) CountByReference(reference, channel string, statuses []string) (int64, error) {
+	var count int64
+	err := r.db.Model(&model.Notification{}).
+		Where("reference = ? AND channel = ? AND status IN ?", reference, channel, statuses).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *notificationRepository) Create(notification *model.Notification) error {
+	return r.db.Create(notification).Error
+}
+
+func (r *notificationRepository) FindPreferencesByCustomerID(customerID uuid.UUID) ([]model.NotificationPreference, error) {
+	var preferences []model.NotificationPreference
+	if err := r.db.Where("customer_id = ?", customerID).Order("channel").Find(&preferences).Error; err != nil {
+		return nil, err
+	}
+	return preferences, nil
+}
+
+// ReplacePreferences swaps all channel preferences of a customer for the given ones
+func (r *notificationRepository) ReplacePreferences(customerID uuid.UUID, preferences []model.NotificationPreference) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("customer_id = ?", customerID).Delete(&model.NotificationPreference{}).Error; err != nil {
+			return err
+		}
+		if len(preferences) == 0 {
+			return nil
+		}
+		return tx.Create(&preferences).Error
+	})
+}
+
+func (r *notificationRepository) FindTemplates() ([]model.NotificationTemplate, error) {
+	var templates []model.NotificationTemplate
+	if err := r.db.Order("type, channel").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	return templates, nil
+}
+
+// SaveTemplate creates the template of a notice type and channel, or replaces the existing one
+func (r *notificationRepository) SaveTemplate(template *model.NotificationTemplate) error {
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "type"}, {Name: "channel"}},
+		DoUpdates: clause.AssignmentColumns([]string{"subject", "body", "updated_at"}),
+	}).Create(template).Error
+}
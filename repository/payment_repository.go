@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"fmt"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type PaymentRepository interface {
+	FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.Payment, int64, error)
+	FindByID(id uuid.UUID) (*model.Payment, error)
+	FindByCustomerID(customerID uuid.UUID) ([]model.Payment, error)
+	Create(payment *model.Payment) error
+	CreateAdjustment(adjustment *model.ChargeAdjustment) error
+	CreateRefund(refund *model.Refund) error
+}
+
+type paymentRepository struct {
+	db *gorm.DB
+}
+
+func NewPaymentRepository(db *gorm.DB) PaymentRepository {
+	return &paymentRepository{db}
+}
+
+func (r *paymentRepository) FindAll(page, perPage int, search string, filter lib.FilterParams) ([]model.Payment, int64, error) {
+	var payments []model.Payment
+	var total int64
+
+	query := r.db.Model(&model.Payment{})
+
+	// Apply search if provided
+	if search != "" {
+		query = query.Where("payments.receipt_number LIKE ? OR payments.method LIKE ? OR payments.note LIKE ?", "%"+search+"%", "%"+search+"%", "%"+search+"%")
+	}
+
+	// Apply filters
+	if len(filter) > 0 {
+		for _, f := range filter {
+			switch f.Operator {
+			case lib.IsEqual:
+				query = query.Where(fmt.Sprintf("payments.%s = ?", f.Field), f.Value)
+			case lib.IsNotEqual:
+				query = query.Where(fmt.Sprintf("payments.%s != ?", f.Field), f.Value)
+			case lib.IsGreaterThan:
+				query = query.Where(fmt.Sprintf("payments.%s > ?", f.Field), f.Value)
+			case lib.IsGreaterEqual:
+				query = query.Where(fmt.Sprintf("payments.%s >= ?", f.Field), f.Value)
+			case lib.IsLessThan:
+				query = query.Where(fmt.Sprintf("payments.%s < ?", f.Field), f.Value)
+			case lib.IsLessEqual:
+				query = query.Where(fmt.Sprintf("payments.%s <= ?", f.Field), f.Value)
+			case lib.IsContain:
+				query = query.Where(fmt.Sprintf("payments.%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsBeginWith:
+				query = query.Where(fmt.Sprintf("payments.%s LIKE ?", f.Field), fmt.Sprintf("%v", f.Value)+"%")
+			case lib.IsEndWith:
+				query = query.Where(fmt.Sprintf("payments.%s LIKE ?", f.Field), "%"+fmt.Sprintf("%v", f.Value))
+			case lib.IsIn:
+				if values, ok := f.Value.([]interface{}); ok {
+					query = query.Where(fmt.Sprintf("payments.%s IN ?", f.Field), values)
+				} else if str, ok := f.Value.(string); ok {
+					values := strings.Split(str, ",")
+					query = query.Where(fmt.Sprintf("payments.%s IN ?", f.Field), values)
+				}
+			}
+		}
+	}
+
+	// Count total
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	// Apply pagination
+	offset := (page - 1) * perPage
+	if page > 0 && perPage > 0 {
+		query = query.Offset(offset).Limit(perPage)
+	}
+
+	// Execute query
+	if err := query.Preload("Customer").Preload("User").Order("created_at DESC").Find(&payments).Error; err != nil {
+		return nil, 0, err
+	}
+
+	return payments, total, nil
+}
+
+func (r *paymentRepository) FindByID(id uuid.UUID) (*model.Payment, error) {
+	var payment model.Payment
+	if err := r.db.Preload("Charge").Preload("Charge.BookTransaction").Preload("Charge.BookTransaction.Book").
+		Preload("Charge.Payments").Preload("Charge.Adjustments").Preload("Charge.Refunds").Preload("Customer").Preload("User").
+		First(&payment, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *paymentRepository) FindByCustomerID(customerID uuid.UUID) ([]model.Payment, error) {
+	var payments []model.Payment
+	if err := r.db.Preload("User").Where("customer_id = ?", customerID).Order("created_at").Find(&payments).Error; err != nil {
+		return nil, err
+	}
+	return payments, nil
+}
+
+func (r *paymentRepository) Create(payment *model.Payment) error {
+	payment.CreatedAt = time.Now()
+	return r.db.Omit("Charge", "Customer", "User").Create(payment).Error
+}
+
+func (r *paymentRepository) CreateAdjustment(adjustment *model.ChargeAdjustment) error {
+	adjustment.CreatedAt = time.Now()
+	return r.db.Omit("ApprovedBy").Create(adjustment).Error
+}
+
+func (r *paymentRepository) CreateRefund(refund *model.Refund) error {
+	refund.CreatedAt = time.Now()
+	return r.db.Create(refund).Error
+}
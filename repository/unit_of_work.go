@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"gorm.io/gorm"
+)
+
+// Repositories groups the repositories taking part in a unit of work.
+// Inside UnitOfWork.Do they all share the same database transaction.
+type Repositories struct {
+	BookTransactions BookTransactionRepository
+	BookStocks       BookStockRepository
+	Customers        CustomerRepository
+	LoanPolicies     LoanPolicyRepository
+	Holds            HoldRepository
+	Charges          ChargeRepository
+	Payments         PaymentRepository
+	CustomerMerges   CustomerMergeRepository
+	CustomerPrivacy  CustomerPrivacyRepository
+	BranchTransfers  BranchTransferRepository
+}
+
+// UnitOfWork runs a set of repository operations atomically
+type UnitOfWork interface {
+	// Do runs fn inside a database transaction, committing when it returns nil
+	// and rolling back on an error or panic
+	Do(fn func(repos Repositories) error) error
+}
+
+type unitOfWork struct {
+	db *gorm.DB
+}
+
+func NewUnitOfWork(db *gorm.DB) UnitOfWork {
+	return &unitOfWork{db}
+}
+
+func (u *unitOfWork) Do(fn func(repos Repositories) error) error {
+	return u.db.Transaction(func(tx *gorm.DB) error {
+		return fn(Repositories{
+			BookTransactions: NewBookTransactionRepository(tx),
+			BookStocks:       NewBookStockRepository(tx),
+			Customers:        NewCustomerRepository(tx),
+			LoanPolicies:     NewLoanPolicyRepository(tx),
+			Holds:            NewHoldRepository(tx),
+			Charges:          NewChargeRepository(tx),
+			Payments:         NewPaymentRepository(tx),
+			CustomerMerges:   NewCustomerMergeRepository(tx),
+			CustomerPrivacy:  NewCustomerPrivacyRepository(tx),
+			BranchTransfers:  NewBranchTransferRepository(tx),
+		})
+	})
+}
@@ -8,24 +8,32 @@ import (
 	"go-gin-simple-api/model"
 	"go-gin-simple-api/repository"
 	"go-gin-simple-api/utils"
+
+	"github.com/google/uuid"
 )
 
 type AuthService interface {
 	Authenticate(ctx context.Context, req dto.AuthReq) (dto.AuthRes, error)
 	Register(ctx context.Context, req dto.RegisterReq) (dto.UserData, error)
 	Validate(ctx context.Context, tokenString string) (dto.UserData, error)
+	LinkCustomer(ctx context.Context, userID uuid.UUID, req dto.UserCustomerLinkRequest) (dto.UserData, error)
+	AssignBranch(ctx context.Context, userID uuid.UUID, req dto.UserBranchAssignRequest) (dto.UserData, error)
 }
 
 type authService struct {
-	repo repository.AuthRepository
-	cfg  *config.Config
+	repo         repository.AuthRepository
+	customerRepo repository.CustomerRepository
+	branchRepo   repository.BranchRepository
+	cfg          *config.Config
 }
 
-func NewAuthService(repo repository.AuthRepository) *authService {
+func NewAuthService(repo repository.AuthRepository, customerRepo repository.CustomerRepository, branchRepo repository.BranchRepository) *authService {
 	cfg, _ := config.LoadConfig()
 	return &authService{
-		repo: repo,
-		cfg:  cfg,
+		repo:         repo,
+		customerRepo: customerRepo,
+		branchRepo:   branchRepo,
+		cfg:          cfg,
 	}
 }
 
@@ -89,11 +97,7 @@ func (s *authService) Register(ctx context.Context, req dto.RegisterReq) (dto.Us
 		Name:     req.Name,
 		Email:    req.Email,
 		Password: hashedPassword,
-		Role:     "user", // Default role
-	}
-
-	if req.Role != "" {
-		user.Role = req.Role
+		Role:     "user", // Public sign-ups are never admins
 	}
 
 	if err := s.repo.Create(&user); err != nil {
@@ -102,10 +106,11 @@ func (s *authService) Register(ctx context.Context, req dto.RegisterReq) (dto.Us
 
 	// Generate user data
 	userData := dto.UserData{
-		ID:    user.ID,
-		Name:  user.Name,
-		Email: user.Email,
-		Role:  user.Role,
+		ID:       user.ID,
+		Name:     user.Name,
+		Email:    user.Email,
+		Role:     user.Role,
+		BranchID: user.BranchID,
 	}
 
 	return userData, nil
@@ -114,3 +119,63 @@ func (s *authService) Register(ctx context.Context, req dto.RegisterReq) (dto.Us
 func (s *authService) Validate(ctx context.Context, tokenString string) (dto.UserData, error) {
 	return utils.ValidateToken(tokenString, s.cfg)
 }
+
+// LinkCustomer links a member account to its customer record, or removes the link.
+// A customer record belongs to at most one account.
+func (s *authService) LinkCustomer(ctx context.Context, userID uuid.UUID, req dto.UserCustomerLinkRequest) (dto.UserData, error) {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return dto.UserData{}, err
+	}
+
+	if req.CustomerID != nil {
+		if _, err := s.customerRepo.FindByID(*req.CustomerID); err != nil {
+			return dto.UserData{}, errors.New("customer not found")
+		}
+		if linked, err := s.repo.FindByCustomerID(*req.CustomerID); err == nil && linked.ID != user.ID {
+			return dto.UserData{}, errors.New("customer is already linked to another user")
+		}
+	}
+
+	user.CustomerID = req.CustomerID
+	if err := s.repo.Update(user); err != nil {
+		return dto.UserData{}, err
+	}
+
+	return dto.UserData{
+		ID:         user.ID,
+		Name:       user.Name,
+		Email:      user.Email,
+		Role:       user.Role,
+		BranchID:   user.BranchID,
+		CustomerID: user.CustomerID,
+	}, nil
+}
+
+// AssignBranch binds a staff account to a branch, or removes the binding
+func (s *authService) AssignBranch(ctx context.Context, userID uuid.UUID, req dto.UserBranchAssignRequest) (dto.UserData, error) {
+	user, err := s.repo.FindByID(userID)
+	if err != nil {
+		return dto.UserData{}, err
+	}
+
+	if req.BranchID != nil {
+		if _, err := s.branchRepo.FindByID(*req.BranchID); err != nil {
+			return dto.UserData{}, errors.New("branch not found")
+		}
+	}
+
+	user.BranchID = req.BranchID
+	if err := s.repo.Update(user); err != nil {
+		return dto.UserData{}, err
+	}
+
+	return dto.UserData{
+		ID:         user.ID,
+		Name:       user.Name,
+		Email:      user.Email,
+		Role:       user.Role,
+		BranchID:   user.BranchID,
+		CustomerID: user.CustomerID,
+	}, nil
+}
@@ -64,7 +64,7 @@ func (s *bookService) GetBooks(page, perPage int, search string, filter lib.Filt
 func (s *bookService) GetBookByID(id uuid.UUID) (*dto.BookRes, error) {
 	book, err := s.repo.FindByID(id)
 	if err != nil {
-		return nil, errors.New("book not found")
+		return nil, ErrBookNotFound
 	}
 
 	response := mapBookToResponse(book)
@@ -73,8 +73,9 @@ func (s *bookService) GetBookByID(id uuid.UUID) (*dto.BookRes, error) {
 
 func (s *bookService) CreateBook(req dto.BookCreateReq) (*dto.BookRes, error) {
 	book := model.Book{
-		Title:       req.Title,
-		Description: req.Description,
+		Title:        req.Title,
+		Description:  req.Description,
+		MaterialType: req.MaterialType,
 	}
 
 	if req.CoverID != nil {
@@ -96,7 +97,7 @@ func (s *bookService) CreateBook(req dto.BookCreateReq) (*dto.BookRes, error) {
 func (s *bookService) UpdateBook(id uuid.UUID, req dto.BookUpdateReq) (*dto.BookRes, error) {
 	book, err := s.repo.FindByID(id)
 	if err != nil {
-		return nil, errors.New("book not found")
+		return nil, ErrBookNotFound
 	}
 
 	// Update fields if provided
@@ -108,6 +109,10 @@ func (s *bookService) UpdateBook(id uuid.UUID, req dto.BookUpdateReq) (*dto.Book
 		book.Description = req.Description
 	}
 
+	if req.MaterialType != "" {
+		book.MaterialType = req.MaterialType
+	}
+
 	if req.CoverID != nil {
 		cover, err := s.mediaRepo.FindByID(*req.CoverID)
 		if err != nil {
@@ -127,7 +132,7 @@ func (s *bookService) UpdateBook(id uuid.UUID, req dto.BookUpdateReq) (*dto.Book
 func (s *bookService) DeleteBook(id uuid.UUID) error {
 	_, err := s.repo.FindByID(id)
 	if err != nil {
-		return errors.New("book not found")
+		return ErrBookNotFound
 	}
 	return s.repo.Delete(id)
 }
@@ -135,7 +140,7 @@ func (s *bookService) DeleteBook(id uuid.UUID) error {
 func (s *bookService) DeleteBookCover(id uuid.UUID) error {
 	book, err := s.repo.FindByID(id)
 	if err != nil {
-		return errors.New("book not found")
+		return ErrBookNotFound
 	}
 
 	if book.CoverID == nil || book.Cover == nil {
@@ -151,9 +156,10 @@ func (s *bookService) DeleteBookCover(id uuid.UUID) error {
 // Helper function to map domain book to DTO response
 func mapBookToResponse(book *model.Book) dto.BookRes {
 	response := dto.BookRes{
-		ID:          book.ID,
-		Title:       book.Title,
-		Description: book.Description,
+		ID:           book.ID,
+		Title:        book.Title,
+		Description:  book.Description,
+		MaterialType: book.MaterialType,
 	}
 
 	if book.Cover != nil {
@@ -2,10 +2,12 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"go-gin-simple-api/dto"
 	"go-gin-simple-api/lib"
 	"go-gin-simple-api/model"
 	"go-gin-simple-api/repository"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -14,22 +16,56 @@ type BookStockService interface {
 	GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.BookStockResponse], error)
 	GetByCode(code string) (*dto.BookStockResponse, error)
 	GetByBookID(bookID uuid.UUID) ([]dto.BookStockResponse, error)
-	GetAvailableByBookID(bookID uuid.UUID) ([]dto.BookStockResponse, error)
-	Create(bookStockRequest dto.BookStockCreateRequest) (*dto.BookStockResponse, error)
-	Update(code string, bookStockRequest dto.BookStockUpdateRequest) (*dto.BookStockResponse, error)
-	Delete(code string) error
-	UpdateStatus(code string, req dto.BookStockStatusUpdateRequest) (*dto.BookStockResponse, error)
+	GetAvailableByBookID(bookID uuid.UUID, branchID *uuid.UUID) ([]dto.BookStockResponse, error)
+	GetAvailabilityByBookID(bookID uuid.UUID) ([]dto.BranchAvailabilityResponse, error)
+	Create(actor dto.UserData, bookStockRequest dto.BookStockCreateRequest) (*dto.BookStockResponse, error)
+	BulkCreate(actor dto.UserData, req dto.BookStockBulkCreateRequest) (*dto.BookStockBulkCreateResponse, error)
+	Update(code string, actor dto.UserData, bookStockRequest dto.BookStockUpdateRequest) (*dto.BookStockResponse, error)
+	Delete(code string, actor dto.UserData) error
+	UpdateStatus(code string, actor dto.UserData, req dto.BookStockStatusUpdateRequest) (*dto.BookStockResponse, error)
+	MarkFound(code string, actor dto.UserData, req dto.BookStockFoundRequest) (*dto.BookStockResponse, error)
+	GetStatusHistory(code string) ([]dto.BookStockStatusHistoryResponse, error)
 }
 
+// ErrBookNotFound is returned when no book has the requested ID
+var ErrBookNotFound = errors.New("book not found")
+
+// ErrBookStockNotFound is returned when no book stock has the requested code
+var ErrBookStockNotFound = errors.New("book stock not found")
+
+// ErrInvalidCodePattern is returned when the configured code pattern cannot produce the requested codes
+var ErrInvalidCodePattern = errors.New("invalid stock code pattern")
+
+// Number of times code generation retries when generated codes clash with manually entered ones
+const maxCodeGenerationAttempts = 5
+
 type bookStockService struct {
-	repository repository.BookStockRepository
-	bookRepo   repository.BookRepository
+	repository    repository.BookStockRepository
+	bookRepo      repository.BookRepository
+	branchRepo    repository.BranchRepository
+	sequenceRepo  repository.CodeSequenceRepository
+	uow           repository.UnitOfWork
+	codePattern   *lib.CodePattern
+	defaultBranch string
 }
 
-func NewBookStockService(repository repository.BookStockRepository, bookRepo repository.BookRepository) BookStockService {
+func NewBookStockService(
+	repository repository.BookStockRepository,
+	bookRepo repository.BookRepository,
+	branchRepo repository.BranchRepository,
+	sequenceRepo repository.CodeSequenceRepository,
+	uow repository.UnitOfWork,
+	codePattern *lib.CodePattern,
+	defaultBranch string,
+) BookStockService {
 	return &bookStockService{
-		repository: repository,
-		bookRepo:   bookRepo,
+		repository:    repository,
+		bookRepo:      bookRepo,
+		branchRepo:    branchRepo,
+		sequenceRepo:  sequenceRepo,
+		uow:           uow,
+		codePattern:   codePattern,
+		defaultBranch: defaultBranch,
 	}
 }
 
@@ -88,8 +124,8 @@ func (s *bookStockService) GetByBookID(bookID uuid.UUID) ([]dto.BookStockRespons
 	return bookStockResponses, nil
 }
 
-func (s *bookStockService) GetAvailableByBookID(bookID uuid.UUID) ([]dto.BookStockResponse, error) {
-	bookStocks, err := s.repository.FindAvailableByBookID(bookID)
+func (s *bookStockService) GetAvailableByBookID(bookID uuid.UUID, branchID *uuid.UUID) ([]dto.BookStockResponse, error) {
+	bookStocks, err := s.repository.FindAvailableByBookID(bookID, branchID)
 	if err != nil {
 		return nil, err
 	}
@@ -102,23 +138,92 @@ func (s *bookStockService) GetAvailableByBookID(bookID uuid.UUID) ([]dto.BookSto
 	return bookStockResponses, nil
 }
 
-func (s *bookStockService) Create(req dto.BookStockCreateRequest) (*dto.BookStockResponse, error) {
+func (s *bookStockService) GetAvailabilityByBookID(bookID uuid.UUID) ([]dto.BranchAvailabilityResponse, error) {
+	// Check if book exists
+	if _, err := s.bookRepo.FindByID(bookID); err != nil {
+		return nil, ErrBookNotFound
+	}
+
+	counts, err := s.repository.CountByBookIDPerBranch(bookID)
+	if err != nil {
+		return nil, err
+	}
+
+	branchIDs := make([]uuid.UUID, 0, len(counts))
+	for _, count := range counts {
+		if count.CurrentBranchID != nil {
+			branchIDs = append(branchIDs, *count.CurrentBranchID)
+		}
+	}
+
+	branchesByID := make(map[uuid.UUID]model.Branch, len(branchIDs))
+	if len(branchIDs) > 0 {
+		branches, err := s.branchRepo.FindByIDs(branchIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, branch := range branches {
+			branchesByID[branch.ID] = branch
+		}
+	}
+
+	// Copies without a location are reported with an empty branch
+	responses := make([]dto.BranchAvailabilityResponse, 0, len(counts))
+	for _, count := range counts {
+		response := dto.BranchAvailabilityResponse{
+			BranchID:  count.CurrentBranchID,
+			Available: count.Available,
+			Total:     count.Total,
+		}
+		if count.CurrentBranchID != nil {
+			if branch, ok := branchesByID[*count.CurrentBranchID]; ok {
+				response.BranchCode = branch.Code
+				response.BranchName = branch.Name
+			}
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+func (s *bookStockService) Create(actor dto.UserData, req dto.BookStockCreateRequest) (*dto.BookStockResponse, error) {
 	// Check if book exists
 	_, err := s.bookRepo.FindByID(req.BookID)
 	if err != nil {
-		return nil, errors.New("book not found")
+		return nil, ErrBookNotFound
 	}
 
-	// Check if code already exists
-	existingStock, err := s.repository.FindByCode(req.Code)
-	if err == nil && existingStock != nil {
-		return nil, errors.New("code already exists")
+	branch, err := s.resolveBranch(actor, req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Code == "" {
+		// Generate a code from the configured pattern
+		codes, err := s.generateCodes(branchCode(req.Branch, branch), 1)
+		if err != nil {
+			return nil, err
+		}
+		req.Code = codes[0]
+	} else {
+		// Check if code already exists
+		existingStock, err := s.repository.FindByCode(req.Code)
+		if err == nil && existingStock != nil {
+			return nil, errors.New("code already exists")
+		}
 	}
 
 	bookStock := model.BookStock{
 		Code:   req.Code,
 		BookID: req.BookID,
 		Status: model.StatusAvailable,
+		Price:  req.Price,
+	}
+
+	if branch != nil {
+		bookStock.OwnerBranchID = &branch.ID
+		bookStock.CurrentBranchID = &branch.ID
 	}
 
 	if req.Status != "" {
@@ -133,61 +238,277 @@ func (s *bookStockService) Create(req dto.BookStockCreateRequest) (*dto.BookStoc
 	return &response, nil
 }
 
-func (s *bookStockService) Update(code string, req dto.BookStockUpdateRequest) (*dto.BookStockResponse, error) {
-	// Check if book stock exists
-	bookStock, err := s.repository.FindByCode(code)
+func (s *bookStockService) BulkCreate(actor dto.UserData, req dto.BookStockBulkCreateRequest) (*dto.BookStockBulkCreateResponse, error) {
+	// Check if book exists
+	book, err := s.bookRepo.FindByID(req.BookID)
 	if err != nil {
-		return nil, errors.New("book stock not found")
+		return nil, ErrBookNotFound
 	}
 
-	// Check if book exists when book_id is provided
-	if req.BookID != uuid.Nil {
-		book, err := s.bookRepo.FindByID(req.BookID)
-		if err != nil {
-			return nil, errors.New("book not found")
-		}
-		bookStock.BookID = book.ID
-		bookStock.Book = *book
+	branch, err := s.resolveBranch(actor, req.BranchID)
+	if err != nil {
+		return nil, err
 	}
 
-	// Update fields if provided
+	status := model.StatusAvailable
 	if req.Status != "" {
-		bookStock.Status = req.Status
+		status = req.Status
 	}
 
-	if err := s.repository.Update(bookStock); err != nil {
+	codes, err := s.generateCodes(branchCode(req.Branch, branch), req.Quantity)
+	if err != nil {
 		return nil, err
 	}
 
-	response := mapToBookStockResponse(bookStock)
-	return &response, nil
+	bookStocks := make([]model.BookStock, 0, len(codes))
+	for _, code := range codes {
+		bookStock := model.BookStock{
+			Code:   code,
+			BookID: book.ID,
+			Status: status,
+			Price:  req.Price,
+		}
+		if branch != nil {
+			bookStock.OwnerBranchID = &branch.ID
+			bookStock.CurrentBranchID = &branch.ID
+		}
+		bookStocks = append(bookStocks, bookStock)
+	}
+
+	// All copies are inserted in a single statement, so either all or none are created
+	if err := s.repository.CreateBatch(bookStocks); err != nil {
+		return nil, err
+	}
+
+	return &dto.BookStockBulkCreateResponse{
+		BookID: book.ID,
+		Codes:  codes,
+	}, nil
+}
+
+func (s *bookStockService) Update(code string, actor dto.UserData, req dto.BookStockUpdateRequest) (*dto.BookStockResponse, error) {
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		// Lock the copy so a concurrent checkout or transfer cannot change it underneath us
+		bookStock, err := repos.BookStocks.FindByCodeForUpdate(code)
+		if err != nil {
+			return ErrBookStockNotFound
+		}
+
+		if err := checkBranchAccess(actor, bookStock.CurrentBranchID); err != nil {
+			return err
+		}
+
+		// Only the columns the request changes are written
+		fields := map[string]interface{}{}
+
+		// Check if book exists when book_id is provided
+		if req.BookID != uuid.Nil {
+			book, err := s.bookRepo.FindByID(req.BookID)
+			if err != nil {
+				return ErrBookNotFound
+			}
+			fields["book_id"] = book.ID
+		}
+
+		// Copies without a location are placed at their new owner; afterwards only transfers move them
+		if req.OwnerBranchID != nil {
+			if err := checkBranchAccess(actor, req.OwnerBranchID); err != nil {
+				return err
+			}
+			branch, err := s.branchRepo.FindByID(*req.OwnerBranchID)
+			if err != nil {
+				return errors.New("branch not found")
+			}
+			fields["owner_branch_id"] = branch.ID
+			if bookStock.CurrentBranchID == nil {
+				fields["current_branch_id"] = branch.ID
+			}
+		}
+
+		if req.Price != nil {
+			fields["price"] = *req.Price
+		}
+
+		// Status changes must follow the transition table
+		if req.Status != "" && req.Status != bookStock.Status {
+			if err := transitionBookStock(repos.BookStocks, bookStock, req.Status, model.StockActionUpdate, req.Reason, &actor.ID); err != nil {
+				return err
+			}
+		}
+
+		if len(fields) == 0 {
+			return nil
+		}
+		return repos.BookStocks.UpdateFields(code, fields)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByCode(code)
 }
 
-func (s *bookStockService) Delete(code string) error {
+func (s *bookStockService) Delete(code string, actor dto.UserData) error {
 	// Check if book stock exists
-	_, err := s.repository.FindByCode(code)
+	bookStock, err := s.repository.FindByCode(code)
 	if err != nil {
-		return errors.New("book stock not found")
+		return ErrBookStockNotFound
+	}
+
+	if err := checkBranchAccess(actor, bookStock.CurrentBranchID); err != nil {
+		return err
 	}
 
 	// Delete book stock
 	return s.repository.Delete(code)
 }
 
-func (s *bookStockService) UpdateStatus(code string, req dto.BookStockStatusUpdateRequest) (*dto.BookStockResponse, error) {
+func (s *bookStockService) UpdateStatus(code string, actor dto.UserData, req dto.BookStockStatusUpdateRequest) (*dto.BookStockResponse, error) {
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		bookStock, err := repos.BookStocks.FindByCodeForUpdate(code)
+		if err != nil {
+			return ErrBookStockNotFound
+		}
+
+		if err := checkBranchAccess(actor, bookStock.CurrentBranchID); err != nil {
+			return err
+		}
+
+		return transitionBookStock(repos.BookStocks, bookStock, req.Status, model.StockActionUpdate, req.Reason, &actor.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByCode(code)
+}
+
+func (s *bookStockService) MarkFound(code string, actor dto.UserData, req dto.BookStockFoundRequest) (*dto.BookStockResponse, error) {
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		bookStock, err := repos.BookStocks.FindByCodeForUpdate(code)
+		if err != nil {
+			return ErrBookStockNotFound
+		}
+
+		// Copies found away from their branch have to be transferred back by that branch
+		if err := checkBranchAccess(actor, bookStock.CurrentBranchID); err != nil {
+			return err
+		}
+
+		// Only lost copies can be found, and they go straight back on the shelf
+		return transitionBookStock(repos.BookStocks, bookStock, model.StatusAvailable, model.StockActionFound, req.Reason, &actor.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByCode(code)
+}
+
+func (s *bookStockService) GetStatusHistory(code string) ([]dto.BookStockStatusHistoryResponse, error) {
 	// Check if book stock exists
-	bookStock, err := s.repository.FindByCode(code)
+	if _, err := s.repository.FindByCode(code); err != nil {
+		return nil, ErrBookStockNotFound
+	}
+
+	histories, err := s.repository.FindStatusHistoryByCode(code)
 	if err != nil {
-		return nil, errors.New("book stock not found")
+		return nil, err
 	}
 
-	// Update status
-	if err := s.repository.UpdateStatus(code, req.Status); err != nil {
+	responses := make([]dto.BookStockStatusHistoryResponse, 0, len(histories))
+	for _, history := range histories {
+		responses = append(responses, dto.BookStockStatusHistoryResponse{
+			ID:         history.ID,
+			StockCode:  history.StockCode,
+			FromStatus: history.FromStatus,
+			ToStatus:   history.ToStatus,
+			Action:     history.Action,
+			Reason:     history.Reason,
+			UserID:     history.UserID,
+			CreatedAt:  history.CreatedAt,
+		})
+	}
+
+	return responses, nil
+}
+
+// resolveBranch loads the branch a new copy belongs to, defaulting to the actor's own branch
+func (s *bookStockService) resolveBranch(actor dto.UserData, branchID *uuid.UUID) (*model.Branch, error) {
+	branchID, err := resolveActorBranch(actor, branchID)
+	if err != nil {
 		return nil, err
 	}
+	if branchID == nil {
+		return nil, nil
+	}
 
-	response := mapToBookStockResponse(bookStock)
-	return &response, nil
+	branch, err := s.branchRepo.FindByID(*branchID)
+	if err != nil {
+		return nil, errors.New("branch not found")
+	}
+	return branch, nil
+}
+
+// branchCode picks the value of the {BRANCH} code token: the explicit one, else the branch code
+func branchCode(explicit string, branch *model.Branch) string {
+	if explicit == "" && branch != nil {
+		return branch.Code
+	}
+	return explicit
+}
+
+// generateCodes reserves count sequence numbers and renders them with the stock code pattern,
+// skipping any code that was already entered manually
+func (s *bookStockService) generateCodes(branch string, count int) ([]string, error) {
+	if count < 1 {
+		return nil, fmt.Errorf("%w: quantity must be at least 1", ErrInvalidCodePattern)
+	}
+	if branch == "" {
+		branch = s.defaultBranch
+	}
+
+	values := lib.CodePatternValues{Branch: branch, Time: time.Now()}
+	sequenceName := "book_stock:" + s.codePattern.Scope(values)
+
+	codes := make([]string, 0, count)
+	for attempt := 0; len(codes) < count && attempt < maxCodeGenerationAttempts; attempt++ {
+		missing := count - len(codes)
+		first, err := s.sequenceRepo.NextRange(sequenceName, missing)
+		if err != nil {
+			return nil, err
+		}
+
+		candidates := make([]string, 0, missing)
+		for i := 0; i < missing; i++ {
+			code := s.codePattern.Render(values, first+int64(i))
+			if len(code) > 50 {
+				return nil, fmt.Errorf("%w: generated code %s exceeds 50 characters", ErrInvalidCodePattern, code)
+			}
+			candidates = append(candidates, code)
+		}
+
+		existing, err := s.repository.FindByCodes(candidates)
+		if err != nil {
+			return nil, err
+		}
+		taken := make(map[string]bool, len(existing))
+		for _, bookStock := range existing {
+			taken[bookStock.Code] = true
+		}
+
+		for _, code := range candidates {
+			if !taken[code] {
+				codes = append(codes, code)
+			}
+		}
+	}
+
+	if len(codes) < count {
+		return nil, fmt.Errorf("%w: failed to generate unique stock codes", ErrInvalidCodePattern)
+	}
+
+	return codes, nil
 }
 
 // Helper function to map a BookStock entity to a BookStockResponse DTO
@@ -197,6 +518,14 @@ func mapToBookStockResponse(bookStock *model.BookStock) dto.BookStockResponse {
 		BookID: bookStock.BookID,
 		Status: bookStock.Status,
 		// BorrowedID: bookStock.BorrowedID,
+		OwnerBranchID:   bookStock.OwnerBranchID,
+		CurrentBranchID: bookStock.CurrentBranchID,
+		Price:           bookStock.Price,
+	}
+
+	if bookStock.CurrentBranch != nil {
+		branch := mapToBranchResponse(bookStock.CurrentBranch)
+		response.CurrentBranch = &branch
 	}
 
 	// if bookStock.BorrowedAt != nil {
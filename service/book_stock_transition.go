@@ -0,0 +1,103 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+
+	"github.com/google/uuid"
+)
+
+// bookStockTransitions lists, per action, the statuses a book stock may move to from each status.
+// Borrowed copies only become Available through a return and Lost or Damaged through a loss on
+// loan, Lost copies only leave Lost through Found, copies only travel between branches through
+// transfers and copies set aside for a hold only leave the hold shelf through a checkout or a
+// release; Withdrawn is final.
+var bookStockTransitions = map[string]map[string][]string{
+	model.StockActionUpdate: {
+		model.StatusAvailable: {model.StatusDamaged, model.StatusLost, model.StatusInRepair, model.StatusWithdrawn},
+		model.StatusDamaged:   {model.StatusInRepair, model.StatusWithdrawn, model.StatusLost},
+		model.StatusInRepair:  {model.StatusAvailable, model.StatusDamaged, model.StatusWithdrawn},
+		model.StatusInTransit: {model.StatusLost},
+	},
+	model.StockActionCheckout: {
+		model.StatusAvailable: {model.StatusBorrowed},
+		model.StatusOnHold:    {model.StatusBorrowed},
+	},
+	model.StockActionReturn: {
+		model.StatusBorrowed: {model.StatusAvailable, model.StatusOnHold},
+	},
+	model.StockActionFound: {
+		model.StatusLost: {model.StatusAvailable, model.StatusOnHold},
+	},
+	model.StockActionTransfer: {
+		model.StatusAvailable: {model.StatusInTransit},
+		model.StatusInTransit: {model.StatusAvailable},
+	},
+	model.StockActionHold: {
+		model.StatusOnHold: {model.StatusAvailable},
+	},
+	model.StockActionLoss: {
+		model.StatusBorrowed: {model.StatusLost, model.StatusDamaged},
+	},
+}
+
+// StockTransitionError is returned when a book stock status change is not allowed
+type StockTransitionError struct {
+	Code   string
+	From   string
+	To     string
+	Action string
+}
+
+func (e *StockTransitionError) Error() string {
+	return fmt.Sprintf("book stock %s cannot move from %s to %s via %s", e.Code, e.From, e.To, e.Action)
+}
+
+// CanTransitionBookStock reports whether the action may move a book stock between the statuses
+func CanTransitionBookStock(from, to, action string) bool {
+	for _, allowed := range bookStockTransitions[action][from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// transitionBookStock validates and applies a status change, recording it in the status history
+func transitionBookStock(repo repository.BookStockRepository, bookStock *model.BookStock, to, action, reason string, userID *uuid.UUID) error {
+	from := bookStock.Status
+	if !CanTransitionBookStock(from, to, action) {
+		return &StockTransitionError{Code: bookStock.Code, From: from, To: to, Action: action}
+	}
+
+	if err := repo.UpdateStatus(bookStock.Code, to); err != nil {
+		return errors.New("failed to update book stock status")
+	}
+
+	history := model.BookStockStatusHistory{
+		ID:         uuid.New(),
+		StockCode:  bookStock.Code,
+		FromStatus: from,
+		ToStatus:   to,
+		Action:     action,
+		Reason:     reason,
+		UserID:     userID,
+	}
+	if err := repo.CreateStatusHistory(&history); err != nil {
+		return err
+	}
+
+	bookStock.Status = to
+	return nil
+}
+
+// transitionBookStockByCode locks a book stock and applies a status change to it
+func transitionBookStockByCode(repo repository.BookStockRepository, code, to, action, reason string, userID *uuid.UUID) error {
+	bookStock, err := repo.FindByCodeForUpdate(code)
+	if err != nil {
+		return ErrBookStockNotFound
+	}
+	return transitionBookStock(repo, bookStock, to, action, reason, userID)
+}
@@ -0,0 +1,266 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ErrBatchRolledBack is returned when one copy of an all-or-nothing batch fails and none are processed
+var ErrBatchRolledBack = errors.New("batch rolled back")
+
+// Error reported for the other copies of an all-or-nothing batch that failed
+const batchRolledBackMessage = "not processed: another copy in the batch failed"
+
+// bulkItemFunc processes one copy of a batch inside a unit of work and returns the loan it touched
+type bulkItemFunc func(repos repository.Repositories, code string) (uuid.UUID, error)
+
+// BulkCheckout lends several copies to one customer. With AllOrNothing every copy is lent in one
+// database transaction and a single failure lends none; otherwise each copy is lent on its own.
+func (s *bookTransactionService) BulkCheckout(actor dto.UserData, req dto.BulkCheckoutRequest) (*dto.BulkCirculationResponse, error) {
+	customer, err := s.customerRepo.FindByID(req.CustomerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	results, err := s.runBulk(uniqueCodes(req.StockCodes), req.AllOrNothing, func(repos repository.Repositories, code string) (uuid.UUID, error) {
+		transaction, err := s.checkout(repos, actor, dto.BookTransactionCreateRequest{
+			StockCode:      code,
+			CustomerID:     customer.ID,
+			Override:       req.Override,
+			OverrideReason: req.OverrideReason,
+		})
+		if err != nil {
+			return uuid.Nil, err
+		}
+		return transaction.ID, nil
+	})
+
+	response := s.bulkResponse(results, req.AllOrNothing, actor, "Loan Receipt")
+	response.Receipt.CustomerCode = customer.Code
+	response.Receipt.CustomerName = customer.Name
+	return response, err
+}
+
+// BulkReturn takes several copies back, finding the open loan of each. With AllOrNothing every
+// copy is returned in one database transaction and a single failure returns none.
+func (s *bookTransactionService) BulkReturn(actor dto.UserData, req dto.BulkReturnRequest) (*dto.BulkCirculationResponse, error) {
+	results, err := s.runBulk(uniqueCodes(req.StockCodes), req.AllOrNothing, func(repos repository.Repositories, code string) (uuid.UUID, error) {
+		transaction, err := repos.BookTransactions.FindActiveByStockCode(code)
+		if err != nil {
+			return uuid.Nil, errors.New("no open loan for this copy")
+		}
+		if err := s.returnBook(repos, transaction.ID, actor, dto.BookTransactionReturnRequest{ReturnAt: req.ReturnAt}); err != nil {
+			return uuid.Nil, err
+		}
+		return transaction.ID, nil
+	})
+
+	response := s.bulkResponse(results, req.AllOrNothing, actor, "Return Receipt")
+
+	// Name the customer on the receipt when the whole stack was theirs
+	var customer *dto.CustomerResponse
+	for _, result := range response.Results {
+		if result.Transaction == nil || result.Transaction.Customer == nil {
+			continue
+		}
+		if customer != nil && customer.ID != result.Transaction.Customer.ID {
+			customer = nil
+			break
+		}
+		customer = result.Transaction.Customer
+	}
+	if customer != nil {
+		response.Receipt.CustomerCode = customer.Code
+		response.Receipt.CustomerName = customer.Name
+	}
+	return response, err
+}
+
+// runBulk processes the copies of a batch in code order, so batches sharing copies lock them in
+// the same order. It returns the loan or the error of every copy.
+func (s *bookTransactionService) runBulk(codes []string, allOrNothing bool, process bulkItemFunc) ([]bulkItemResult, error) {
+	ordered := append([]string(nil), codes...)
+	sort.Strings(ordered)
+
+	outcomes := make(map[string]bulkItemResult, len(codes))
+	if allOrNothing {
+		var failed string
+		err := s.uow.Do(func(repos repository.Repositories) error {
+			for _, code := range ordered {
+				id, err := process(repos, code)
+				if err != nil {
+					failed = code
+					return err
+				}
+				outcomes[code] = bulkItemResult{Code: code, TransactionID: id}
+			}
+			return nil
+		})
+		if err != nil {
+			results := make([]bulkItemResult, 0, len(codes))
+			for _, code := range codes {
+				result := bulkItemResult{Code: code, Err: errors.New(batchRolledBackMessage)}
+				if code == failed {
+					result.Err = err
+				}
+				results = append(results, result)
+			}
+			if failed == "" {
+				return results, err
+			}
+			return results, fmt.Errorf("%w: %s: %v", ErrBatchRolledBack, failed, err)
+		}
+	} else {
+		for _, code := range ordered {
+			var id uuid.UUID
+			err := s.uow.Do(func(repos repository.Repositories) error {
+				var err error
+				id, err = process(repos, code)
+				return err
+			})
+			outcomes[code] = bulkItemResult{Code: code, TransactionID: id, Err: err}
+		}
+	}
+
+	// Report in the order the copies were given
+	results := make([]bulkItemResult, 0, len(codes))
+	for _, code := range codes {
+		results = append(results, outcomes[code])
+	}
+	return results, nil
+}
+
+// bulkItemResult is the outcome of one copy of a batch
+type bulkItemResult struct {
+	Code          string
+	TransactionID uuid.UUID
+	Err           error
+}
+
+// bulkResponse loads the loans a batch touched and builds the per-copy results and the receipt
+func (s *bookTransactionService) bulkResponse(results []bulkItemResult, allOrNothing bool, actor dto.UserData, title string) *dto.BulkCirculationResponse {
+	response := &dto.BulkCirculationResponse{
+		AllOrNothing: allOrNothing,
+		Results:      make([]dto.BulkCirculationItemResult, 0, len(results)),
+		Receipt: dto.CirculationReceiptResponse{
+			Title:    title,
+			IssuedAt: time.Now(),
+			Items:    make([]dto.CirculationReceiptItem, 0, len(results)),
+			IssuedBy: actor.Name,
+		},
+	}
+
+	for _, result := range results {
+		item := dto.BulkCirculationItemResult{StockCode: result.Code}
+		if result.Err != nil {
+			item.Error = result.Err.Error()
+			response.Failed++
+			response.Results = append(response.Results, item)
+			continue
+		}
+
+		item.Success = true
+		response.Succeeded++
+		transaction, err := s.repository.FindByID(result.TransactionID)
+		if err != nil {
+			response.Results = append(response.Results, item)
+			continue
+		}
+		loan := mapToBookTransactionResponse(transaction)
+		item.Transaction = &loan
+		response.Results = append(response.Results, item)
+
+		receiptItem := dto.CirculationReceiptItem{
+			StockCode:    transaction.StockCode,
+			BookTitle:    transaction.Book.Title,
+			CustomerCode: transaction.Customer.Code,
+			DueDate:      transaction.DueDate,
+			ReturnAt:     transaction.ReturnAt,
+		}
+		if transaction.ReturnAt != nil {
+			for _, charge := range transaction.Charges {
+				if charge.Type == model.ChargeTypeLateFee {
+					receiptItem.LateFee += charge.Total
+				}
+			}
+			receiptItem.LateFee = roundMoney(receiptItem.LateFee)
+			response.Receipt.TotalLateFees += receiptItem.LateFee
+		}
+		response.Receipt.Items = append(response.Receipt.Items, receiptItem)
+	}
+	response.Receipt.TotalLateFees = roundMoney(response.Receipt.TotalLateFees)
+
+	return response
+}
+
+// CirculationReceiptPDF renders the combined receipt of a bulk checkout or return
+func (s *bookTransactionService) CirculationReceiptPDF(response *dto.BulkCirculationResponse) ([]byte, error) {
+	receipt := response.Receipt
+	lines := []lib.ReceiptLine{
+		{Label: "Date", Value: receipt.IssuedAt.Format("02 Jan 2006 15:04")},
+	}
+	if receipt.CustomerName != "" {
+		lines = append(lines, lib.ReceiptLine{Label: "Customer", Value: fmt.Sprintf("%s (%s)", receipt.CustomerName, receipt.CustomerCode)})
+	}
+
+	for _, item := range receipt.Items {
+		lines = append(lines, lib.ReceiptLine{Label: fmt.Sprintf("%s [%s]", item.BookTitle, item.StockCode)})
+		if item.ReturnAt != nil {
+			lines = append(lines, lib.ReceiptLine{Label: "Returned", Value: item.ReturnAt.Format("02 Jan 2006")})
+			if item.LateFee > 0 {
+				lines = append(lines, lib.ReceiptLine{Label: "Late fee", Value: formatMoney(item.LateFee)})
+			}
+		} else {
+			lines = append(lines, lib.ReceiptLine{Label: "Due", Value: item.DueDate.Format("02 Jan 2006")})
+		}
+	}
+
+	// Copies the desk could not process are listed so they can be dealt with by hand
+	var failed []dto.BulkCirculationItemResult
+	for _, result := range response.Results {
+		if !result.Success {
+			failed = append(failed, result)
+		}
+	}
+	if len(failed) > 0 {
+		lines = append(lines, lib.ReceiptLine{Label: "Not processed"})
+		for _, result := range failed {
+			lines = append(lines, lib.ReceiptLine{Label: result.StockCode, Value: result.Error})
+		}
+	}
+
+	lines = append(lines, lib.ReceiptLine{Label: "Summary"})
+	lines = append(lines, lib.ReceiptLine{Label: "Items", Value: fmt.Sprintf("%d", len(receipt.Items))})
+	if receipt.TotalLateFees > 0 {
+		lines = append(lines, lib.ReceiptLine{Label: "Total late fees", Value: formatMoney(receipt.TotalLateFees)})
+	}
+	lines = append(lines, lib.ReceiptLine{Label: "Issued by", Value: receipt.IssuedBy})
+
+	return lib.GenerateReceiptPDF(lib.Receipt{
+		Title:  receipt.Title,
+		Lines:  lines,
+		Footer: "Please keep this receipt for your records.",
+	})
+}
+
+// uniqueCodes drops repeated stock codes, keeping the first occurrence
+func uniqueCodes(codes []string) []string {
+	seen := make(map[string]bool, len(codes))
+	unique := make([]string, 0, len(codes))
+	for _, code := range codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		unique = append(unique, code)
+	}
+	return unique
+}
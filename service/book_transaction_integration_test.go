@@ -0,0 +1,428 @@
+//go:build integration
+
+package service
+
+import (
+	"go-gin-simple-api/config"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// These tests run the real gorm repositories and unit of work against PostgreSQL. They need a
+// database they may create tables in:
+//
+//	TEST_DATABASE_DSN="host=localhost user=postgres password=postgres dbname=library_test sslmode=disable" \
+//		go test -tags integration ./service/...
+
+// statement is one SQL statement run through gorm
+type statement struct {
+	sql  string
+	inTx bool
+}
+
+// statementRecorder keeps the statements gorm runs while it is recording
+type statementRecorder struct {
+	mu         sync.Mutex
+	recording  bool
+	statements []statement
+}
+
+func (r *statementRecorder) record(db *gorm.DB) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.recording {
+		return
+	}
+	// Inside a unit of work gorm runs statements on the *sql.Tx instead of the pool
+	_, inTx := db.Statement.ConnPool.(gorm.TxCommitter)
+	r.statements = append(r.statements, statement{sql: db.Statement.SQL.String(), inTx: inTx})
+}
+
+func (r *statementRecorder) start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recording = true
+	r.statements = nil
+}
+
+func (r *statementRecorder) stop() []statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.recording = false
+	return r.statements
+}
+
+type integrationFixture struct {
+	db       *gorm.DB
+	recorder *statementRecorder
+	svc      BookTransactionService
+	book     model.Book
+	branch   model.Branch
+	// Staff of the fixture's branch
+	staff dto.UserData
+}
+
+func newIntegrationFixture(t *testing.T) *integrationFixture {
+	t.Helper()
+
+	dsn := os.Getenv("TEST_DATABASE_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_DSN is not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatalf("connecting to the database: %v", err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { sqlDB.Close() })
+
+	if err := db.Exec(`CREATE EXTENSION IF NOT EXISTS "uuid-ossp"`).Error; err != nil {
+		t.Fatalf("creating the uuid extension: %v", err)
+	}
+	if err := config.Migrate(db); err != nil {
+		t.Fatalf("migrating: %v", err)
+	}
+
+	recorder := &statementRecorder{}
+	callbacks := db.Callback()
+	for _, err := range []error{
+		callbacks.Create().After("gorm:create").Register("test:record", recorder.record),
+		callbacks.Query().After("gorm:query").Register("test:record", recorder.record),
+		callbacks.Update().After("gorm:update").Register("test:record", recorder.record),
+		callbacks.Delete().After("gorm:delete").Register("test:record", recorder.record),
+		callbacks.Raw().After("gorm:raw").Register("test:record", recorder.record),
+	} {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Every test works on its own rows, so runs do not depend on what is in the database
+	suffix := strings.ToUpper(uuid.NewString()[:8])
+	f := &integrationFixture{
+		db:       db,
+		recorder: recorder,
+		book:     model.Book{ID: uuid.New(), Title: "Integration " + suffix},
+		branch:   model.Branch{ID: uuid.New(), Code: "T" + suffix, Name: "Branch " + suffix},
+	}
+	if err := db.Create(&f.book).Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Create(&f.branch).Error; err != nil {
+		t.Fatal(err)
+	}
+	f.staff = dto.UserData{ID: uuid.New(), Role: "user", BranchID: &f.branch.ID}
+
+	f.svc = NewBookTransactionService(
+		repository.NewBookTransactionRepository(db),
+		repository.NewBookRepository(db),
+		repository.NewBookStockRepository(db),
+		repository.NewCustomerRepository(db),
+		repository.NewUnitOfWork(db),
+		3, alwaysOpen{}, EligibilityRules{}, 0,
+	)
+	return f
+}
+
+func (f *integrationFixture) createStock(t *testing.T, branchID *uuid.UUID) string {
+	t.Helper()
+	code := "IT-" + strings.ToUpper(uuid.NewString()[:12])
+	bookStock := model.BookStock{
+		Code:            code,
+		BookID:          f.book.ID,
+		Status:          model.StatusAvailable,
+		OwnerBranchID:   branchID,
+		CurrentBranchID: branchID,
+	}
+	if err := f.db.Create(&bookStock).Error; err != nil {
+		t.Fatal(err)
+	}
+	return code
+}
+
+func (f *integrationFixture) createCustomer(t *testing.T) uuid.UUID {
+	t.Helper()
+	customer := model.Customer{
+		ID:     uuid.New(),
+		Code:   "IT-" + strings.ToUpper(uuid.NewString()[:12]),
+		Name:   "Integration Customer",
+		Status: model.CustomerStatusActive,
+	}
+	if err := f.db.Create(&customer).Error; err != nil {
+		t.Fatal(err)
+	}
+	return customer.ID
+}
+
+func (f *integrationFixture) checkout(t *testing.T, stockCode string) uuid.UUID {
+	t.Helper()
+	loan, err := f.svc.Create(f.staff, dto.BookTransactionCreateRequest{
+		StockCode:  stockCode,
+		CustomerID: f.createCustomer(t),
+	})
+	if err != nil {
+		t.Fatalf("checkout of %s: %v", stockCode, err)
+	}
+	return loan.ID
+}
+
+func (f *integrationFixture) stock(t *testing.T, code string) model.BookStock {
+	t.Helper()
+	var bookStock model.BookStock
+	if err := f.db.First(&bookStock, "code = ?", code).Error; err != nil {
+		t.Fatal(err)
+	}
+	return bookStock
+}
+
+func (f *integrationFixture) loan(t *testing.T, id uuid.UUID) model.BookTransaction {
+	t.Helper()
+	var loan model.BookTransaction
+	if err := f.db.First(&loan, "id = ?", id).Error; err != nil {
+		t.Fatal(err)
+	}
+	return loan
+}
+
+// assertLockedInTransaction checks that the rows of the given tables were read with
+// SELECT ... FOR UPDATE and that those reads and every write ran inside the unit of work
+func assertLockedInTransaction(t *testing.T, statements []statement, tables ...string) {
+	t.Helper()
+	for _, table := range tables {
+		locked := false
+		for _, stmt := range statements {
+			if strings.HasPrefix(stmt.sql, "SELECT") && strings.Contains(stmt.sql, `FROM "`+table+`"`) && strings.Contains(stmt.sql, "FOR UPDATE") {
+				locked = true
+				if !stmt.inTx {
+					t.Errorf("%s was locked outside a transaction: %s", table, stmt.sql)
+				}
+			}
+		}
+		if !locked {
+			t.Errorf("expected %s to be read with FOR UPDATE", table)
+		}
+	}
+
+	for _, stmt := range statements {
+		if strings.HasPrefix(stmt.sql, "SELECT") {
+			continue
+		}
+		if !stmt.inTx {
+			t.Errorf("expected every write to use the unit of work's transaction, got: %s", stmt.sql)
+		}
+	}
+}
+
+func TestIntegrationCheckoutLendsACopyOnlyOnce(t *testing.T) {
+	const desks = 10
+
+	f := newIntegrationFixture(t)
+	code := f.createStock(t, &f.branch.ID)
+
+	customerIDs := make([]uuid.UUID, desks)
+	for i := range customerIDs {
+		customerIDs[i] = f.createCustomer(t)
+	}
+
+	f.recorder.start()
+	start := make(chan struct{})
+	errs := make([]error, desks)
+	var wg sync.WaitGroup
+	for i := 0; i < desks; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			_, errs[i] = f.svc.Create(f.staff, dto.BookTransactionCreateRequest{
+				StockCode:  code,
+				CustomerID: customerIDs[i],
+			})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+	assertLockedInTransaction(t, f.recorder.stop(), "book_stocks", "customers")
+
+	var lent int
+	for _, err := range errs {
+		if err == nil {
+			lent++
+		} else if err.Error() != "book stock is not available" {
+			t.Errorf("unexpected checkout error: %v", err)
+		}
+	}
+	if lent != 1 {
+		t.Fatalf("expected exactly one successful checkout, got %d", lent)
+	}
+
+	var loans int64
+	if err := f.db.Model(&model.BookTransaction{}).Where("stock_code = ?", code).Count(&loans).Error; err != nil {
+		t.Fatal(err)
+	}
+	if loans != 1 {
+		t.Errorf("expected exactly one loan, got %d", loans)
+	}
+	if status := f.stock(t, code).Status; status != model.StatusBorrowed {
+		t.Errorf("expected the copy to be %s, got %s", model.StatusBorrowed, status)
+	}
+}
+
+func TestIntegrationReturnWaitsForTheCopyLock(t *testing.T) {
+	f := newIntegrationFixture(t)
+	code := f.createStock(t, &f.branch.ID)
+	id := f.checkout(t, code)
+
+	// Another transaction holds the copy, as a concurrent checkout or transfer would
+	tx := f.db.Begin()
+	var held model.BookStock
+	if err := tx.Raw(`SELECT * FROM book_stocks WHERE code = ? FOR UPDATE`, code).Scan(&held).Error; err != nil {
+		tx.Rollback()
+		t.Fatal(err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := f.svc.ReturnBook(id, f.staff, dto.BookTransactionReturnRequest{})
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		tx.Rollback()
+		t.Fatalf("expected the return to wait for the copy's lock, it finished with %v", err)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	tx.Rollback()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("return: %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("return did not finish after the lock was released")
+	}
+}
+
+func TestIntegrationReturnMovesTheCopyToTheReturningBranch(t *testing.T) {
+	f := newIntegrationFixture(t)
+	code := f.createStock(t, &f.branch.ID)
+	id := f.checkout(t, code)
+
+	// The copy comes back at another branch
+	other := model.Branch{ID: uuid.New(), Code: "R" + strings.ToUpper(uuid.NewString()[:8]), Name: "Returning branch"}
+	if err := f.db.Create(&other).Error; err != nil {
+		t.Fatal(err)
+	}
+	staff := dto.UserData{ID: uuid.New(), Role: "user", BranchID: &other.ID}
+
+	f.recorder.start()
+	_, err := f.svc.ReturnBook(id, staff, dto.BookTransactionReturnRequest{})
+	statements := f.recorder.stop()
+	if err != nil {
+		t.Fatalf("return: %v", err)
+	}
+	assertLockedInTransaction(t, statements, "book_transactions", "book_stocks")
+
+	if loan := f.loan(t, id); loan.Status != model.StatusBTReturned || loan.ReturnAt == nil {
+		t.Errorf("expected the loan to be %s with a return date, got %s", model.StatusBTReturned, loan.Status)
+	}
+	bookStock := f.stock(t, code)
+	if bookStock.Status != model.StatusAvailable {
+		t.Errorf("expected the copy to be %s, got %s", model.StatusAvailable, bookStock.Status)
+	}
+	if bookStock.CurrentBranchID == nil || *bookStock.CurrentBranchID != other.ID {
+		t.Errorf("expected the copy to stay at the returning branch %s, got %v", other.ID, bookStock.CurrentBranchID)
+	}
+}
+
+func TestIntegrationUpdateSwapsTheCopyOfALoan(t *testing.T) {
+	f := newIntegrationFixture(t)
+	oldCode := f.createStock(t, &f.branch.ID)
+	newCode := f.createStock(t, &f.branch.ID)
+	id := f.checkout(t, oldCode)
+
+	f.recorder.start()
+	_, err := f.svc.Update(id, f.staff, dto.BookTransactionUpdateRequest{StockCode: newCode})
+	statements := f.recorder.stop()
+	if err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	assertLockedInTransaction(t, statements, "book_transactions", "book_stocks")
+
+	loan := f.loan(t, id)
+	if loan.StockCode != newCode {
+		t.Errorf("expected the loan to be on %s, got %s", newCode, loan.StockCode)
+	}
+	if loan.BranchID == nil || *loan.BranchID != f.branch.ID {
+		t.Errorf("expected the loan to be lent from %s, got %v", f.branch.ID, loan.BranchID)
+	}
+	if status := f.stock(t, oldCode).Status; status != model.StatusAvailable {
+		t.Errorf("expected the old copy to be %s, got %s", model.StatusAvailable, status)
+	}
+	if status := f.stock(t, newCode).Status; status != model.StatusBorrowed {
+		t.Errorf("expected the new copy to be %s, got %s", model.StatusBorrowed, status)
+	}
+}
+
+func TestIntegrationUpdateRejectsACopyOfAnotherBranch(t *testing.T) {
+	f := newIntegrationFixture(t)
+	oldCode := f.createStock(t, &f.branch.ID)
+	id := f.checkout(t, oldCode)
+
+	other := model.Branch{ID: uuid.New(), Code: "O" + strings.ToUpper(uuid.NewString()[:8]), Name: "Other branch"}
+	if err := f.db.Create(&other).Error; err != nil {
+		t.Fatal(err)
+	}
+	newCode := f.createStock(t, &other.ID)
+
+	if _, err := f.svc.Update(id, f.staff, dto.BookTransactionUpdateRequest{StockCode: newCode}); err == nil {
+		t.Fatal("expected swapping to a copy of another branch to fail")
+	}
+
+	// Nothing was written
+	if loan := f.loan(t, id); loan.StockCode != oldCode {
+		t.Errorf("expected the loan to stay on %s, got %s", oldCode, loan.StockCode)
+	}
+	if status := f.stock(t, oldCode).Status; status != model.StatusBorrowed {
+		t.Errorf("expected the old copy to stay %s, got %s", model.StatusBorrowed, status)
+	}
+	if status := f.stock(t, newCode).Status; status != model.StatusAvailable {
+		t.Errorf("expected the other branch's copy to stay %s, got %s", model.StatusAvailable, status)
+	}
+}
+
+func TestIntegrationDeleteReleasesTheCopy(t *testing.T) {
+	f := newIntegrationFixture(t)
+	code := f.createStock(t, &f.branch.ID)
+	id := f.checkout(t, code)
+
+	f.recorder.start()
+	err := f.svc.Delete(id)
+	statements := f.recorder.stop()
+	if err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	assertLockedInTransaction(t, statements, "book_transactions", "book_stocks")
+
+	if err := f.db.First(&model.BookTransaction{}, "id = ?", id).Error; err != gorm.ErrRecordNotFound {
+		t.Errorf("expected the loan to be gone, got %v", err)
+	}
+	if status := f.stock(t, code).Status; status != model.StatusAvailable {
+		t.Errorf("expected the copy to be %s, got %s", model.StatusAvailable, status)
+	}
+}
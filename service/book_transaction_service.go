@@ -2,10 +2,13 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"go-gin-simple-api/dto"
 	"go-gin-simple-api/lib"
 	"go-gin-simple-api/model"
 	"go-gin-simple-api/repository"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -14,22 +17,48 @@ import (
 type BookTransactionService interface {
 	GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.BookTransactionResponse], error)
 	GetByID(id uuid.UUID) (*dto.BookTransactionResponse, error)
-	GetByCustomerID(customerID uuid.UUID) ([]dto.BookTransactionResponse, error)
+	GetByCustomerID(actor dto.UserData, customerID uuid.UUID) ([]dto.BookTransactionResponse, error)
 	GetByBookID(bookID uuid.UUID) ([]dto.BookTransactionResponse, error)
 	GetByStockCode(stockCode string) ([]dto.BookTransactionResponse, error)
-	Create(req dto.BookTransactionCreateRequest) (*dto.BookTransactionResponse, error)
-	Update(id uuid.UUID, req dto.BookTransactionUpdateRequest) (*dto.BookTransactionResponse, error)
+	Create(actor dto.UserData, req dto.BookTransactionCreateRequest) (*dto.BookTransactionResponse, error)
+	Update(id uuid.UUID, actor dto.UserData, req dto.BookTransactionUpdateRequest) (*dto.BookTransactionResponse, error)
 	Delete(id uuid.UUID) error
-	UpdateStatus(id uuid.UUID, req dto.BookTransactionStatusUpdateRequest) (*dto.BookTransactionResponse, error)
-	ReturnBook(id uuid.UUID, req dto.BookTransactionReturnRequest) (*dto.BookTransactionResponse, error)
+	UpdateStatus(id uuid.UUID, actor dto.UserData, req dto.BookTransactionStatusUpdateRequest) (*dto.BookTransactionResponse, error)
+	Correct(id uuid.UUID, actor dto.UserData, req dto.BookTransactionCorrectionRequest) (*dto.BookTransactionResponse, error)
+	GetEvents(id uuid.UUID) ([]dto.BookTransactionEventResponse, error)
+	ReturnBook(id uuid.UUID, actor dto.UserData, req dto.BookTransactionReturnRequest) (*dto.BookTransactionResponse, error)
+	ReportLoss(id uuid.UUID, actor dto.UserData, req dto.BookTransactionLossRequest) (*dto.BookTransactionResponse, error)
+	BulkCheckout(actor dto.UserData, req dto.BulkCheckoutRequest) (*dto.BulkCirculationResponse, error)
+	BulkReturn(actor dto.UserData, req dto.BulkReturnRequest) (*dto.BulkCirculationResponse, error)
+	CirculationReceiptPDF(response *dto.BulkCirculationResponse) ([]byte, error)
 	GetOverdueTransactions() ([]dto.BookTransactionResponse, error)
+	MarkOverdue() (int64, error)
+	Renew(id uuid.UUID, actor dto.UserData) (*dto.BookTransactionResponse, error)
+	GetRenewals(id uuid.UUID) ([]dto.LoanRenewalResponse, error)
+	CheckEligibility(customerID uuid.UUID, stockCode string) (*dto.EligibilityResponse, error)
+	GetEligibilityOverrides(customerID uuid.UUID) ([]dto.EligibilityOverrideResponse, error)
 }
 
+// ErrRenewalNotAllowed is returned when a loan cannot be renewed
+var ErrRenewalNotAllowed = errors.New("loan cannot be renewed")
+
+// ErrLoanClosed is returned when an operation needs an open loan
+var ErrLoanClosed = errors.New("loan is closed")
+
 type bookTransactionService struct {
 	repository    repository.BookTransactionRepository
 	bookRepo      repository.BookRepository
 	bookStockRepo repository.BookStockRepository
 	customerRepo  repository.CustomerRepository
+	uow           repository.UnitOfWork
+	// Days a customer has to pick up a returned copy set aside for their hold
+	holdPickupDays int
+	// Opening days of each branch, which due dates fall on and late fees may leave out
+	calendar Calendar
+	// Checks a customer must pass before borrowing
+	eligibilityRules EligibilityRules
+	// Added to the copy's price when charging for a lost or damaged copy
+	processingFee float64
 }
 
 func NewBookTransactionService(
@@ -37,12 +66,22 @@ func NewBookTransactionService(
 	bookRepo repository.BookRepository,
 	bookStockRepo repository.BookStockRepository,
 	customerRepo repository.CustomerRepository,
+	uow repository.UnitOfWork,
+	holdPickupDays int,
+	calendar Calendar,
+	eligibilityRules EligibilityRules,
+	processingFee float64,
 ) BookTransactionService {
 	return &bookTransactionService{
-		repository:    repository,
-		bookRepo:      bookRepo,
-		bookStockRepo: bookStockRepo,
-		customerRepo:  customerRepo,
+		repository:       repository,
+		bookRepo:         bookRepo,
+		bookStockRepo:    bookStockRepo,
+		customerRepo:     customerRepo,
+		uow:              uow,
+		holdPickupDays:   holdPickupDays,
+		calendar:         calendar,
+		eligibilityRules: eligibilityRules,
+		processingFee:    processingFee,
 	}
 }
 
@@ -87,7 +126,11 @@ func (s *bookTransactionService) GetByID(id uuid.UUID) (*dto.BookTransactionResp
 	return &response, nil
 }
 
-func (s *bookTransactionService) GetByCustomerID(customerID uuid.UUID) ([]dto.BookTransactionResponse, error) {
+func (s *bookTransactionService) GetByCustomerID(actor dto.UserData, customerID uuid.UUID) ([]dto.BookTransactionResponse, error) {
+	if err := CheckCustomerAccess(actor, customerID); err != nil {
+		return nil, err
+	}
+
 	transactions, err := s.repository.FindByCustomerID(customerID)
 	if err != nil {
 		return nil, err
@@ -129,253 +172,915 @@ func (s *bookTransactionService) GetByStockCode(stockCode string) ([]dto.BookTra
 	return responses, nil
 }
 
-func (s *bookTransactionService) Create(req dto.BookTransactionCreateRequest) (*dto.BookTransactionResponse, error) {
-	// Check if book exists
-	// book, err := s.bookRepo.FindByID(req.BookID)
-	// if err != nil {
-	// 	return nil, errors.New("book not found")
-	// }
+func (s *bookTransactionService) Create(actor dto.UserData, req dto.BookTransactionCreateRequest) (*dto.BookTransactionResponse, error) {
+	var transaction *model.BookTransaction
+
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		transaction, err = s.checkout(repos, actor, req)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapToBookTransactionResponse(transaction)
+	return &response, nil
+}
 
-	// Check if book stock exists
-	bookStock, err := s.bookStockRepo.FindByCode(req.StockCode)
+// checkout lends a copy to a customer inside a unit of work
+func (s *bookTransactionService) checkout(repos repository.Repositories, actor dto.UserData, req dto.BookTransactionCreateRequest) (*model.BookTransaction, error) {
+	// Lock the copy so concurrent checkouts of it wait for this one and then see it Borrowed
+	bookStock, err := repos.BookStocks.FindByCodeForUpdate(req.StockCode)
 	if err != nil {
-		return nil, errors.New("book stock not found")
+		return nil, ErrBookStockNotFound
 	}
 
 	// Check if book stock is available
-	if bookStock.Status != model.StatusAvailable {
+	if !CanTransitionBookStock(bookStock.Status, model.StatusBorrowed, model.StockActionCheckout) {
 		return nil, errors.New("book stock is not available")
 	}
 
-	// Check if customer exists
-	customer, err := s.customerRepo.FindByID(req.CustomerID)
+	// Branch staff can only lend copies located at their branch
+	if err := checkBranchAccess(actor, bookStock.CurrentBranchID); err != nil {
+		return nil, err
+	}
+
+	// Check if customer exists, locking it so concurrent checkouts count each other's loans
+	customer, err := repos.Customers.FindByIDForUpdate(req.CustomerID)
 	if err != nil {
 		return nil, errors.New("customer not found")
 	}
+	if customer.AnonymizedAt != nil {
+		return nil, errors.New("customer is anonymized and can no longer borrow")
+	}
+
+	hold, err := holdForCheckout(repos, bookStock, customer.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := s.bookRepo.FindByID(bookStock.BookID)
+	if err != nil {
+		return nil, ErrBookNotFound
+	}
+
+	// Loan terms depend on who borrows what
+	policy, err := resolveLoanPolicy(repos.LoanPolicies, customer, book)
+	if err != nil {
+		return nil, err
+	}
 
 	now := time.Now()
+
+	// Staff may lend despite failed checks, but only deliberately and on the record
+	reasons, err := evaluateEligibility(repos, s.eligibilityRules, customer, policy, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(reasons) > 0 && !req.Override {
+		return nil, &EligibilityError{Reasons: reasons}
+	}
+
+	// Loans fall due on a day the lending branch is open
+	dueDate, err := nextOpenDay(s.calendar, bookStock.CurrentBranchID, now.AddDate(0, 0, policy.LoanPeriodDays))
+	if err != nil {
+		return nil, err
+	}
+
 	transaction := model.BookTransaction{
-		ID:         uuid.New(),
-		BookID:     bookStock.BookID,
-		StockCode:  req.StockCode,
-		CustomerID: customer.ID,
-		DueDate:    time.Now().AddDate(0, 0, 7),
-		Status:     req.Status,
-		BorrowedAt: &now,
+		ID:             uuid.New(),
+		BookID:         bookStock.BookID,
+		StockCode:      req.StockCode,
+		CustomerID:     customer.ID,
+		BranchID:       bookStock.CurrentBranchID,
+		DueDate:        dueDate,
+		BorrowedAt:     &now,
+		LoanPeriodDays: policy.LoanPeriodDays,
+		DailyLateFee:   policy.DailyLateFee,
+		MaxRenewals:    policy.MaxRenewals,
+		// Late fee terms
+		GracePeriodDays: policy.GracePeriodDays,
+		MaxLateFee:      policy.MaxLateFee,
+		SkipClosedDays:  policy.SkipClosedDays,
+	}
+	if policy.ID != uuid.Nil {
+		transaction.LoanPolicyID = &policy.ID
 	}
 
-	if err := s.repository.Create(&transaction); err != nil {
+	if err := transitionBookTransaction(repos.BookTransactions, &transaction, model.StatusBTBorrowed, model.LoanActionCheckout, "", &actor.ID); err != nil {
+		return nil, err
+	}
+
+	if err := repos.BookTransactions.Create(&transaction); err != nil {
+		return nil, err
+	}
+
+	if len(reasons) > 0 {
+		codes := make([]string, 0, len(reasons))
+		for _, reason := range reasons {
+			codes = append(codes, reason.Code)
+		}
+		override := model.EligibilityOverride{
+			ID:                uuid.New(),
+			BookTransactionID: transaction.ID,
+			CustomerID:        customer.ID,
+			ReasonCodes:       strings.Join(codes, ","),
+			Reason:            req.OverrideReason,
+			UserID:            actor.ID,
+		}
+		if err := repos.BookTransactions.CreateEligibilityOverride(&override); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := fulfilHold(repos, hold, now); err != nil {
 		return nil, err
 	}
 
 	// Update book stock status to borrowed
-	if err := s.bookStockRepo.UpdateStatus(req.StockCode, model.StatusBorrowed); err != nil {
-		return nil, errors.New("failed to update book stock status")
+	if err := transitionBookStock(repos.BookStocks, bookStock, model.StatusBorrowed, model.StockActionCheckout, "", &actor.ID); err != nil {
+		return nil, err
 	}
 
-	response := mapToBookTransactionResponse(&transaction)
-	return &response, nil
+	return &transaction, nil
 }
 
-func (s *bookTransactionService) Update(id uuid.UUID, req dto.BookTransactionUpdateRequest) (*dto.BookTransactionResponse, error) {
-	// Check if transaction exists
-	transaction, err := s.repository.FindByID(id)
-	if err != nil {
-		return nil, errors.New("transaction not found")
+// holdForCheckout returns the hold that lending the copy to the customer fulfils, if any
+func holdForCheckout(repos repository.Repositories, bookStock *model.BookStock, customerID uuid.UUID) (*model.Hold, error) {
+	// A copy on the hold shelf can only be borrowed by the customer it was set aside for
+	if bookStock.Status == model.StatusOnHold {
+		hold, err := repos.Holds.FindReadyByStockCodeForUpdate(bookStock.Code)
+		if err != nil || hold.CustomerID != customerID {
+			return nil, errors.New("book stock is on hold for another customer")
+		}
+		return hold, nil
+	}
+
+	// Borrowing any copy of the title satisfies the customer's place in the queue
+	if pending, err := repos.Holds.FindActiveByCustomerAndBook(customerID, bookStock.BookID); err == nil && pending.Status == model.StatusHoldPending {
+		return pending, nil
+	}
+	return nil, nil
+}
+
+// fulfilHold closes the hold a checkout satisfied
+func fulfilHold(repos repository.Repositories, hold *model.Hold, now time.Time) error {
+	if hold == nil {
+		return nil
+	}
+	hold.Status = model.StatusHoldFulfilled
+	hold.ClosedAt = &now
+	return repos.Holds.Update(hold)
+}
+
+// takeBackCopy passes a returned copy to the next customer waiting for the title, or puts it
+// back on the shelf. Copies may be returned at any branch and stay where they were returned.
+func (s *bookTransactionService) takeBackCopy(repos repository.Repositories, bookStock *model.BookStock, actor dto.UserData, reason string) error {
+	if _, err := releaseCopyToQueue(repos, bookStock, model.StockActionReturn, reason, &actor.ID, s.holdPickupDays); err != nil {
+		return err
 	}
 
-	// // Check if book exists when book_id is provided
-	// if req.BookID != uuid.Nil {
-	// 	_, err := s.bookRepo.FindByID(req.BookID)
-	// 	if err != nil {
-	// 		return nil, errors.New("book not found")
-	// 	}
-	// 	transaction.BookID = req.BookID
-	// }
+	if actor.BranchID != nil {
+		return repos.BookStocks.UpdateCurrentBranch(bookStock.Code, *actor.BranchID)
+	}
+	return nil
+}
 
-	// Check if book stock exists when stock_code is provided
-	if req.StockCode != "" {
-		stockCode, err := s.bookStockRepo.FindByCode(req.StockCode)
+func (s *bookTransactionService) Update(id uuid.UUID, actor dto.UserData, req dto.BookTransactionUpdateRequest) (*dto.BookTransactionResponse, error) {
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		// Check if transaction exists
+		transaction, err := repos.BookTransactions.FindByIDForUpdate(id)
 		if err != nil {
-			return nil, errors.New("book stock not found")
+			return errors.New("transaction not found")
 		}
 
-		// If changing stock code, update old stock status back to Available
-		if transaction.StockCode != req.StockCode {
-			if err := s.bookStockRepo.UpdateStatus(transaction.StockCode, model.StatusAvailable); err != nil {
-				return nil, errors.New("failed to update original book stock status")
+		// Closed loans only change through corrections, which keep the copy and charges consistent
+		if isClosedLoan(transaction.Status) {
+			return fmt.Errorf("%w: use a correction to change a %s loan", ErrLoanClosed, transaction.Status)
+		}
+
+		swapping := req.StockCode != "" && req.StockCode != transaction.StockCode
+		returning := req.Status == model.StatusBTReturned
+		if req.ReturnAt != nil && !returning {
+			return errors.New("return_at can only be set when returning the loan")
+		}
+
+		// Lock every copy this update touches before changing any of them
+		codes := make([]string, 0, 2)
+		if swapping || returning {
+			codes = append(codes, transaction.StockCode)
+		}
+		if swapping {
+			codes = append(codes, req.StockCode)
+		}
+		bookStocks, err := lockBookStocks(repos.BookStocks, codes...)
+		if err != nil {
+			return err
+		}
+
+		// Check if customer exists when customer_id is provided
+		if req.CustomerID != uuid.Nil {
+			_, err := repos.Customers.FindByID(req.CustomerID)
+			if err != nil {
+				return errors.New("customer not found")
+			}
+			transaction.CustomerID = req.CustomerID
+		}
+
+		// Move the loan to another copy, releasing the old one
+		if swapping {
+			newStock := bookStocks[req.StockCode]
+
+			// The new copy has to pass the same checks as at checkout before the old one is released
+			if !CanTransitionBookStock(newStock.Status, model.StatusBorrowed, model.StockActionCheckout) {
+				return errors.New("book stock is not available")
+			}
+			if err := checkBranchAccess(actor, newStock.CurrentBranchID); err != nil {
+				return err
+			}
+			hold, err := holdForCheckout(repos, newStock, transaction.CustomerID)
+			if err != nil {
+				return err
+			}
+
+			// Closing the customer's own hold first keeps the old copy from being set aside for them
+			if err := fulfilHold(repos, hold, time.Now()); err != nil {
+				return err
+			}
+
+			// The old copy goes to the next customer waiting for the title, at the branch it is at
+			if _, err := releaseCopyToQueue(repos, bookStocks[transaction.StockCode], model.StockActionReturn, "loan moved to another copy", &actor.ID, s.holdPickupDays); err != nil {
+				return err
 			}
 
 			// Set new stock to Borrowed
-			if err := s.bookStockRepo.UpdateStatus(req.StockCode, model.StatusBorrowed); err != nil {
-				return nil, errors.New("failed to update new book stock status")
+			if err := transitionBookStock(repos.BookStocks, newStock, model.StatusBorrowed, model.StockActionCheckout, "loan moved from another copy", &actor.ID); err != nil {
+				return err
+			}
+
+			transaction.BookID = newStock.BookID
+			transaction.StockCode = req.StockCode
+			transaction.BranchID = newStock.CurrentBranchID
+		}
+
+		// Update fields if provided
+		if req.DueDate != nil {
+			transaction.DueDate = *req.DueDate
+		}
+
+		if req.Status != "" && req.Status != transaction.Status {
+			if !returning {
+				if err := transitionBookTransaction(repos.BookTransactions, transaction, req.Status, model.LoanActionUpdate, "", &actor.ID); err != nil {
+					return err
+				}
+				return repos.BookTransactions.Update(transaction)
+			}
+
+			// Returning here works like a return at the desk
+			returnAt := time.Now()
+			if req.ReturnAt != nil {
+				returnAt = *req.ReturnAt
 			}
+			if err := transitionBookTransaction(repos.BookTransactions, transaction, model.StatusBTReturned, model.LoanActionReturn, "", &actor.ID); err != nil {
+				return err
+			}
+			if err := s.takeBackCopy(repos, bookStocks[transaction.StockCode], actor, ""); err != nil {
+				return err
+			}
+			transaction.ReturnAt = &returnAt
+			if err := repos.BookTransactions.Update(transaction); err != nil {
+				return err
+			}
+			return s.assessLateFee(repos, transaction, returnAt, actor.ID)
 		}
 
-		transaction.BookID = stockCode.BookID
-		transaction.StockCode = req.StockCode
+		return repos.BookTransactions.Update(transaction)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Check if customer exists when customer_id is provided
-	if req.CustomerID != uuid.Nil {
-		_, err := s.customerRepo.FindByID(req.CustomerID)
+	return s.GetByID(id)
+}
+
+func (s *bookTransactionService) Delete(id uuid.UUID) error {
+	return s.uow.Do(func(repos repository.Repositories) error {
+		// Check if transaction exists
+		transaction, err := repos.BookTransactions.FindByIDForUpdate(id)
 		if err != nil {
-			return nil, errors.New("customer not found")
+			return errors.New("transaction not found")
 		}
-		transaction.CustomerID = req.CustomerID
-	}
 
-	// Update fields if provided
-	// transaction.DueDate = time.Now().AddDate(0, 0, 7)
-	if req.DueDate != nil {
-		transaction.DueDate = *req.DueDate
-	}
+		// Closed loans are kept for the record, together with their charges and payments
+		if isClosedLoan(transaction.Status) {
+			return fmt.Errorf("%w: a %s loan cannot be deleted", ErrLoanClosed, transaction.Status)
+		}
 
-	if req.Status != "" {
-		transaction.Status = req.Status
+		// The loan is active (Borrowed or Overdue), so restore book stock status to Available
+		if err := transitionBookStockByCode(repos.BookStocks, transaction.StockCode, model.StatusAvailable, model.StockActionReturn, "loan deleted", nil); err != nil {
+			return err
+		}
 
-		// If status changed to Returned, update book stock status to Available
-		if req.Status == model.StatusBTReturned && (transaction.ReturnAt == nil || req.ReturnAt != nil) {
-			if err := s.bookStockRepo.UpdateStatus(transaction.StockCode, model.StatusAvailable); err != nil {
-				return nil, errors.New("failed to update book stock status")
-			}
+		// Delete transaction
+		return repos.BookTransactions.Delete(id)
+	})
+}
+
+// UpdateStatus moves an open loan between Borrowed and Overdue or returns it. Closed loans
+// cannot be reopened here; that takes a correction.
+func (s *bookTransactionService) UpdateStatus(id uuid.UUID, actor dto.UserData, req dto.BookTransactionStatusUpdateRequest) (*dto.BookTransactionResponse, error) {
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		// Check if transaction exists
+		transaction, err := repos.BookTransactions.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("transaction not found")
+		}
 
-			// Set return date if not provided
-			if req.ReturnAt == nil {
-				now := time.Now()
-				transaction.ReturnAt = &now
-			} else {
-				transaction.ReturnAt = req.ReturnAt
+		if req.Status == transaction.Status {
+			return nil
+		}
+
+		if req.Status != model.StatusBTReturned {
+			if err := transitionBookTransaction(repos.BookTransactions, transaction, req.Status, model.LoanActionUpdate, req.Reason, &actor.ID); err != nil {
+				return err
 			}
+			return repos.BookTransactions.Update(transaction)
 		}
+
+		if err := transitionBookTransaction(repos.BookTransactions, transaction, model.StatusBTReturned, model.LoanActionReturn, req.Reason, &actor.ID); err != nil {
+			return err
+		}
+
+		bookStock, err := repos.BookStocks.FindByCodeForUpdate(transaction.StockCode)
+		if err != nil {
+			return ErrBookStockNotFound
+		}
+		if err := s.takeBackCopy(repos, bookStock, actor, req.Reason); err != nil {
+			return err
+		}
+
+		// Set return date
+		now := time.Now()
+		transaction.ReturnAt = &now
+		if err := repos.BookTransactions.Update(transaction); err != nil {
+			return err
+		}
+
+		return s.assessLateFee(repos, transaction, now, actor.ID)
+	})
+	if err != nil {
+		return nil, err
 	}
 
+	return s.GetByID(id)
+}
+
+func (s *bookTransactionService) ReturnBook(id uuid.UUID, actor dto.UserData, req dto.BookTransactionReturnRequest) (*dto.BookTransactionResponse, error) {
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		return s.returnBook(repos, id, actor, req)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Get updated transaction
+	return s.GetByID(id)
+}
+
+// returnBook takes a borrowed copy back inside a unit of work
+func (s *bookTransactionService) returnBook(repos repository.Repositories, id uuid.UUID, actor dto.UserData, req dto.BookTransactionReturnRequest) error {
+	// Lock the loan so a second return of it waits and then sees it returned
+	transaction, err := repos.BookTransactions.FindByIDForUpdate(id)
+	if err != nil {
+		return errors.New("transaction not found")
+	}
+
+	// Check if transaction is already returned
+	if transaction.Status == model.StatusBTReturned {
+		return errors.New("book is already returned")
+	}
+
+	returnAt := time.Now()
 	if req.ReturnAt != nil {
-		transaction.ReturnAt = req.ReturnAt
+		returnAt = *req.ReturnAt
+	}
+
+	// A copy reported lost that turns up is returned through its loan
+	if transaction.Status == model.StatusBTLost {
+		return s.returnLostCopy(repos, transaction, returnAt, actor, req.RefundMethod)
+	}
+	if transaction.Status == model.StatusBTDamaged {
+		return fmt.Errorf("%w: copy was reported damaged", ErrLoanClosed)
+	}
+
+	if err := transitionBookTransaction(repos.BookTransactions, transaction, model.StatusBTReturned, model.LoanActionReturn, "", &actor.ID); err != nil {
+		return err
 	}
 
-	if err := s.repository.Update(transaction); err != nil {
+	bookStock, err := repos.BookStocks.FindByCodeForUpdate(transaction.StockCode)
+	if err != nil {
+		return ErrBookStockNotFound
+	}
+
+	if err := s.takeBackCopy(repos, bookStock, actor, ""); err != nil {
+		return err
+	}
+
+	// Return book
+	if err := repos.BookTransactions.ReturnBook(id, returnAt); err != nil {
+		return err
+	}
+
+	return s.assessLateFee(repos, transaction, returnAt, actor.ID)
+}
+
+// ReportLoss closes an open loan whose copy was lost or came back damaged. Late fees are
+// settled up to today and the customer is charged the copy's price plus the processing fee.
+func (s *bookTransactionService) ReportLoss(id uuid.UUID, actor dto.UserData, req dto.BookTransactionLossRequest) (*dto.BookTransactionResponse, error) {
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		transaction, err := repos.BookTransactions.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("transaction not found")
+		}
+
+		if isClosedLoan(transaction.Status) {
+			return fmt.Errorf("%w: loan is %s", ErrLoanClosed, transaction.Status)
+		}
+
+		if err := transitionBookTransaction(repos.BookTransactions, transaction, req.Status, model.LoanActionLoss, req.Reason, &actor.ID); err != nil {
+			return err
+		}
+
+		bookStock, err := repos.BookStocks.FindByCodeForUpdate(transaction.StockCode)
+		if err != nil {
+			return ErrBookStockNotFound
+		}
+
+		if err := transitionBookStock(repos.BookStocks, bookStock, req.Status, model.StockActionLoss, req.Reason, &actor.ID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		transaction.ReturnAt = &now
+		if err := repos.BookTransactions.Update(transaction); err != nil {
+			return err
+		}
+
+		if err := s.assessLateFee(repos, transaction, now, actor.ID); err != nil {
+			return err
+		}
+
+		charge := newReplacementCharge(transaction, bookStock, s.processingFee, actor.ID, now)
+		if charge.Total <= 0 {
+			return nil
+		}
+		return repos.Charges.Create(&charge)
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	response := mapToBookTransactionResponse(transaction)
-	return &response, nil
+	return s.GetByID(id)
 }
 
-func (s *bookTransactionService) Delete(id uuid.UUID) error {
-	// Check if transaction exists
-	transaction, err := s.repository.FindByID(id)
+// returnLostCopy closes a lost loan as returned once its copy turns up. The copy goes back into
+// circulation and its replacement charges are reversed; late fees stay as frozen at the loss.
+func (s *bookTransactionService) returnLostCopy(repos repository.Repositories, transaction *model.BookTransaction, returnAt time.Time, actor dto.UserData, refundMethod string) error {
+	bookStock, err := repos.BookStocks.FindByCodeForUpdate(transaction.StockCode)
 	if err != nil {
-		return errors.New("transaction not found")
+		return ErrBookStockNotFound
 	}
 
-	// If the transaction is active (Borrowed or Overdue), restore book stock status to Available
-	if transaction.Status == model.StatusBTBorrowed || transaction.Status == model.StatusBTOverdue {
-		if err := s.bookStockRepo.UpdateStatus(transaction.StockCode, model.StatusAvailable); err != nil {
-			return errors.New("failed to update book stock status")
+	// Staff may already have marked the copy found on its own
+	if bookStock.Status == model.StatusLost {
+		if _, err := releaseCopyToQueue(repos, bookStock, model.StockActionFound, lostItemReturnedReason, &actor.ID, s.holdPickupDays); err != nil {
+			return err
+		}
+		if actor.BranchID != nil {
+			if err := repos.BookStocks.UpdateCurrentBranch(transaction.StockCode, *actor.BranchID); err != nil {
+				return err
+			}
 		}
 	}
 
-	// Delete transaction
-	return s.repository.Delete(id)
+	if err := transitionBookTransaction(repos.BookTransactions, transaction, model.StatusBTReturned, model.LoanActionFound, lostItemReturnedReason, &actor.ID); err != nil {
+		return err
+	}
+	transaction.ReturnAt = &returnAt
+	if err := repos.BookTransactions.Update(transaction); err != nil {
+		return err
+	}
+
+	charges, err := repos.Charges.FindByBookTransactionID(transaction.ID)
+	if err != nil {
+		return err
+	}
+	for _, charge := range charges {
+		if charge.Type != model.ChargeTypeReplacement || charge.ReversedAt != nil {
+			continue
+		}
+		if err := reverseReplacementCharge(repos, charge.ID, transaction.CustomerID, actor, refundMethod); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-func (s *bookTransactionService) UpdateStatus(id uuid.UUID, req dto.BookTransactionStatusUpdateRequest) (*dto.BookTransactionResponse, error) {
-	// Check if transaction exists
-	transaction, err := s.repository.FindByID(id)
+// Correct fixes a closed loan: it reopens a returned loan whose copy is still on the shelf, or
+// fixes a mis-recorded return date. Charges stay as they were frozen when the loan closed.
+func (s *bookTransactionService) Correct(id uuid.UUID, actor dto.UserData, req dto.BookTransactionCorrectionRequest) (*dto.BookTransactionResponse, error) {
+	if req.Status == "" && req.ReturnAt == nil {
+		return nil, errors.New("nothing to correct")
+	}
+	if req.Status == model.StatusBTBorrowed && req.ReturnAt != nil {
+		return nil, errors.New("a reopened loan has no return date")
+	}
+
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		transaction, err := repos.BookTransactions.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("transaction not found")
+		}
+
+		if !isClosedLoan(transaction.Status) {
+			return errors.New("only closed loans need corrections; update the open loan instead")
+		}
+
+		if req.ReturnAt != nil {
+			if transaction.BorrowedAt != nil && req.ReturnAt.Before(*transaction.BorrowedAt) {
+				return errors.New("return date is before the loan was borrowed")
+			}
+			previous := "none"
+			if transaction.ReturnAt != nil {
+				previous = transaction.ReturnAt.Format(time.RFC3339)
+			}
+			reason := fmt.Sprintf("return date %s corrected to %s: %s", previous, req.ReturnAt.Format(time.RFC3339), req.Reason)
+			if err := recordBookTransactionEvent(repos.BookTransactions, transaction, transaction.Status, model.LoanActionCorrection, reason, &actor.ID); err != nil {
+				return err
+			}
+			transaction.ReturnAt = req.ReturnAt
+		}
+
+		if req.Status == model.StatusBTBorrowed {
+			if err := transitionBookTransaction(repos.BookTransactions, transaction, model.StatusBTBorrowed, model.LoanActionCorrection, req.Reason, &actor.ID); err != nil {
+				return err
+			}
+
+			// Reopening lends the copy again, so it must still be on the shelf and not set aside for a hold
+			bookStock, err := repos.BookStocks.FindByCodeForUpdate(transaction.StockCode)
+			if err != nil {
+				return ErrBookStockNotFound
+			}
+			if bookStock.Status != model.StatusAvailable {
+				return fmt.Errorf("book stock %s is %s and cannot be lent again", bookStock.Code, bookStock.Status)
+			}
+			if active, err := repos.BookTransactions.FindActiveByStockCode(bookStock.Code); err == nil && active.ID != transaction.ID {
+				return fmt.Errorf("book stock %s is on another open loan", bookStock.Code)
+			}
+			if err := transitionBookStock(repos.BookStocks, bookStock, model.StatusBorrowed, model.StockActionCheckout, "loan reopened by correction", &actor.ID); err != nil {
+				return err
+			}
+			transaction.ReturnAt = nil
+		}
+
+		return repos.BookTransactions.Update(transaction)
+	})
 	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(id)
+}
+
+func (s *bookTransactionService) GetEvents(id uuid.UUID) ([]dto.BookTransactionEventResponse, error) {
+	// Check if transaction exists
+	if _, err := s.repository.FindByID(id); err != nil {
 		return nil, errors.New("transaction not found")
 	}
 
-	// Update status
-	if err := s.repository.UpdateStatus(id, req.Status); err != nil {
+	events, err := s.repository.FindEventsByTransactionID(id)
+	if err != nil {
 		return nil, err
 	}
 
-	// Update book stock status based on transaction status change
-	if req.Status == model.StatusBTBorrowed {
-		if err := s.bookStockRepo.UpdateStatus(transaction.StockCode, model.StatusBorrowed); err != nil {
-			return nil, errors.New("failed to update book stock status")
+	responses := make([]dto.BookTransactionEventResponse, 0, len(events))
+	for _, event := range events {
+		response := dto.BookTransactionEventResponse{
+			ID:                event.ID,
+			BookTransactionID: event.BookTransactionID,
+			FromStatus:        event.FromStatus,
+			ToStatus:          event.ToStatus,
+			Action:            event.Action,
+			Reason:            event.Reason,
+			UserID:            event.UserID,
+			CreatedAt:         event.CreatedAt,
 		}
-	} else if req.Status == model.StatusBTReturned {
-		if err := s.bookStockRepo.UpdateStatus(transaction.StockCode, model.StatusAvailable); err != nil {
-			return nil, errors.New("failed to update book stock status")
+		if event.User != nil {
+			response.User = &dto.UserData{
+				ID:    event.User.ID,
+				Name:  event.User.Name,
+				Email: event.User.Email,
+				Role:  event.User.Role,
+			}
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+func (s *bookTransactionService) Renew(id uuid.UUID, actor dto.UserData) (*dto.BookTransactionResponse, error) {
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		// Lock the loan so concurrent renewals cannot both pass the renewal limit
+		transaction, err := repos.BookTransactions.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("transaction not found")
+		}
+
+		// Members renew their own loans only
+		if err := CheckCustomerAccess(actor, transaction.CustomerID); err != nil {
+			return err
 		}
 
-		// Set return date
 		now := time.Now()
-		transaction.ReturnAt = &now
-		errUpdate := s.repository.Update(transaction)
-		if errUpdate != nil {
-			return nil, errUpdate
+		if transaction.Status == model.StatusBTOverdue || (transaction.Status == model.StatusBTBorrowed && transaction.DueDate.Before(now)) {
+			return fmt.Errorf("%w: loan is overdue", ErrRenewalNotAllowed)
+		}
+		if transaction.Status != model.StatusBTBorrowed {
+			return fmt.Errorf("%w: loan is %s", ErrRenewalNotAllowed, transaction.Status)
+		}
+
+		if transaction.RenewalCount >= transaction.MaxRenewals {
+			return fmt.Errorf("%w: maximum of %d renewals reached", ErrRenewalNotAllowed, transaction.MaxRenewals)
+		}
+
+		// Customers waiting for the title get it first
+		pendingHolds, err := repos.Holds.CountPendingByBookID(transaction.BookID)
+		if err != nil {
+			return err
+		}
+		if pendingHolds > 0 {
+			return fmt.Errorf("%w: other customers are waiting for this title", ErrRenewalNotAllowed)
+		}
+
+		// The loan period restarts on the day of renewal and ends on a day the branch is open
+		newDueDate, err := nextOpenDay(s.calendar, transaction.BranchID, now.AddDate(0, 0, transaction.LoanPeriodDays))
+		if err != nil {
+			return err
+		}
+		if !newDueDate.After(transaction.DueDate) {
+			return fmt.Errorf("%w: renewing now would not extend the due date", ErrRenewalNotAllowed)
+		}
+
+		renewal := model.LoanRenewal{
+			ID:                uuid.New(),
+			BookTransactionID: transaction.ID,
+			PreviousDueDate:   transaction.DueDate,
+			NewDueDate:        newDueDate,
+			UserID:            &actor.ID,
+			CreatedAt:         now,
 		}
+		if err := repos.BookTransactions.CreateRenewal(&renewal); err != nil {
+			return err
+		}
+
+		transaction.DueDate = newDueDate
+		transaction.RenewalCount++
+		return repos.BookTransactions.Update(transaction)
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	transaction.Status = req.Status
-	response := mapToBookTransactionResponse(transaction)
-	return &response, nil
+	return s.GetByID(id)
 }
 
-func (s *bookTransactionService) ReturnBook(id uuid.UUID, req dto.BookTransactionReturnRequest) (*dto.BookTransactionResponse, error) {
+func (s *bookTransactionService) GetRenewals(id uuid.UUID) ([]dto.LoanRenewalResponse, error) {
 	// Check if transaction exists
-	transaction, err := s.repository.FindByID(id)
-	if err != nil {
+	if _, err := s.repository.FindByID(id); err != nil {
 		return nil, errors.New("transaction not found")
 	}
 
-	// Check if transaction is already returned
-	if transaction.Status == model.StatusBTReturned {
-		return nil, errors.New("book is already returned")
+	renewals, err := s.repository.FindRenewalsByTransactionID(id)
+	if err != nil {
+		return nil, err
 	}
 
-	returnAt := time.Now()
-	if req.ReturnAt != nil {
-		returnAt = *req.ReturnAt
+	responses := make([]dto.LoanRenewalResponse, 0, len(renewals))
+	for _, renewal := range renewals {
+		responses = append(responses, dto.LoanRenewalResponse{
+			ID:                renewal.ID,
+			BookTransactionID: renewal.BookTransactionID,
+			PreviousDueDate:   renewal.PreviousDueDate,
+			NewDueDate:        renewal.NewDueDate,
+			UserID:            renewal.UserID,
+			CreatedAt:         renewal.CreatedAt,
+		})
 	}
 
-	// Return book
-	if err := s.repository.ReturnBook(id, returnAt); err != nil {
+	return responses, nil
+}
+
+// CheckEligibility runs the checkout eligibility checks for a customer, optionally for borrowing a given copy
+func (s *bookTransactionService) CheckEligibility(customerID uuid.UUID, stockCode string) (*dto.EligibilityResponse, error) {
+	var reasons []dto.EligibilityReason
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		customer, err := repos.Customers.FindByID(customerID)
+		if err != nil {
+			return errors.New("customer not found")
+		}
+
+		// Without a copy the loan limit of the customer's general policy applies
+		book := &model.Book{}
+		if stockCode != "" {
+			bookStock, err := repos.BookStocks.FindByCode(stockCode)
+			if err != nil {
+				return ErrBookStockNotFound
+			}
+			if book, err = s.bookRepo.FindByID(bookStock.BookID); err != nil {
+				return ErrBookNotFound
+			}
+		}
+
+		policy, err := resolveLoanPolicy(repos.LoanPolicies, customer, book)
+		if err != nil {
+			return err
+		}
+
+		reasons, err = evaluateEligibility(repos, s.eligibilityRules, customer, policy, time.Now())
+		return err
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	// Update book stock status to Available
-	if err := s.bookStockRepo.UpdateStatus(transaction.StockCode, model.StatusAvailable); err != nil {
-		return nil, errors.New("failed to update book stock status")
-	}
+	return &dto.EligibilityResponse{
+		CustomerID: customerID,
+		Eligible:   len(reasons) == 0,
+		Reasons:    reasons,
+	}, nil
+}
 
-	// Get updated transaction
-	updatedTransaction, err := s.repository.FindByID(id)
+func (s *bookTransactionService) GetEligibilityOverrides(customerID uuid.UUID) ([]dto.EligibilityOverrideResponse, error) {
+	overrides, err := s.repository.FindEligibilityOverridesByCustomerID(customerID)
 	if err != nil {
 		return nil, err
 	}
 
-	response := mapToBookTransactionResponse(updatedTransaction)
-	return &response, nil
+	responses := make([]dto.EligibilityOverrideResponse, 0, len(overrides))
+	for _, override := range overrides {
+		response := dto.EligibilityOverrideResponse{
+			ID:                override.ID,
+			BookTransactionID: override.BookTransactionID,
+			CustomerID:        override.CustomerID,
+			ReasonCodes:       strings.Split(override.ReasonCodes, ","),
+			Reason:            override.Reason,
+			UserID:            override.UserID,
+			CreatedAt:         override.CreatedAt,
+		}
+		if override.Customer.ID != uuid.Nil {
+			response.Customer = &dto.CustomerResponse{
+				ID:       override.Customer.ID,
+				Code:     override.Customer.Code,
+				Name:     override.Customer.Name,
+				Category: override.Customer.Category,
+			}
+		}
+		if override.User.ID != uuid.Nil {
+			response.User = &dto.UserData{
+				ID:    override.User.ID,
+				Name:  override.User.Name,
+				Email: override.User.Email,
+				Role:  override.User.Role,
+			}
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+// assessLateFee charges the customer for a late return according to the loan's fee terms.
+// Late fees staff raised while the loan was open are settled up to the return date instead,
+// and every charge of the loan is frozen from now on.
+func (s *bookTransactionService) assessLateFee(repos repository.Repositories, transaction *model.BookTransaction, returnAt time.Time, userID uuid.UUID) error {
+	now := time.Now()
+
+	charges, err := repos.Charges.FindByBookTransactionID(transaction.ID)
+	if err != nil {
+		return err
+	}
+	closed, err := loanClosedDays(s.calendar, transaction, returnAt)
+	if err != nil {
+		return err
+	}
+	existing := make([]model.Charge, 0, len(charges))
+	for _, charge := range charges {
+		if charge.Type == model.ChargeTypeLateFee {
+			existing = append(existing, charge)
+		}
+	}
+	if len(existing) > 0 {
+		for _, charge := range existing {
+			if charge.FrozenAt != nil {
+				continue
+			}
+			fee := assessLateFee(transaction, charge.DailyLateFee, returnAt, closed)
+			charge.DaysLate = fee.DaysLate
+			charge.Total = fee.Total
+			charge.FrozenAt = &now
+			if err := repos.Charges.Update(&charge); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	fee := assessLateFee(transaction, transaction.DailyLateFee, returnAt, closed)
+	if fee.Total <= 0 {
+		return nil
+	}
+
+	charge := model.Charge{
+		ID:                uuid.New(),
+		BookTransactionID: transaction.ID,
+		Type:              model.ChargeTypeLateFee,
+		DaysLate:          fee.DaysLate,
+		DailyLateFee:      fee.DailyLateFee,
+		Total:             fee.Total,
+		UserID:            userID,
+		CreatedAt:         now,
+		FrozenAt:          &now,
+	}
+	return repos.Charges.Create(&charge)
+}
+
+// lockBookStocks loads and locks the given copies in code order, so requests touching
+// the same copies always lock them in the same order and cannot deadlock
+func lockBookStocks(repo repository.BookStockRepository, codes ...string) (map[string]*model.BookStock, error) {
+	sorted := append([]string(nil), codes...)
+	sort.Strings(sorted)
+
+	bookStocks := make(map[string]*model.BookStock, len(sorted))
+	for _, code := range sorted {
+		if _, ok := bookStocks[code]; ok {
+			continue
+		}
+		bookStock, err := repo.FindByCodeForUpdate(code)
+		if err != nil {
+			return nil, ErrBookStockNotFound
+		}
+		bookStocks[code] = bookStock
+	}
+	return bookStocks, nil
 }
 
+// GetOverdueTransactions lists open loans past their due date, including ones the scheduler
+// has not marked Overdue yet. It does not change any loan.
 func (s *bookTransactionService) GetOverdueTransactions() ([]dto.BookTransactionResponse, error) {
 	transactions, err := s.repository.FindOverdueTransactions()
 	if err != nil {
 		return nil, err
 	}
 
-	// Update status to Overdue for all overdue transactions
-	var responses []dto.BookTransactionResponse
+	responses := make([]dto.BookTransactionResponse, 0, len(transactions))
 	for _, transaction := range transactions {
-		if transaction.Status != model.StatusBTOverdue {
-			// Update status to Overdue
-			err := s.repository.UpdateStatus(transaction.ID, model.StatusBTOverdue)
-			if err != nil {
-				return nil, err
-			}
-			transaction.Status = model.StatusBTOverdue
-		}
 		responses = append(responses, mapToBookTransactionResponse(&transaction))
 	}
 
 	return responses, nil
 }
 
+// MarkOverdue moves borrowed loans past their due date to Overdue; the scheduler runs it periodically
+func (s *bookTransactionService) MarkOverdue() (int64, error) {
+	var ids []uuid.UUID
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		ids, err = repos.BookTransactions.MarkOverdue(time.Now())
+		if err != nil {
+			return err
+		}
+
+		events := make([]model.BookTransactionEvent, 0, len(ids))
+		for _, id := range ids {
+			events = append(events, model.BookTransactionEvent{
+				ID:                uuid.New(),
+				BookTransactionID: id,
+				FromStatus:        model.StatusBTBorrowed,
+				ToStatus:          model.StatusBTOverdue,
+				Action:            model.LoanActionOverdue,
+				Reason:            "due date passed",
+			})
+		}
+		return repos.BookTransactions.CreateEvents(events)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
 // Helper function to map a BookTransaction entity to a BookTransactionResponse DTO
 func mapToBookTransactionResponse(transaction *model.BookTransaction) dto.BookTransactionResponse {
 	response := dto.BookTransactionResponse{
@@ -383,17 +1088,34 @@ func mapToBookTransactionResponse(transaction *model.BookTransaction) dto.BookTr
 		BookID:     transaction.BookID,
 		StockCode:  transaction.StockCode,
 		CustomerID: transaction.CustomerID,
+		BranchID:   transaction.BranchID,
 		DueDate:    transaction.DueDate,
 		Status:     transaction.Status,
 		BorrowedAt: transaction.BorrowedAt,
 		ReturnAt:   transaction.ReturnAt,
+		// Loan terms applied at checkout
+		LoanPolicyID:   transaction.LoanPolicyID,
+		LoanPeriodDays: transaction.LoanPeriodDays,
+		DailyLateFee:   transaction.DailyLateFee,
+		MaxRenewals:    transaction.MaxRenewals,
+		RenewalCount:   transaction.RenewalCount,
+		// Late fee terms
+		GracePeriodDays: transaction.GracePeriodDays,
+		MaxLateFee:      transaction.MaxLateFee,
+		SkipClosedDays:  transaction.SkipClosedDays,
+	}
+
+	if transaction.LoanPolicy != nil {
+		policy := mapToLoanPolicyResponse(transaction.LoanPolicy)
+		response.LoanPolicy = &policy
 	}
 
 	if transaction.Book.ID != uuid.Nil {
 		response.Book = &dto.BookRes{
-			ID:          transaction.Book.ID,
-			Title:       transaction.Book.Title,
-			Description: transaction.Book.Description,
+			ID:           transaction.Book.ID,
+			Title:        transaction.Book.Title,
+			Description:  transaction.Book.Description,
+			MaterialType: transaction.Book.MaterialType,
 		}
 
 		if transaction.Book.Cover != nil && transaction.Book.Cover.Path != "" {
@@ -411,23 +1133,35 @@ func mapToBookTransactionResponse(transaction *model.BookTransaction) dto.BookTr
 
 	if transaction.Customer.ID != uuid.Nil {
 		response.Customer = &dto.CustomerResponse{
-			ID:   transaction.Customer.ID,
-			Code: transaction.Customer.Code,
-			Name: transaction.Customer.Name,
+			ID:       transaction.Customer.ID,
+			Code:     transaction.Customer.Code,
+			Name:     transaction.Customer.Name,
+			Category: transaction.Customer.Category,
 		}
 	}
 
 	if len(transaction.Charges) > 0 {
 		response.Charges = make([]dto.ChargeResponse, 0)
 		for _, charge := range transaction.Charges {
+			ledger := summarizeCharge(&charge)
 			response.Charges = append(response.Charges, dto.ChargeResponse{
 				ID:                charge.ID,
 				BookTransactionID: charge.BookTransactionID,
+				Type:              charge.Type,
 				DaysLate:          charge.DaysLate,
 				DailyLateFee:      charge.DailyLateFee,
 				Total:             charge.Total,
 				UserID:            charge.UserID,
 				CreatedAt:         charge.CreatedAt,
+				FrozenAt:          charge.FrozenAt,
+				ReplacementCost:   charge.ReplacementCost,
+				ProcessingFee:     charge.ProcessingFee,
+				ReversedAt:        charge.ReversedAt,
+				Paid:              ledger.Paid,
+				Refunded:          ledger.Refunded,
+				Waived:            ledger.Waived,
+				Balance:           ledger.Balance,
+				PaymentStatus:     ledger.Status,
 			})
 		}
 	}
@@ -0,0 +1,301 @@
+package service
+
+import (
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// memoryDB is an in-memory stand-in for the database behind a unit of work. Rows locked with
+// a ...ForUpdate lookup stay locked until the unit of work ends, and writes only become visible
+// to others on commit, the way SELECT ... FOR UPDATE behaves in PostgreSQL.
+type memoryDB struct {
+	mu        sync.Mutex
+	rowLocks  map[string]*sync.Mutex
+	stocks    map[string]model.BookStock
+	customers map[uuid.UUID]model.Customer
+	loans     []model.BookTransaction
+	history   []model.BookStockStatusHistory
+}
+
+func newMemoryDB() *memoryDB {
+	return &memoryDB{
+		rowLocks:  map[string]*sync.Mutex{},
+		stocks:    map[string]model.BookStock{},
+		customers: map[uuid.UUID]model.Customer{},
+	}
+}
+
+func (db *memoryDB) rowLock(key string) *sync.Mutex {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.rowLocks[key] == nil {
+		db.rowLocks[key] = &sync.Mutex{}
+	}
+	return db.rowLocks[key]
+}
+
+type memoryUnitOfWork struct {
+	db *memoryDB
+}
+
+// memoryTx holds the row locks and pending writes of one unit of work
+type memoryTx struct {
+	db     *memoryDB
+	locked map[string]*sync.Mutex
+	writes []func()
+}
+
+func (tx *memoryTx) lockRow(key string) {
+	if _, ok := tx.locked[key]; ok {
+		return
+	}
+	lock := tx.db.rowLock(key)
+	lock.Lock()
+	tx.locked[key] = lock
+}
+
+func (tx *memoryTx) write(fn func()) {
+	tx.writes = append(tx.writes, fn)
+}
+
+func (u *memoryUnitOfWork) Do(fn func(repos repository.Repositories) error) error {
+	tx := &memoryTx{db: u.db, locked: map[string]*sync.Mutex{}}
+	defer func() {
+		for _, lock := range tx.locked {
+			lock.Unlock()
+		}
+	}()
+
+	err := fn(repository.Repositories{
+		BookTransactions: &memoryBookTransactionRepo{tx: tx},
+		BookStocks:       &memoryBookStockRepo{tx: tx},
+		Customers:        &memoryCustomerRepo{tx: tx},
+		LoanPolicies:     &memoryLoanPolicyRepo{},
+		Holds:            &memoryHoldRepo{},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Committing takes a round trip, as with a real database, which gives units of work that
+	// do not lock properly time to interleave
+	time.Sleep(time.Millisecond)
+
+	// Commit before the locks are released
+	u.db.mu.Lock()
+	defer u.db.mu.Unlock()
+	for _, write := range tx.writes {
+		write()
+	}
+	return nil
+}
+
+// The memory repositories embed the real interfaces and implement only what checkout uses;
+// any other call panics, which points at a missing fake rather than passing silently.
+
+type memoryBookStockRepo struct {
+	repository.BookStockRepository
+	tx *memoryTx
+}
+
+func (r *memoryBookStockRepo) FindByCodeForUpdate(code string) (*model.BookStock, error) {
+	r.tx.lockRow("book_stock:" + code)
+	r.tx.db.mu.Lock()
+	defer r.tx.db.mu.Unlock()
+	bookStock, ok := r.tx.db.stocks[code]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &bookStock, nil
+}
+
+func (r *memoryBookStockRepo) UpdateStatus(code string, status string) error {
+	db := r.tx.db
+	r.tx.write(func() {
+		bookStock := db.stocks[code]
+		bookStock.Status = status
+		db.stocks[code] = bookStock
+	})
+	return nil
+}
+
+func (r *memoryBookStockRepo) CreateStatusHistory(history *model.BookStockStatusHistory) error {
+	db, entry := r.tx.db, *history
+	r.tx.write(func() {
+		db.history = append(db.history, entry)
+	})
+	return nil
+}
+
+type memoryCustomerRepo struct {
+	repository.CustomerRepository
+	tx *memoryTx
+}
+
+func (r *memoryCustomerRepo) FindByIDForUpdate(id uuid.UUID) (*model.Customer, error) {
+	r.tx.lockRow("customer:" + id.String())
+	r.tx.db.mu.Lock()
+	defer r.tx.db.mu.Unlock()
+	customer, ok := r.tx.db.customers[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &customer, nil
+}
+
+type memoryBookTransactionRepo struct {
+	repository.BookTransactionRepository
+	tx *memoryTx
+}
+
+func (r *memoryBookTransactionRepo) Create(transaction *model.BookTransaction) error {
+	db, loan := r.tx.db, *transaction
+	r.tx.write(func() {
+		db.loans = append(db.loans, loan)
+	})
+	return nil
+}
+
+func (r *memoryBookTransactionRepo) CreateEvents(events []model.BookTransactionEvent) error {
+	return nil
+}
+
+type memoryLoanPolicyRepo struct {
+	repository.LoanPolicyRepository
+}
+
+func (r *memoryLoanPolicyRepo) FindMatching(customerCategory, materialType string) (*model.LoanPolicy, error) {
+	return nil, nil
+}
+
+type memoryHoldRepo struct {
+	repository.HoldRepository
+}
+
+func (r *memoryHoldRepo) FindActiveByCustomerAndBook(customerID, bookID uuid.UUID) (*model.Hold, error) {
+	return nil, gorm.ErrRecordNotFound
+}
+
+type memoryBookRepo struct {
+	repository.BookRepository
+	book model.Book
+}
+
+func (r *memoryBookRepo) FindByID(id uuid.UUID) (*model.Book, error) {
+	if id != r.book.ID {
+		return nil, gorm.ErrRecordNotFound
+	}
+	book := r.book
+	return &book, nil
+}
+
+// alwaysOpen is a calendar without closed days
+type alwaysOpen struct{}
+
+func (alwaysOpen) ClosedDays(branchID *uuid.UUID, from, to time.Time) (ClosedDays, error) {
+	return WeeklyClosedDays(nil), nil
+}
+
+func TestCreateLendsACopyOnlyOnceUnderConcurrentCheckouts(t *testing.T) {
+	const desks = 20
+
+	db := newMemoryDB()
+	book := model.Book{ID: uuid.New(), Title: "Concurrency in Go"}
+	db.stocks["BK-0001"] = model.BookStock{Code: "BK-0001", BookID: book.ID, Status: model.StatusAvailable}
+
+	// Every desk serves a different customer, so the copy's row lock is all that keeps them apart
+	customerIDs := make([]uuid.UUID, desks)
+	for i := range customerIDs {
+		customerIDs[i] = uuid.New()
+		db.customers[customerIDs[i]] = model.Customer{ID: customerIDs[i], Status: model.CustomerStatusActive}
+	}
+
+	svc := NewBookTransactionService(nil, &memoryBookRepo{book: book}, nil, nil, &memoryUnitOfWork{db: db}, 3, alwaysOpen{}, EligibilityRules{}, 0)
+	actor := dto.UserData{ID: uuid.New(), Role: "admin"}
+
+	start := make(chan struct{})
+	errs := make([]error, desks)
+	var wg sync.WaitGroup
+	for i := 0; i < desks; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+			_, errs[i] = svc.Create(actor, dto.BookTransactionCreateRequest{
+				StockCode:  "BK-0001",
+				CustomerID: customerIDs[i],
+			})
+		}(i)
+	}
+	close(start)
+	wg.Wait()
+
+	var lent int
+	for _, err := range errs {
+		if err == nil {
+			lent++
+		} else if err.Error() != "book stock is not available" {
+			t.Errorf("unexpected checkout error: %v", err)
+		}
+	}
+	if lent != 1 {
+		t.Fatalf("expected exactly one successful checkout, got %d", lent)
+	}
+
+	if len(db.loans) != 1 {
+		t.Fatalf("expected exactly one loan, got %d", len(db.loans))
+	}
+	if db.loans[0].Status != model.StatusBTBorrowed {
+		t.Errorf("expected the loan to be %s, got %s", model.StatusBTBorrowed, db.loans[0].Status)
+	}
+	if status := db.stocks["BK-0001"].Status; status != model.StatusBorrowed {
+		t.Errorf("expected the copy to be %s, got %s", model.StatusBorrowed, status)
+	}
+
+	var borrowed int
+	for _, entry := range db.history {
+		if entry.ToStatus == model.StatusBorrowed {
+			borrowed++
+		}
+	}
+	if borrowed != 1 {
+		t.Errorf("expected the copy to be marked %s once, got %d times", model.StatusBorrowed, borrowed)
+	}
+}
+
+func TestCreateRollsBackWhenCheckoutFails(t *testing.T) {
+	db := newMemoryDB()
+	book := model.Book{ID: uuid.New(), Title: "Transactions"}
+	db.stocks["BK-0002"] = model.BookStock{Code: "BK-0002", BookID: book.ID, Status: model.StatusAvailable}
+
+	// The customer does not exist, so checkout fails after the copy has been locked
+	svc := NewBookTransactionService(nil, &memoryBookRepo{book: book}, nil, nil, &memoryUnitOfWork{db: db}, 3, alwaysOpen{}, EligibilityRules{}, 0)
+	_, err := svc.Create(dto.UserData{ID: uuid.New(), Role: "admin"}, dto.BookTransactionCreateRequest{
+		StockCode:  "BK-0002",
+		CustomerID: uuid.New(),
+	})
+	if err == nil {
+		t.Fatal("expected checkout for an unknown customer to fail")
+	}
+
+	if len(db.loans) != 0 || len(db.history) != 0 {
+		t.Errorf("expected nothing to be written, got %d loans and %d status changes", len(db.loans), len(db.history))
+	}
+	if status := db.stocks["BK-0002"].Status; status != model.StatusAvailable {
+		t.Errorf("expected the copy to stay %s, got %s", model.StatusAvailable, status)
+	}
+
+	// The copy is unlocked again, so the next checkout is not blocked
+	lock := db.rowLock("book_stock:BK-0002")
+	if !lock.TryLock() {
+		t.Fatal("expected the copy's row lock to be released")
+	}
+	lock.Unlock()
+}
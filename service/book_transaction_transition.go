@@ -0,0 +1,95 @@
+package service
+
+import (
+	"fmt"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+
+	"github.com/google/uuid"
+)
+
+// bookTransactionTransitions lists, per action, the statuses a loan may move to from each status.
+// A loan opens Borrowed, may become Overdue and closes as Returned, Lost or Damaged. Closed
+// loans only change through a Lost copy being returned or an explicit correction.
+var bookTransactionTransitions = map[string]map[string][]string{
+	model.LoanActionCheckout: {
+		"": {model.StatusBTBorrowed},
+	},
+	model.LoanActionReturn: {
+		model.StatusBTBorrowed: {model.StatusBTReturned},
+		model.StatusBTOverdue:  {model.StatusBTReturned},
+	},
+	model.LoanActionOverdue: {
+		model.StatusBTBorrowed: {model.StatusBTOverdue},
+	},
+	model.LoanActionUpdate: {
+		model.StatusBTBorrowed: {model.StatusBTOverdue},
+		model.StatusBTOverdue:  {model.StatusBTBorrowed},
+	},
+	model.LoanActionLoss: {
+		model.StatusBTBorrowed: {model.StatusBTLost, model.StatusBTDamaged},
+		model.StatusBTOverdue:  {model.StatusBTLost, model.StatusBTDamaged},
+	},
+	model.LoanActionFound: {
+		model.StatusBTLost: {model.StatusBTReturned},
+	},
+	model.LoanActionCorrection: {
+		model.StatusBTReturned: {model.StatusBTBorrowed},
+	},
+}
+
+// LoanTransitionError is returned when a book transaction status change is not allowed
+type LoanTransitionError struct {
+	ID     uuid.UUID
+	From   string
+	To     string
+	Action string
+}
+
+func (e *LoanTransitionError) Error() string {
+	return fmt.Sprintf("book transaction %s cannot move from %s to %s via %s", e.ID, e.From, e.To, e.Action)
+}
+
+// CanTransitionBookTransaction reports whether the action may move a loan between the statuses
+func CanTransitionBookTransaction(from, to, action string) bool {
+	for _, allowed := range bookTransactionTransitions[action][from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// isClosedLoan reports whether a loan status is final outside of corrections
+func isClosedLoan(status string) bool {
+	return status == model.StatusBTReturned || status == model.StatusBTLost || status == model.StatusBTDamaged
+}
+
+// transitionBookTransaction validates a status change and records it in the event history.
+// It only changes the status in memory; the caller saves the loan with its other changes.
+func transitionBookTransaction(repo repository.BookTransactionRepository, transaction *model.BookTransaction, to, action, reason string, userID *uuid.UUID) error {
+	from := transaction.Status
+	if !CanTransitionBookTransaction(from, to, action) {
+		return &LoanTransitionError{ID: transaction.ID, From: from, To: to, Action: action}
+	}
+
+	if err := recordBookTransactionEvent(repo, transaction, to, action, reason, userID); err != nil {
+		return err
+	}
+
+	transaction.Status = to
+	return nil
+}
+
+// recordBookTransactionEvent adds an entry to the event history of a loan without checking the transition
+func recordBookTransactionEvent(repo repository.BookTransactionRepository, transaction *model.BookTransaction, to, action, reason string, userID *uuid.UUID) error {
+	return repo.CreateEvents([]model.BookTransactionEvent{{
+		ID:                uuid.New(),
+		BookTransactionID: transaction.ID,
+		FromStatus:        transaction.Status,
+		ToStatus:          to,
+		Action:            action,
+		Reason:            reason,
+		UserID:            userID,
+	}})
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+// ErrBranchAccessDenied is returned when branch staff act on copies or records of another branch
+var ErrBranchAccessDenied = errors.New("access denied for this branch")
+
+type BranchService interface {
+	GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.BranchResponse], error)
+	GetByID(id uuid.UUID) (*dto.BranchResponse, error)
+	Create(req dto.BranchCreateRequest) (*dto.BranchResponse, error)
+	Update(id uuid.UUID, req dto.BranchUpdateRequest) (*dto.BranchResponse, error)
+	Delete(id uuid.UUID) error
+}
+
+type branchService struct {
+	repository repository.BranchRepository
+}
+
+func NewBranchService(repository repository.BranchRepository) BranchService {
+	return &branchService{
+		repository: repository,
+	}
+}
+
+func (s *branchService) GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.BranchResponse], error) {
+	branches, total, err := s.repository.FindAll(page, perPage, search, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	branchResponses := make([]dto.BranchResponse, 0)
+	for _, branch := range branches {
+		branchResponses = append(branchResponses, mapToBranchResponse(&branch))
+	}
+
+	// Calculate total pages
+	totalPages := int64(total) / int64(perPage)
+	if int64(total)%int64(perPage) > 0 {
+		totalPages++
+	}
+
+	return &dto.PaginatedResponseData[[]dto.BranchResponse]{
+		Status:  200,
+		Message: "Branches retrieved successfully",
+		Data:    branchResponses,
+		Meta: dto.PaginationMeta{
+			Page:        page,
+			PerPage:     perPage,
+			TotalItems:  total,
+			TotalPages:  totalPages,
+			ItemsOnPage: int64(len(branchResponses)),
+		},
+	}, nil
+}
+
+func (s *branchService) GetByID(id uuid.UUID) (*dto.BranchResponse, error) {
+	branch, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("branch not found")
+	}
+
+	response := mapToBranchResponse(branch)
+	return &response, nil
+}
+
+func (s *branchService) Create(req dto.BranchCreateRequest) (*dto.BranchResponse, error) {
+	code := strings.ToUpper(req.Code)
+
+	// Check if code already exists
+	if existing, err := s.repository.FindByCode(code); err == nil && existing != nil {
+		return nil, errors.New("branch code already exists")
+	}
+
+	branch := model.Branch{
+		ID:      uuid.New(),
+		Code:    code,
+		Name:    req.Name,
+		Address: req.Address,
+	}
+
+	if err := s.repository.Create(&branch); err != nil {
+		return nil, err
+	}
+
+	response := mapToBranchResponse(&branch)
+	return &response, nil
+}
+
+func (s *branchService) Update(id uuid.UUID, req dto.BranchUpdateRequest) (*dto.BranchResponse, error) {
+	branch, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("branch not found")
+	}
+
+	if req.Code != nil {
+		code := strings.ToUpper(*req.Code)
+		if code != branch.Code {
+			if existing, err := s.repository.FindByCode(code); err == nil && existing != nil {
+				return nil, errors.New("branch code already exists")
+			}
+			branch.Code = code
+		}
+	}
+	if req.Name != nil {
+		branch.Name = *req.Name
+	}
+	if req.Address != nil {
+		branch.Address = *req.Address
+	}
+
+	if err := s.repository.Update(branch); err != nil {
+		return nil, err
+	}
+
+	response := mapToBranchResponse(branch)
+	return &response, nil
+}
+
+func (s *branchService) Delete(id uuid.UUID) error {
+	if _, err := s.repository.FindByID(id); err != nil {
+		return errors.New("branch not found")
+	}
+
+	// Branches still holding copies or staff cannot be removed
+	used, err := s.repository.IsBranchUsed(id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return errors.New("branch still has book stocks or staff assigned")
+	}
+
+	return s.repository.Delete(id)
+}
+
+// checkBranchAccess rejects branch staff acting on something located at another branch.
+// Staff without a branch may act everywhere.
+func checkBranchAccess(actor dto.UserData, branchID *uuid.UUID) error {
+	if actor.BranchID == nil {
+		return nil
+	}
+	if branchID == nil || *branchID != *actor.BranchID {
+		return ErrBranchAccessDenied
+	}
+	return nil
+}
+
+// resolveActorBranch returns the requested branch, defaulting to the actor's own branch.
+// Branch staff may only pick their own branch.
+func resolveActorBranch(actor dto.UserData, branchID *uuid.UUID) (*uuid.UUID, error) {
+	if branchID == nil {
+		return actor.BranchID, nil
+	}
+	if err := checkBranchAccess(actor, branchID); err != nil {
+		return nil, err
+	}
+	return branchID, nil
+}
+
+// Helper function to map a Branch entity to a BranchResponse DTO
+func mapToBranchResponse(branch *model.Branch) dto.BranchResponse {
+	return dto.BranchResponse{
+		ID:        branch.ID,
+		Code:      branch.Code,
+		Name:      branch.Name,
+		Address:   branch.Address,
+		CreatedAt: branch.CreatedAt,
+		UpdatedAt: branch.UpdatedAt,
+	}
+}
@@ -0,0 +1,285 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type BranchTransferService interface {
+	GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.BranchTransferResponse], error)
+	GetByID(id uuid.UUID) (*dto.BranchTransferResponse, error)
+	Request(actor dto.UserData, req dto.BranchTransferCreateRequest) (*dto.BranchTransferResponse, error)
+	Ship(id uuid.UUID, actor dto.UserData) (*dto.BranchTransferResponse, error)
+	Receive(id uuid.UUID, actor dto.UserData) (*dto.BranchTransferResponse, error)
+	Cancel(id uuid.UUID, actor dto.UserData) (*dto.BranchTransferResponse, error)
+}
+
+type branchTransferService struct {
+	repository repository.BranchTransferRepository
+	branchRepo repository.BranchRepository
+	uow        repository.UnitOfWork
+}
+
+func NewBranchTransferService(
+	repository repository.BranchTransferRepository,
+	branchRepo repository.BranchRepository,
+	uow repository.UnitOfWork,
+) BranchTransferService {
+	return &branchTransferService{
+		repository: repository,
+		branchRepo: branchRepo,
+		uow:        uow,
+	}
+}
+
+func (s *branchTransferService) GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.BranchTransferResponse], error) {
+	transfers, total, err := s.repository.FindAll(page, perPage, search, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	transferResponses := make([]dto.BranchTransferResponse, 0)
+	for _, transfer := range transfers {
+		transferResponses = append(transferResponses, mapToBranchTransferResponse(&transfer))
+	}
+
+	// Calculate total pages
+	totalPages := int64(total) / int64(perPage)
+	if int64(total)%int64(perPage) > 0 {
+		totalPages++
+	}
+
+	return &dto.PaginatedResponseData[[]dto.BranchTransferResponse]{
+		Status:  200,
+		Message: "Transfers retrieved successfully",
+		Data:    transferResponses,
+		Meta: dto.PaginationMeta{
+			Page:        page,
+			PerPage:     perPage,
+			TotalItems:  total,
+			TotalPages:  totalPages,
+			ItemsOnPage: int64(len(transferResponses)),
+		},
+	}, nil
+}
+
+func (s *branchTransferService) GetByID(id uuid.UUID) (*dto.BranchTransferResponse, error) {
+	transfer, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("transfer not found")
+	}
+
+	response := mapToBranchTransferResponse(transfer)
+	return &response, nil
+}
+
+func (s *branchTransferService) Request(actor dto.UserData, req dto.BranchTransferCreateRequest) (*dto.BranchTransferResponse, error) {
+	if _, err := s.branchRepo.FindByID(req.ToBranchID); err != nil {
+		return nil, errors.New("branch not found")
+	}
+
+	transfer := model.BranchTransfer{
+		ID:          uuid.New(),
+		StockCode:   req.StockCode,
+		ToBranchID:  req.ToBranchID,
+		Status:      model.StatusTransferRequested,
+		Notes:       req.Notes,
+		RequestedBy: actor.ID,
+		RequestedAt: time.Now(),
+	}
+
+	// The copy stays locked until the transfer is inserted, so two requests for it cannot
+	// both pass the open transfer check
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		bookStock, err := repos.BookStocks.FindByCodeForUpdate(req.StockCode)
+		if err != nil {
+			return ErrBookStockNotFound
+		}
+
+		if bookStock.CurrentBranchID == nil {
+			return errors.New("book stock is not located at any branch")
+		}
+
+		if *bookStock.CurrentBranchID == req.ToBranchID {
+			return errors.New("book stock is already at this branch")
+		}
+
+		// Either the sending or the receiving branch may ask for the copy to move
+		if checkBranchAccess(actor, bookStock.CurrentBranchID) != nil && checkBranchAccess(actor, &req.ToBranchID) != nil {
+			return ErrBranchAccessDenied
+		}
+
+		if _, err := repos.BranchTransfers.FindOpenByStockCode(bookStock.Code); err == nil {
+			return fmt.Errorf("book stock %s already has an open transfer", bookStock.Code)
+		}
+
+		transfer.FromBranchID = *bookStock.CurrentBranchID
+		return repos.BranchTransfers.Create(&transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(transfer.ID)
+}
+
+func (s *branchTransferService) Ship(id uuid.UUID, actor dto.UserData) (*dto.BranchTransferResponse, error) {
+	// The transfer and the copy are locked so concurrent requests cannot ship the same transfer twice
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		transfer, err := repos.BranchTransfers.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("transfer not found")
+		}
+
+		if transfer.Status != model.StatusTransferRequested {
+			return fmt.Errorf("transfer is %s and cannot be shipped", transfer.Status)
+		}
+
+		// Only the branch holding the copy can send it off
+		if err := checkBranchAccess(actor, &transfer.FromBranchID); err != nil {
+			return err
+		}
+
+		bookStock, err := repos.BookStocks.FindByCodeForUpdate(transfer.StockCode)
+		if err != nil {
+			return ErrBookStockNotFound
+		}
+
+		if bookStock.CurrentBranchID == nil || *bookStock.CurrentBranchID != transfer.FromBranchID {
+			return errors.New("book stock is no longer at the sending branch")
+		}
+
+		reason := fmt.Sprintf("Shipped to branch %s", transfer.ToBranch.Code)
+		if err := transitionBookStock(repos.BookStocks, bookStock, model.StatusInTransit, model.StockActionTransfer, reason, &actor.ID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		transfer.Status = model.StatusTransferInTransit
+		transfer.ShippedBy = &actor.ID
+		transfer.ShippedAt = &now
+
+		return repos.BranchTransfers.Update(transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(id)
+}
+
+func (s *branchTransferService) Receive(id uuid.UUID, actor dto.UserData) (*dto.BranchTransferResponse, error) {
+	// The status change, the new location and the transfer record are written together,
+	// so a copy never ends up Available at the old branch or In Transit at the new one
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		transfer, err := repos.BranchTransfers.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("transfer not found")
+		}
+
+		if transfer.Status != model.StatusTransferInTransit {
+			return fmt.Errorf("transfer is %s and cannot be received", transfer.Status)
+		}
+
+		// Only the destination branch can confirm arrival
+		if err := checkBranchAccess(actor, &transfer.ToBranchID); err != nil {
+			return err
+		}
+
+		bookStock, err := repos.BookStocks.FindByCodeForUpdate(transfer.StockCode)
+		if err != nil {
+			return ErrBookStockNotFound
+		}
+
+		reason := fmt.Sprintf("Received from branch %s", transfer.FromBranch.Code)
+		if err := transitionBookStock(repos.BookStocks, bookStock, model.StatusAvailable, model.StockActionTransfer, reason, &actor.ID); err != nil {
+			return err
+		}
+
+		if err := repos.BookStocks.UpdateCurrentBranch(bookStock.Code, transfer.ToBranchID); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		transfer.Status = model.StatusTransferReceived
+		transfer.ReceivedBy = &actor.ID
+		transfer.ReceivedAt = &now
+
+		return repos.BranchTransfers.Update(transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(id)
+}
+
+func (s *branchTransferService) Cancel(id uuid.UUID, actor dto.UserData) (*dto.BranchTransferResponse, error) {
+	// Locked so a transfer cannot be cancelled while it is being shipped
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		transfer, err := repos.BranchTransfers.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("transfer not found")
+		}
+
+		// Copies already on the road have to be received first
+		if transfer.Status != model.StatusTransferRequested {
+			return fmt.Errorf("transfer is %s and cannot be cancelled", transfer.Status)
+		}
+
+		if checkBranchAccess(actor, &transfer.FromBranchID) != nil && checkBranchAccess(actor, &transfer.ToBranchID) != nil {
+			return ErrBranchAccessDenied
+		}
+
+		transfer.Status = model.StatusTransferCancelled
+
+		return repos.BranchTransfers.Update(transfer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(id)
+}
+
+// Helper function to map a BranchTransfer entity to a BranchTransferResponse DTO
+func mapToBranchTransferResponse(transfer *model.BranchTransfer) dto.BranchTransferResponse {
+	response := dto.BranchTransferResponse{
+		ID:           transfer.ID,
+		StockCode:    transfer.StockCode,
+		FromBranchID: transfer.FromBranchID,
+		ToBranchID:   transfer.ToBranchID,
+		Status:       transfer.Status,
+		Notes:        transfer.Notes,
+		RequestedBy:  transfer.RequestedBy,
+		RequestedAt:  transfer.RequestedAt,
+		ShippedBy:    transfer.ShippedBy,
+		ShippedAt:    transfer.ShippedAt,
+		ReceivedBy:   transfer.ReceivedBy,
+		ReceivedAt:   transfer.ReceivedAt,
+	}
+
+	if transfer.BookStock.Code != "" {
+		bookStock := mapToBookStockResponse(&transfer.BookStock)
+		response.BookStock = &bookStock
+	}
+
+	if transfer.FromBranch.ID != uuid.Nil {
+		fromBranch := mapToBranchResponse(&transfer.FromBranch)
+		response.FromBranch = &fromBranch
+	}
+
+	if transfer.ToBranch.ID != uuid.Nil {
+		toBranch := mapToBranchResponse(&transfer.ToBranch)
+		response.ToBranch = &toBranch
+	}
+
+	return response
+}
@@ -0,0 +1,372 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Calendar tells on which days each branch of the library is closed
+type Calendar interface {
+	// ClosedDays returns the closed days of a branch between from and to, loaded once so callers
+	// can ask day by day. Without a branch only library-wide hours and closures apply.
+	ClosedDays(branchID *uuid.UUID, from, to time.Time) (ClosedDays, error)
+}
+
+type CalendarService interface {
+	Calendar
+	GetOpeningHours(branchID *uuid.UUID) ([]dto.OpeningHoursResponse, error)
+	SetOpeningHours(actor dto.UserData, req dto.OpeningHoursRequest) ([]dto.OpeningHoursResponse, error)
+	GetClosures(branchID *uuid.UUID, from, to string) ([]dto.ClosureResponse, error)
+	CreateClosure(actor dto.UserData, req dto.ClosureCreateRequest) (*dto.ClosureResponse, error)
+	DeleteClosure(actor dto.UserData, id uuid.UUID) error
+	GetDays(branchID *uuid.UUID, from, to string) ([]dto.CalendarDayResponse, error)
+}
+
+// ErrInvalidCalendar is returned when opening hours or closures do not make sense
+var ErrInvalidCalendar = errors.New("invalid calendar")
+
+// Longest span the calendar is read or searched for an open day
+const maxCalendarDays = 366
+
+// Where the hours of a weekday come from
+const (
+	hoursSourceBranch  = "branch"
+	hoursSourceLibrary = "library"
+	hoursSourceDefault = "default"
+)
+
+type calendarService struct {
+	repository repository.CalendarRepository
+	// Weekdays closed where no opening hours are set
+	defaultClosed WeeklyClosedDays
+}
+
+func NewCalendarService(repository repository.CalendarRepository, defaultClosed WeeklyClosedDays) CalendarService {
+	return &calendarService{
+		repository:    repository,
+		defaultClosed: defaultClosed,
+	}
+}
+
+// weekdayHours are the effective hours of one weekday at a branch
+type weekdayHours struct {
+	OpensAt  string
+	ClosesAt string
+	Closed   bool
+	Source   string
+}
+
+// calendarDays is the calendar of a branch over a date range
+type calendarDays struct {
+	week     [7]weekdayHours
+	closures []model.Closure
+}
+
+func (d *calendarDays) IsClosed(day time.Time) bool {
+	_, closed := d.closedReason(day)
+	return closed
+}
+
+// closedReason tells whether the branch is closed on a day and why
+func (d *calendarDays) closedReason(day time.Time) (string, bool) {
+	date := day.Format(model.CalendarDateLayout)
+	for _, closure := range d.closures {
+		if closure.StartDate <= date && date <= closure.EndDate {
+			return closure.Reason, true
+		}
+	}
+	if d.week[day.Weekday()].Closed {
+		return "closed on " + day.Weekday().String(), true
+	}
+	return "", false
+}
+
+func (s *calendarService) ClosedDays(branchID *uuid.UUID, from, to time.Time) (ClosedDays, error) {
+	week, err := s.week(branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	closures, err := s.repository.FindClosuresBetween(branchID, from.Format(model.CalendarDateLayout), to.Format(model.CalendarDateLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	return &calendarDays{week: week, closures: closures}, nil
+}
+
+// week resolves the hours of every weekday: branch hours first, then library-wide hours,
+// then the configured closed weekdays
+func (s *calendarService) week(branchID *uuid.UUID) ([7]weekdayHours, error) {
+	var week [7]weekdayHours
+	for weekday := range week {
+		week[weekday] = weekdayHours{Closed: s.defaultClosed.IsClosed(weekdayDate(time.Weekday(weekday))), Source: hoursSourceDefault}
+	}
+
+	hours, err := s.repository.FindOpeningHours(branchID)
+	if err != nil {
+		return week, err
+	}
+
+	// Library-wide rows first so branch rows replace them
+	for _, branchRows := range []bool{false, true} {
+		for _, row := range hours {
+			if (row.BranchID != nil) != branchRows || row.Weekday < 0 || row.Weekday > 6 {
+				continue
+			}
+			source := hoursSourceLibrary
+			if branchRows {
+				source = hoursSourceBranch
+			}
+			week[row.Weekday] = weekdayHours{OpensAt: row.OpensAt, ClosesAt: row.ClosesAt, Closed: row.Closed, Source: source}
+		}
+	}
+	return week, nil
+}
+
+func (s *calendarService) GetOpeningHours(branchID *uuid.UUID) ([]dto.OpeningHoursResponse, error) {
+	week, err := s.week(branchID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.OpeningHoursResponse, 0, len(week))
+	for weekday, hours := range week {
+		responses = append(responses, dto.OpeningHoursResponse{
+			Weekday:  time.Weekday(weekday).String(),
+			OpensAt:  hours.OpensAt,
+			ClosesAt: hours.ClosesAt,
+			Closed:   hours.Closed,
+			Source:   hours.Source,
+		})
+	}
+	return responses, nil
+}
+
+// SetOpeningHours replaces the weekly hours of a branch, or the library-wide hours
+func (s *calendarService) SetOpeningHours(actor dto.UserData, req dto.OpeningHoursRequest) ([]dto.OpeningHoursResponse, error) {
+	// Branch staff manage the hours of their own branch only
+	if err := checkBranchAccess(actor, req.BranchID); err != nil {
+		return nil, err
+	}
+
+	seen := make(map[time.Weekday]bool, len(req.Days))
+	hours := make([]model.OpeningHours, 0, len(req.Days))
+	for _, day := range req.Days {
+		weekday, err := ParseWeeklyClosedDays(day.Weekday)
+		if err != nil || len(weekday) != 1 {
+			return nil, fmt.Errorf("%w: unknown weekday %q", ErrInvalidCalendar, day.Weekday)
+		}
+		if seen[weekday[0]] {
+			return nil, fmt.Errorf("%w: %s listed twice", ErrInvalidCalendar, day.Weekday)
+		}
+		seen[weekday[0]] = true
+
+		row := model.OpeningHours{
+			ID:       uuid.New(),
+			BranchID: req.BranchID,
+			Weekday:  int(weekday[0]),
+			Closed:   day.Closed,
+		}
+		if !day.Closed {
+			// HH:MM strings compare in time order
+			if day.OpensAt == "" || day.ClosesAt == "" || day.OpensAt >= day.ClosesAt {
+				return nil, fmt.Errorf("%w: %s needs an opening time before its closing time", ErrInvalidCalendar, day.Weekday)
+			}
+			row.OpensAt = day.OpensAt
+			row.ClosesAt = day.ClosesAt
+		}
+		hours = append(hours, row)
+	}
+
+	if err := s.repository.ReplaceOpeningHours(req.BranchID, hours); err != nil {
+		return nil, err
+	}
+
+	return s.GetOpeningHours(req.BranchID)
+}
+
+func (s *calendarService) GetClosures(branchID *uuid.UUID, from, to string) ([]dto.ClosureResponse, error) {
+	fromDate, toDate, err := parseCalendarRange(from, to, false)
+	if err != nil {
+		return nil, err
+	}
+
+	closures, err := s.repository.FindClosuresBetween(branchID, fromDate.Format(model.CalendarDateLayout), toDate.Format(model.CalendarDateLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ClosureResponse, 0, len(closures))
+	for _, closure := range closures {
+		responses = append(responses, mapToClosureResponse(&closure))
+	}
+	return responses, nil
+}
+
+func (s *calendarService) CreateClosure(actor dto.UserData, req dto.ClosureCreateRequest) (*dto.ClosureResponse, error) {
+	// Library-wide closures need staff not bound to a branch
+	if err := checkBranchAccess(actor, req.BranchID); err != nil {
+		return nil, err
+	}
+
+	endDate := req.EndDate
+	if endDate == "" {
+		endDate = req.StartDate
+	}
+	start, end, err := parseCalendarRange(req.StartDate, endDate, true)
+	if err != nil {
+		return nil, err
+	}
+
+	closure := model.Closure{
+		ID:        uuid.New(),
+		BranchID:  req.BranchID,
+		StartDate: start.Format(model.CalendarDateLayout),
+		EndDate:   end.Format(model.CalendarDateLayout),
+		Reason:    req.Reason,
+		UserID:    actor.ID,
+		CreatedAt: time.Now(),
+	}
+	if err := s.repository.CreateClosure(&closure); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repository.FindClosureByID(closure.ID)
+	if err != nil {
+		return nil, err
+	}
+	response := mapToClosureResponse(created)
+	return &response, nil
+}
+
+func (s *calendarService) DeleteClosure(actor dto.UserData, id uuid.UUID) error {
+	closure, err := s.repository.FindClosureByID(id)
+	if err != nil {
+		return errors.New("closure not found")
+	}
+	if err := checkBranchAccess(actor, closure.BranchID); err != nil {
+		return err
+	}
+
+	return s.repository.DeleteClosure(id)
+}
+
+// GetDays lists whether a branch is open on each date of a range
+func (s *calendarService) GetDays(branchID *uuid.UUID, from, to string) ([]dto.CalendarDayResponse, error) {
+	fromDate, toDate, err := parseCalendarRange(from, to, false)
+	if err != nil {
+		return nil, err
+	}
+
+	closed, err := s.ClosedDays(branchID, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+	days := closed.(*calendarDays)
+
+	var responses []dto.CalendarDayResponse
+	for day := fromDate; !day.After(toDate); day = day.AddDate(0, 0, 1) {
+		hours := days.week[day.Weekday()]
+		response := dto.CalendarDayResponse{
+			Date:    day.Format(model.CalendarDateLayout),
+			Weekday: day.Weekday().String(),
+			Open:    true,
+		}
+		if reason, isClosed := days.closedReason(day); isClosed {
+			response.Open = false
+			response.Reason = reason
+		} else {
+			response.OpensAt = hours.OpensAt
+			response.ClosesAt = hours.ClosesAt
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// parseCalendarRange parses a YYYY-MM-DD date range. Without required dates the range defaults
+// to the coming 30 days, or 30 days from its start.
+func parseCalendarRange(from, to string, required bool) (time.Time, time.Time, error) {
+	now := time.Now()
+	fromDate := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	if from != "" || required {
+		parsed, err := time.ParseInLocation(model.CalendarDateLayout, from, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: dates must be formatted as YYYY-MM-DD", ErrInvalidCalendar)
+		}
+		fromDate = parsed
+	}
+
+	toDate := fromDate.AddDate(0, 0, 30)
+	if to != "" || required {
+		parsed, err := time.ParseInLocation(model.CalendarDateLayout, to, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: dates must be formatted as YYYY-MM-DD", ErrInvalidCalendar)
+		}
+		toDate = parsed
+	}
+
+	if toDate.Before(fromDate) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: the range ends before it starts", ErrInvalidCalendar)
+	}
+	if toDate.Sub(fromDate) > maxCalendarDays*24*time.Hour {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: the range spans more than %d days", ErrInvalidCalendar, maxCalendarDays)
+	}
+	return fromDate, toDate, nil
+}
+
+// nextOpenDay moves a due date forward to the first day the branch is open, keeping its time of day
+func nextOpenDay(calendar Calendar, branchID *uuid.UUID, day time.Time) (time.Time, error) {
+	closed, err := calendar.ClosedDays(branchID, day, day.AddDate(0, 0, maxCalendarDays))
+	if err != nil {
+		return day, err
+	}
+
+	for i := 0; i < maxCalendarDays; i++ {
+		candidate := day.AddDate(0, 0, i)
+		if !closed.IsClosed(candidate) {
+			return candidate, nil
+		}
+	}
+	// A branch closed all year keeps the original due date rather than none
+	return day, nil
+}
+
+// loanClosedDays returns the days left out of a loan's late fee, or nil when its terms count every day
+func loanClosedDays(calendar Calendar, transaction *model.BookTransaction, asOf time.Time) (ClosedDays, error) {
+	if !transaction.SkipClosedDays || calendar == nil || !asOf.After(transaction.DueDate) {
+		return nil, nil
+	}
+	return calendar.ClosedDays(transaction.BranchID, transaction.DueDate, asOf)
+}
+
+// weekdayDate returns a date falling on the weekday, to ask a ClosedDays about weekdays
+func weekdayDate(weekday time.Weekday) time.Time {
+	// 4 January 1970 was a Sunday
+	return time.Date(1970, 1, 4+int(weekday), 12, 0, 0, 0, time.Local)
+}
+
+func mapToClosureResponse(closure *model.Closure) dto.ClosureResponse {
+	response := dto.ClosureResponse{
+		ID:        closure.ID,
+		BranchID:  closure.BranchID,
+		StartDate: closure.StartDate,
+		EndDate:   closure.EndDate,
+		Reason:    strings.TrimSpace(closure.Reason),
+		UserID:    closure.UserID,
+		CreatedAt: closure.CreatedAt,
+	}
+	if closure.Branch != nil {
+		branch := mapToBranchResponse(closure.Branch)
+		response.Branch = &branch
+	}
+	return response
+}
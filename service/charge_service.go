@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"go-gin-simple-api/dto"
 	"go-gin-simple-api/lib"
 	"go-gin-simple-api/model"
@@ -19,23 +20,36 @@ type ChargeService interface {
 	Create(userEmail string, req dto.ChargeCreateRequest) (*dto.ChargeResponse, error)
 	Update(id uuid.UUID, req dto.ChargeUpdateRequest) (*dto.ChargeResponse, error)
 	Delete(id uuid.UUID) error
+	Preview(req dto.ChargePreviewRequest) (*dto.ChargePreviewResponse, error)
 }
 
+// ErrChargeFrozen is returned when changing a charge of a closed loan
+var ErrChargeFrozen = errors.New("charge is frozen because the loan is closed")
+
+// ErrDuplicateLateFee is returned when charging a late fee for a loan that already has one
+var ErrDuplicateLateFee = errors.New("loan already has a late fee")
+
 type chargeService struct {
 	repository          repository.ChargeRepository
 	bookTransactionRepo repository.BookTransactionRepository
 	userRepo            repository.AuthRepository
+	uow                 repository.UnitOfWork
+	calendar            Calendar
 }
 
 func NewChargeService(
 	repository repository.ChargeRepository,
 	bookTransactionRepo repository.BookTransactionRepository,
 	userRepo repository.AuthRepository,
+	uow repository.UnitOfWork,
+	calendar Calendar,
 ) ChargeService {
 	return &chargeService{
 		repository:          repository,
 		bookTransactionRepo: bookTransactionRepo,
 		userRepo:            userRepo,
+		uow:                 uow,
+		calendar:            calendar,
 	}
 }
 
@@ -109,45 +123,70 @@ func (s *chargeService) GetByUserID(userID uuid.UUID) ([]dto.ChargeResponse, err
 }
 
 func (s *chargeService) Create(userEmail string, req dto.ChargeCreateRequest) (*dto.ChargeResponse, error) {
-	// Check if book transactionData exists
-	transactionData, err := s.bookTransactionRepo.FindByID(req.BookTransactionID)
-	if err != nil {
-		return nil, errors.New("book transaction not found")
-	}
-
 	// Check if user exists
 	user, errExists := s.userRepo.FindByEmail(userEmail)
 	if errExists != nil {
 		return nil, errors.New("user not found")
 	}
 
-	// Calculate total charge
-	// total := float64(req.DaysLate) * req.DailyLateFee
-
-	var daysLate int
-	duration := time.Since(transactionData.DueDate)
-	if duration <= 0 {
-		return nil, errors.New("transaction is not late")
-	} else {
-		daysLate = int(duration.Hours() / 24)
-		if duration.Hours()/24 > float64(daysLate) {
-			daysLate++
-		}
-	}
-
-	total := float64(daysLate) * req.DailyLateFee
-
 	charge := model.Charge{
 		ID:                uuid.New(),
-		BookTransactionID: transactionData.ID,
-		DaysLate:          daysLate,
-		DailyLateFee:      req.DailyLateFee,
-		Total:             total,
+		BookTransactionID: req.BookTransactionID,
+		Type:              model.ChargeTypeLateFee,
 		UserID:            user.ID,
 		CreatedAt:         time.Now(),
 	}
 
-	if err := s.repository.Create(&charge); err != nil {
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		// Lock the loan so a concurrent return or charge cannot add a second late fee
+		transactionData, err := repos.BookTransactions.FindByIDForUpdate(req.BookTransactionID)
+		if err != nil {
+			return errors.New("book transaction not found")
+		}
+
+		charges, err := repos.Charges.FindByBookTransactionID(transactionData.ID)
+		if err != nil {
+			return err
+		}
+		for _, existing := range charges {
+			if existing.Type == model.ChargeTypeLateFee {
+				return ErrDuplicateLateFee
+			}
+		}
+
+		// Closed loans are settled at return; charging one afterwards has to be deliberate
+		if isClosedLoan(transactionData.Status) && !req.Override {
+			return fmt.Errorf("%w: set override to charge a %s loan", ErrLoanClosed, transactionData.Status)
+		}
+
+		// A returned loan is charged up to its return date, an open one up to now
+		asOf := lateFeeAsOf(transactionData)
+		if !asOf.After(transactionData.DueDate) {
+			return errors.New("transaction is not late")
+		}
+
+		// Use the fee rate of the loan policy unless one is given explicitly
+		dailyLateFee := transactionData.DailyLateFee
+		if req.DailyLateFee != nil {
+			dailyLateFee = *req.DailyLateFee
+		}
+
+		closed, err := loanClosedDays(s.calendar, transactionData, asOf)
+		if err != nil {
+			return err
+		}
+		fee := assessLateFee(transactionData, dailyLateFee, asOf, closed)
+
+		charge.DaysLate = fee.DaysLate
+		charge.DailyLateFee = fee.DailyLateFee
+		charge.Total = fee.Total
+		if transactionData.ReturnAt != nil {
+			charge.FrozenAt = &charge.CreatedAt
+		}
+
+		return repos.Charges.Create(&charge)
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -168,26 +207,36 @@ func (s *chargeService) Update(id uuid.UUID, req dto.ChargeUpdateRequest) (*dto.
 		return nil, errors.New("charge not found")
 	}
 
-	// Update fields if provided
-	var daysLate int
-	// charge.DaysLate = *req.DaysLate
-	duration := time.Since(charge.BookTransaction.DueDate)
-	if duration <= 0 {
-		return nil, errors.New("transaction is not late")
-	} else {
-		daysLate = int(duration.Hours() / 24)
-		if duration.Hours()/24 > float64(daysLate) {
-			daysLate++
-		}
+	if charge.FrozenAt != nil {
+		return nil, ErrChargeFrozen
+	}
+	if charge.Type != model.ChargeTypeLateFee {
+		return nil, errors.New("only late fees can be recalculated")
 	}
 
+	// Update fields if provided
 	if req.DailyLateFee != nil {
 		charge.DailyLateFee = *req.DailyLateFee
 	}
 
-	// Recalculate total
-	// charge.Total = float64(charge.DaysLate) * charge.DailyLateFee
-	charge.Total = float64(daysLate) * charge.DailyLateFee
+	// Recalculate up to the return date, so a charge edited after the return stops growing
+	transaction := &charge.BookTransaction
+	asOf := lateFeeAsOf(transaction)
+	if !asOf.After(transaction.DueDate) {
+		return nil, errors.New("transaction is not late")
+	}
+
+	closed, err := loanClosedDays(s.calendar, transaction, asOf)
+	if err != nil {
+		return nil, err
+	}
+	fee := assessLateFee(transaction, charge.DailyLateFee, asOf, closed)
+	charge.DaysLate = fee.DaysLate
+	charge.Total = fee.Total
+	if transaction.ReturnAt != nil {
+		now := time.Now()
+		charge.FrozenAt = &now
+	}
 
 	if err := s.repository.Update(charge); err != nil {
 		return nil, err
@@ -199,27 +248,82 @@ func (s *chargeService) Update(id uuid.UUID, req dto.ChargeUpdateRequest) (*dto.
 
 func (s *chargeService) Delete(id uuid.UUID) error {
 	// Check if charge exists
-	_, err := s.repository.FindByID(id)
+	charge, err := s.repository.FindByID(id)
 	if err != nil {
 		return errors.New("charge not found")
 	}
 
+	// The ledger must stay consistent with the payments taken
+	if len(charge.Payments) > 0 || len(charge.Adjustments) > 0 {
+		return errors.New("charge has payments or adjustments and cannot be deleted")
+	}
+
 	// Delete charge
 	return s.repository.Delete(id)
 }
 
+// Preview calculates the late fee of a loan as Create or Update would, without saving anything
+func (s *chargeService) Preview(req dto.ChargePreviewRequest) (*dto.ChargePreviewResponse, error) {
+	transaction, err := s.bookTransactionRepo.FindByID(req.BookTransactionID)
+	if err != nil {
+		return nil, errors.New("book transaction not found")
+	}
+
+	asOf := lateFeeAsOf(transaction)
+	if req.AsOf != nil {
+		asOf = *req.AsOf
+	}
+
+	dailyLateFee := transaction.DailyLateFee
+	if req.DailyLateFee != nil {
+		dailyLateFee = *req.DailyLateFee
+	}
+
+	closed, err := loanClosedDays(s.calendar, transaction, asOf)
+	if err != nil {
+		return nil, err
+	}
+	fee := assessLateFee(transaction, dailyLateFee, asOf, closed)
+
+	return &dto.ChargePreviewResponse{
+		BookTransactionID: transaction.ID,
+		DueDate:           transaction.DueDate,
+		ReturnAt:          transaction.ReturnAt,
+		AsOf:              asOf,
+		DaysLate:          fee.DaysLate,
+		DailyLateFee:      fee.DailyLateFee,
+		GracePeriodDays:   transaction.GracePeriodDays,
+		MaxLateFee:        transaction.MaxLateFee,
+		Total:             fee.Total,
+		Frozen:            transaction.ReturnAt != nil,
+	}, nil
+}
+
 // Helper function to map a Charge entity to a ChargeResponse DTO
 func mapToChargeResponse(charge *model.Charge) dto.ChargeResponse {
 	response := dto.ChargeResponse{
 		ID:                charge.ID,
 		BookTransactionID: charge.BookTransactionID,
+		Type:              charge.Type,
 		DaysLate:          charge.DaysLate,
 		DailyLateFee:      charge.DailyLateFee,
 		Total:             charge.Total,
 		UserID:            charge.UserID,
 		CreatedAt:         charge.CreatedAt,
+		FrozenAt:          charge.FrozenAt,
+		// Replacement charges
+		ReplacementCost: charge.ReplacementCost,
+		ProcessingFee:   charge.ProcessingFee,
+		ReversedAt:      charge.ReversedAt,
 	}
 
+	ledger := summarizeCharge(charge)
+	response.Paid = ledger.Paid
+	response.Refunded = ledger.Refunded
+	response.Waived = ledger.Waived
+	response.Balance = ledger.Balance
+	response.PaymentStatus = ledger.Status
+
 	if charge.BookTransaction.ID != uuid.Nil {
 		bookTransaction := mapToBookTransactionResponse(&charge.BookTransaction)
 		response.BookTransaction = &bookTransaction
@@ -0,0 +1,499 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+type CustomerMergeService interface {
+	FindDuplicates() ([]dto.CustomerDuplicateResponse, error)
+	FindDuplicatesOf(customerID uuid.UUID) ([]dto.CustomerDuplicateResponse, error)
+	Merge(actor dto.UserData, req dto.CustomerMergeRequest) (*dto.CustomerMergeResponse, error)
+	GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.CustomerMergeResponse], error)
+	GetByID(id uuid.UUID) (*dto.CustomerMergeResponse, error)
+}
+
+// ErrCustomerMergeConflict is returned when two customers cannot be merged
+var ErrCustomerMergeConflict = errors.New("customers cannot be merged")
+
+// Scores at or above this are reported as likely duplicates
+const minDuplicateScore = 0.6
+
+// Names at least this similar count as the same name spelled differently
+const minNameSimilarity = 0.85
+
+// What each matching detail adds to a duplicate score
+const (
+	duplicateNameWeight        = 0.5
+	duplicateEmailWeight       = 0.35
+	duplicatePhoneWeight       = 0.35
+	duplicateDateOfBirthWeight = 0.15
+)
+
+// Name buckets larger than this are too common a name part to compare every pair in
+const maxDuplicateBucket = 1000
+
+type customerMergeService struct {
+	repository   repository.CustomerMergeRepository
+	customerRepo repository.CustomerRepository
+	userRepo     repository.AuthRepository
+	uow          repository.UnitOfWork
+}
+
+func NewCustomerMergeService(
+	repository repository.CustomerMergeRepository,
+	customerRepo repository.CustomerRepository,
+	userRepo repository.AuthRepository,
+	uow repository.UnitOfWork,
+) CustomerMergeService {
+	return &customerMergeService{
+		repository:   repository,
+		customerRepo: customerRepo,
+		userRepo:     userRepo,
+		uow:          uow,
+	}
+}
+
+// duplicateProfile holds the normalised details customers are matched on
+type duplicateProfile struct {
+	customer *model.Customer
+	name     string
+	email    string
+	phone    string
+}
+
+func newDuplicateProfile(customer *model.Customer) duplicateProfile {
+	return duplicateProfile{
+		customer: customer,
+		name:     normalizeName(customer.Name),
+		email:    normalizeEmail(customer.Email),
+		phone:    phoneKey(customer.Phone),
+	}
+}
+
+// FindDuplicates lists every pair of customers that are likely the same person, best matches first
+func (s *customerMergeService) FindDuplicates() ([]dto.CustomerDuplicateResponse, error) {
+	customers, _, err := s.customerRepo.FindAll(0, 0, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Anonymized customers have nothing left to match on
+	profiles := make([]duplicateProfile, 0, len(customers))
+	for i := range customers {
+		if customers[i].AnonymizedAt == nil {
+			profiles = append(profiles, newDuplicateProfile(&customers[i]))
+		}
+	}
+
+	// Only customers sharing an email, a phone number or the start of a name part are compared
+	buckets := make(map[string][]int)
+	for i, profile := range profiles {
+		keys := make(map[string]bool)
+		if profile.email != "" {
+			keys["email:"+profile.email] = true
+		}
+		if profile.phone != "" {
+			keys["phone:"+profile.phone] = true
+		}
+		for _, part := range strings.Fields(profile.name) {
+			if runes := []rune(part); len(runes) >= 3 {
+				keys["name:"+string(runes[:3])] = true
+			}
+		}
+		for key := range keys {
+			buckets[key] = append(buckets[key], i)
+		}
+	}
+
+	seen := make(map[[2]int]bool)
+	responses := make([]dto.CustomerDuplicateResponse, 0)
+	for key, members := range buckets {
+		if strings.HasPrefix(key, "name:") && len(members) > maxDuplicateBucket {
+			continue
+		}
+		for a := 0; a < len(members); a++ {
+			for b := a + 1; b < len(members); b++ {
+				pair := [2]int{members[a], members[b]}
+				if seen[pair] {
+					continue
+				}
+				seen[pair] = true
+
+				score, reasons := duplicateScore(profiles[pair[0]], profiles[pair[1]])
+				if score >= minDuplicateScore {
+					responses = append(responses, dto.CustomerDuplicateResponse{
+						Customer:  mapToCustomerResponse(profiles[pair[0]].customer),
+						Duplicate: mapToCustomerResponse(profiles[pair[1]].customer),
+						Score:     score,
+						Reasons:   reasons,
+					})
+				}
+			}
+		}
+	}
+
+	sortDuplicates(responses)
+	return responses, nil
+}
+
+// FindDuplicatesOf lists the customers that are likely the same person as the given one
+func (s *customerMergeService) FindDuplicatesOf(customerID uuid.UUID) ([]dto.CustomerDuplicateResponse, error) {
+	customer, err := s.customerRepo.FindByID(customerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	customers, _, err := s.customerRepo.FindAll(0, 0, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	target := newDuplicateProfile(customer)
+	responses := make([]dto.CustomerDuplicateResponse, 0)
+	for i := range customers {
+		if customers[i].ID == customer.ID || customers[i].AnonymizedAt != nil {
+			continue
+		}
+		score, reasons := duplicateScore(target, newDuplicateProfile(&customers[i]))
+		if score >= minDuplicateScore {
+			responses = append(responses, dto.CustomerDuplicateResponse{
+				Customer:  mapToCustomerResponse(customer),
+				Duplicate: mapToCustomerResponse(&customers[i]),
+				Score:     score,
+				Reasons:   reasons,
+			})
+		}
+	}
+
+	sortDuplicates(responses)
+	return responses, nil
+}
+
+// Merge folds a duplicate customer into the survivor in one database transaction: every loan
+// (with its charges), payment, refund, hold and notice moves over, contact details the survivor
+// lacks are copied, the duplicate is deleted and an audit record keeps what it looked like.
+func (s *customerMergeService) Merge(actor dto.UserData, req dto.CustomerMergeRequest) (*dto.CustomerMergeResponse, error) {
+	if req.SurvivorID == req.MergedID {
+		return nil, fmt.Errorf("%w: a customer cannot be merged into itself", ErrCustomerMergeConflict)
+	}
+
+	// A user account links to a single customer, so only one of the two may have one
+	_, survivorErr := s.userRepo.FindByCustomerID(req.SurvivorID)
+	_, mergedErr := s.userRepo.FindByCustomerID(req.MergedID)
+	if survivorErr == nil && mergedErr == nil {
+		return nil, fmt.Errorf("%w: both customers have a member account", ErrCustomerMergeConflict)
+	}
+
+	mergeID := uuid.New()
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		// Lock both customers in a fixed order so concurrent merges cannot deadlock
+		ids := []uuid.UUID{req.SurvivorID, req.MergedID}
+		sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+		locked := make(map[uuid.UUID]*model.Customer, len(ids))
+		for _, id := range ids {
+			customer, err := repos.Customers.FindByIDForUpdate(id)
+			if err != nil {
+				return errors.New("customer not found")
+			}
+			locked[id] = customer
+		}
+		survivor, merged := locked[req.SurvivorID], locked[req.MergedID]
+		if survivor.AnonymizedAt != nil || merged.AnonymizedAt != nil {
+			return fmt.Errorf("%w: anonymized customers cannot be merged", ErrCustomerMergeConflict)
+		}
+
+		if err := mergeGuardians(repos.Customers, survivor, merged); err != nil {
+			return err
+		}
+
+		snapshot, err := json.Marshal(merged)
+		if err != nil {
+			return err
+		}
+
+		counts, err := repos.CustomerMerges.Reassign(merged.ID, survivor.ID)
+		if err != nil {
+			return err
+		}
+
+		mergeCustomerDetails(survivor, merged, time.Now())
+		if err := repos.Customers.Update(survivor); err != nil {
+			return err
+		}
+		if err := repos.Customers.Delete(merged.ID); err != nil {
+			return err
+		}
+
+		return repos.CustomerMerges.Create(&model.CustomerMerge{
+			ID:           mergeID,
+			SurvivorID:   survivor.ID,
+			MergedID:     merged.ID,
+			MergedCode:   merged.Code,
+			MergedName:   merged.Name,
+			Snapshot:     string(snapshot),
+			Transactions: counts.Transactions,
+			Charges:      counts.Charges,
+			Payments:     counts.Payments,
+			Refunds:      counts.Refunds,
+			Holds:        counts.Holds,
+			Reason:       req.Reason,
+			UserID:       actor.ID,
+			CreatedAt:    time.Now(),
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(mergeID)
+}
+
+func (s *customerMergeService) GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.CustomerMergeResponse], error) {
+	merges, total, err := s.repository.FindAll(page, perPage, search, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.CustomerMergeResponse, 0, len(merges))
+	for _, merge := range merges {
+		responses = append(responses, mapToCustomerMergeResponse(&merge))
+	}
+
+	// Calculate total pages
+	totalPages := int64(total) / int64(perPage)
+	if int64(total)%int64(perPage) > 0 {
+		totalPages++
+	}
+
+	return &dto.PaginatedResponseData[[]dto.CustomerMergeResponse]{
+		Status:  200,
+		Message: "Customer merges retrieved successfully",
+		Data:    responses,
+		Meta: dto.PaginationMeta{
+			Page:        page,
+			PerPage:     perPage,
+			TotalItems:  total,
+			TotalPages:  totalPages,
+			ItemsOnPage: int64(len(responses)),
+		},
+	}, nil
+}
+
+func (s *customerMergeService) GetByID(id uuid.UUID) (*dto.CustomerMergeResponse, error) {
+	merge, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("customer merge not found")
+	}
+
+	response := mapToCustomerMergeResponse(merge)
+	return &response, nil
+}
+
+// mergeCustomerDetails fills in what the survivor lacks from the merged customer. The membership
+// keeps the earlier start and the later expiry, and a suspension of either carries over.
+func mergeCustomerDetails(survivor, merged *model.Customer, now time.Time) {
+	if survivor.Email == "" {
+		survivor.Email = merged.Email
+	}
+	if survivor.Phone == "" {
+		survivor.Phone = merged.Phone
+	}
+	if survivor.Address == "" {
+		survivor.Address = merged.Address
+	}
+	if survivor.DateOfBirth == nil {
+		survivor.DateOfBirth = merged.DateOfBirth
+	}
+	if survivor.Category == "" {
+		survivor.Category = merged.Category
+	}
+
+	if merged.MembershipStartedAt != nil && (survivor.MembershipStartedAt == nil || merged.MembershipStartedAt.Before(*survivor.MembershipStartedAt)) {
+		survivor.MembershipStartedAt = merged.MembershipStartedAt
+	}
+	if survivor.MembershipExpiresAt != nil && merged.MembershipExpiresAt != nil && merged.MembershipExpiresAt.After(*survivor.MembershipExpiresAt) {
+		survivor.MembershipExpiresAt = merged.MembershipExpiresAt
+	}
+
+	if merged.Status == model.CustomerStatusSuspended && survivor.Status != model.CustomerStatusSuspended {
+		survivor.Status = model.CustomerStatusSuspended
+		survivor.StatusReason = merged.StatusReason
+	}
+	survivor.Status = membershipStatus(survivor, now)
+}
+
+// mergeGuardians works out the guardian of the merged customer: the survivor keeps theirs or takes
+// the merged one's, and a link between the two customers disappears as they become one.
+// Dependants of both stay dependants, which a customer with a guardian cannot have.
+func mergeGuardians(customers repository.CustomerRepository, survivor, merged *model.Customer) error {
+	guardianID := survivor.GuardianID
+	if guardianID != nil && *guardianID == merged.ID {
+		guardianID = nil
+	}
+	if guardianID == nil && merged.GuardianID != nil && *merged.GuardianID != survivor.ID {
+		guardianID = merged.GuardianID
+	}
+
+	if guardianID != nil {
+		for _, guardian := range []*model.Customer{survivor, merged} {
+			dependants, err := customers.FindDependants(guardian.ID)
+			if err != nil {
+				return err
+			}
+			for _, dependant := range dependants {
+				if dependant.ID != survivor.ID && dependant.ID != merged.ID {
+					return fmt.Errorf("%w: the merged customer would be both a guardian and a dependant", ErrCustomerMergeConflict)
+				}
+			}
+		}
+	}
+
+	survivor.GuardianID = guardianID
+	return nil
+}
+
+// duplicateScore rates how likely two customers are the same person, with the details that match
+func duplicateScore(a, b duplicateProfile) (float64, []string) {
+	var score float64
+	reasons := make([]string, 0)
+
+	if a.name != "" && b.name != "" {
+		if a.name == b.name {
+			score += duplicateNameWeight
+			reasons = append(reasons, "same name")
+		} else if similarity := nameSimilarity(a.name, b.name); similarity >= minNameSimilarity {
+			score += duplicateNameWeight * similarity
+			reasons = append(reasons, fmt.Sprintf("similar name (%.0f%%)", similarity*100))
+		}
+	}
+	if a.email != "" && a.email == b.email {
+		score += duplicateEmailWeight
+		reasons = append(reasons, "same email")
+	}
+	if a.phone != "" && a.phone == b.phone {
+		score += duplicatePhoneWeight
+		reasons = append(reasons, "same phone")
+	}
+	if a.customer.DateOfBirth != nil && b.customer.DateOfBirth != nil &&
+		a.customer.DateOfBirth.Format(model.CalendarDateLayout) == b.customer.DateOfBirth.Format(model.CalendarDateLayout) {
+		score += duplicateDateOfBirthWeight
+		reasons = append(reasons, "same date of birth")
+	}
+
+	if score > 1 {
+		score = 1
+	}
+	return math.Round(score*100) / 100, reasons
+}
+
+// normalizeName lowercases a name, drops punctuation and sorts its parts, so "Doe, John" matches "john doe"
+func normalizeName(name string) string {
+	parts := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
+// phoneKey keeps the last nine digits of a phone number, so local and international forms match
+func phoneKey(phone string) string {
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, phone)
+	if len(digits) > 9 {
+		digits = digits[len(digits)-9:]
+	}
+	return digits
+}
+
+// nameSimilarity is 1 minus the edit distance between two names relative to the longer one
+func nameSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+
+	// Levenshtein distance, keeping one row of the table
+	row := make([]int, len(rb)+1)
+	for j := range row {
+		row[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		previous := row[0]
+		row[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current := min(row[j]+1, row[j-1]+1, previous+cost)
+			previous = row[j]
+			row[j] = current
+		}
+	}
+	return 1 - float64(row[len(rb)])/float64(longest)
+}
+
+func sortDuplicates(duplicates []dto.CustomerDuplicateResponse) {
+	sort.SliceStable(duplicates, func(i, j int) bool {
+		if duplicates[i].Score != duplicates[j].Score {
+			return duplicates[i].Score > duplicates[j].Score
+		}
+		return duplicates[i].Customer.Code < duplicates[j].Customer.Code
+	})
+}
+
+func mapToCustomerMergeResponse(merge *model.CustomerMerge) dto.CustomerMergeResponse {
+	response := dto.CustomerMergeResponse{
+		ID:           merge.ID,
+		SurvivorID:   merge.SurvivorID,
+		MergedID:     merge.MergedID,
+		MergedCode:   merge.MergedCode,
+		MergedName:   merge.MergedName,
+		Snapshot:     json.RawMessage(merge.Snapshot),
+		Transactions: merge.Transactions,
+		Charges:      merge.Charges,
+		Payments:     merge.Payments,
+		Refunds:      merge.Refunds,
+		Holds:        merge.Holds,
+		Reason:       merge.Reason,
+		UserID:       merge.UserID,
+		CreatedAt:    merge.CreatedAt,
+	}
+
+	if merge.Survivor != nil {
+		survivor := mapToCustomerResponse(merge.Survivor)
+		response.Survivor = &survivor
+	}
+
+	if merge.User != nil {
+		response.User = &dto.UserData{
+			ID:    merge.User.ID,
+			Name:  merge.User.Name,
+			Email: merge.User.Email,
+			Role:  merge.User.Role,
+		}
+	}
+
+	return response
+}
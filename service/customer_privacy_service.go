@@ -0,0 +1,292 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CustomerPrivacyService interface {
+	Export(customerID uuid.UUID) (*dto.CustomerDataExport, error)
+	ExportZip(customerID uuid.UUID) ([]byte, error)
+	Anonymize(customerID uuid.UUID, req dto.CustomerAnonymizeRequest) (*dto.CustomerResponse, error)
+	AnonymizeInactive() (*dto.RetentionRunResponse, error)
+}
+
+// ErrCustomerNotAnonymizable is returned when a customer still has business with the library
+var ErrCustomerNotAnonymizable = errors.New("customer cannot be anonymized")
+
+// Name anonymized customers go by
+const anonymizedCustomerName = "Anonymized customer"
+
+type customerPrivacyService struct {
+	customerRepo        repository.CustomerRepository
+	bookTransactionRepo repository.BookTransactionRepository
+	chargeRepo          repository.ChargeRepository
+	paymentRepo         repository.PaymentRepository
+	holdRepo            repository.HoldRepository
+	notificationRepo    repository.NotificationRepository
+	privacyRepo         repository.CustomerPrivacyRepository
+	uow                 repository.UnitOfWork
+	// Days without activity after which a customer is anonymized; 0 keeps customers forever
+	retentionDays int
+}
+
+func NewCustomerPrivacyService(
+	customerRepo repository.CustomerRepository,
+	bookTransactionRepo repository.BookTransactionRepository,
+	chargeRepo repository.ChargeRepository,
+	paymentRepo repository.PaymentRepository,
+	holdRepo repository.HoldRepository,
+	notificationRepo repository.NotificationRepository,
+	privacyRepo repository.CustomerPrivacyRepository,
+	uow repository.UnitOfWork,
+	retentionDays int,
+) CustomerPrivacyService {
+	return &customerPrivacyService{
+		customerRepo:        customerRepo,
+		bookTransactionRepo: bookTransactionRepo,
+		chargeRepo:          chargeRepo,
+		paymentRepo:         paymentRepo,
+		holdRepo:            holdRepo,
+		notificationRepo:    notificationRepo,
+		privacyRepo:         privacyRepo,
+		uow:                 uow,
+		retentionDays:       retentionDays,
+	}
+}
+
+// Export collects the customer's profile, loans, charges with their payments, holds and notices
+func (s *customerPrivacyService) Export(customerID uuid.UUID) (*dto.CustomerDataExport, error) {
+	customer, err := s.customerRepo.FindByID(customerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	export := &dto.CustomerDataExport{
+		ExportedAt:              time.Now(),
+		Customer:                mapToCustomerResponse(customer),
+		Loans:                   make([]dto.BookTransactionResponse, 0),
+		Charges:                 make([]dto.ChargeBalanceResponse, 0),
+		Payments:                make([]dto.PaymentResponse, 0),
+		Holds:                   make([]dto.HoldResponse, 0),
+		Notifications:           make([]dto.NotificationResponse, 0),
+		NotificationPreferences: make([]dto.NotificationPreferenceResponse, 0),
+	}
+
+	transactions, err := s.bookTransactionRepo.FindByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range transactions {
+		export.Loans = append(export.Loans, mapToBookTransactionResponse(&transaction))
+	}
+
+	charges, err := s.chargeRepo.FindByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, charge := range charges {
+		export.Charges = append(export.Charges, mapToChargeBalanceResponse(&charge))
+	}
+
+	payments, err := s.paymentRepo.FindByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, payment := range payments {
+		export.Payments = append(export.Payments, mapToPaymentResponse(&payment))
+	}
+
+	holds, err := s.holdRepo.FindByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, hold := range holds {
+		export.Holds = append(export.Holds, mapToHoldResponse(&hold))
+	}
+
+	notifications, err := s.notificationRepo.FindByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, notification := range notifications {
+		export.Notifications = append(export.Notifications, mapToNotificationResponse(&notification))
+	}
+
+	preferences, err := s.notificationRepo.FindPreferencesByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, preference := range preferences {
+		export.NotificationPreferences = append(export.NotificationPreferences, mapToNotificationPreferenceResponse(&preference))
+	}
+
+	return export, nil
+}
+
+// ExportZip packs the export into a ZIP archive with one JSON file per kind of record
+func (s *customerPrivacyService) ExportZip(customerID uuid.UUID) ([]byte, error) {
+	export, err := s.Export(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	sections := []struct {
+		name string
+		data interface{}
+	}{
+		{"profile.json", export.Customer},
+		{"loans.json", export.Loans},
+		{"charges.json", export.Charges},
+		{"payments.json", export.Payments},
+		{"holds.json", export.Holds},
+		{"notifications.json", export.Notifications},
+		{"notification_preferences.json", export.NotificationPreferences},
+	}
+
+	files := make([]lib.ArchiveFile, 0, len(sections))
+	for _, section := range sections {
+		body, err := json.MarshalIndent(section.data, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, lib.ArchiveFile{Name: section.name, Body: body})
+	}
+
+	return lib.BuildZip(files, export.ExportedAt)
+}
+
+// Anonymize erases a customer's personal data. Loans, charges and payments stay, tied to a
+// nameless record, so circulation and fine statistics keep adding up. Customers with open loans,
+// unpaid charges, a copy waiting on the hold shelf or dependants are refused; pending holds are cancelled.
+func (s *customerPrivacyService) Anonymize(customerID uuid.UUID, req dto.CustomerAnonymizeRequest) (*dto.CustomerResponse, error) {
+	var customer *model.Customer
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		var err error
+		customer, err = repos.Customers.FindByIDForUpdate(customerID)
+		if err != nil {
+			return errors.New("customer not found")
+		}
+		if customer.AnonymizedAt != nil {
+			return fmt.Errorf("%w: customer is already anonymized", ErrCustomerNotAnonymizable)
+		}
+
+		openLoans, err := repos.BookTransactions.CountActiveByCustomerID(customer.ID, "")
+		if err != nil {
+			return err
+		}
+		if openLoans > 0 {
+			return fmt.Errorf("%w: customer has %d open loans", ErrCustomerNotAnonymizable, openLoans)
+		}
+
+		charges, err := repos.Charges.FindByCustomerID(customer.ID)
+		if err != nil {
+			return err
+		}
+		var outstanding float64
+		for _, charge := range charges {
+			if ledger := summarizeCharge(&charge); ledger.Balance > 0 {
+				outstanding += ledger.Balance
+			}
+		}
+		if outstanding = roundMoney(outstanding); outstanding > 0 {
+			return fmt.Errorf("%w: customer owes %s in unpaid charges", ErrCustomerNotAnonymizable, formatMoney(outstanding))
+		}
+
+		// Children keep their responsible adult until staff give them another
+		dependants, err := repos.Customers.FindDependants(customer.ID)
+		if err != nil {
+			return err
+		}
+		if len(dependants) > 0 {
+			return fmt.Errorf("%w: customer is the guardian of %d customers", ErrCustomerNotAnonymizable, len(dependants))
+		}
+
+		holds, err := repos.Holds.FindByCustomerID(customer.ID)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+		for _, hold := range holds {
+			switch hold.Status {
+			case model.StatusHoldReady:
+				return fmt.Errorf("%w: a copy is waiting on the hold shelf for the customer", ErrCustomerNotAnonymizable)
+			case model.StatusHoldPending:
+				hold.Status = model.StatusHoldCancelled
+				hold.ClosedAt = &now
+				if err := repos.Holds.Update(&hold); err != nil {
+					return err
+				}
+			}
+		}
+
+		scrubCustomer(customer, req.Reason, now)
+		if err := repos.Customers.Update(customer); err != nil {
+			return err
+		}
+		return repos.CustomerPrivacy.ScrubRelated(customer.ID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapToCustomerResponse(customer)
+	return &response, nil
+}
+
+// AnonymizeInactive applies the retention policy: customers without any activity for the
+// retention period are anonymized, unless they still owe the library something
+func (s *customerPrivacyService) AnonymizeInactive() (*dto.RetentionRunResponse, error) {
+	result := &dto.RetentionRunResponse{Skipped: make([]dto.RetentionSkip, 0)}
+	if s.retentionDays <= 0 {
+		return result, nil
+	}
+
+	customers, err := s.privacyRepo.FindInactive(time.Now().AddDate(0, 0, -s.retentionDays))
+	if err != nil {
+		return nil, err
+	}
+
+	reason := fmt.Sprintf("no activity for %d days", s.retentionDays)
+	for _, customer := range customers {
+		_, err := s.Anonymize(customer.ID, dto.CustomerAnonymizeRequest{Reason: reason})
+		if errors.Is(err, ErrCustomerNotAnonymizable) {
+			result.Skipped = append(result.Skipped, dto.RetentionSkip{
+				CustomerID: customer.ID,
+				Code:       customer.Code,
+				Reason:     err.Error(),
+			})
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Anonymized++
+	}
+
+	return result, nil
+}
+
+// scrubCustomer replaces everything identifying about a customer, including who their guardian was.
+// The category and membership dates stay, as statistics group by them and they do not identify anyone.
+func scrubCustomer(customer *model.Customer, reason string, now time.Time) {
+	customer.Code = "ANON-" + strings.ReplaceAll(customer.ID.String(), "-", "")
+	customer.Name = anonymizedCustomerName
+	customer.Email = ""
+	customer.Phone = ""
+	customer.Address = ""
+	customer.DateOfBirth = nil
+	customer.GuardianID = nil
+	customer.AnonymizedAt = &now
+	customer.Status = model.CustomerStatusAnonymized
+	customer.StatusReason = reason
+}
@@ -2,10 +2,13 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"go-gin-simple-api/dto"
 	"go-gin-simple-api/lib"
 	"go-gin-simple-api/model"
 	"go-gin-simple-api/repository"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -19,20 +22,35 @@ type CustomerService interface {
 	Create(req dto.CustomerCreateRequest) (*dto.CustomerResponse, error)
 	Update(id uuid.UUID, req dto.CustomerUpdateRequest) (*dto.CustomerResponse, error)
 	Delete(id uuid.UUID) error
+	RenewMembership(id uuid.UUID, req dto.MembershipRenewRequest) (*dto.CustomerResponse, error)
+	ExpireMemberships() (int64, error)
 }
 
+// ErrCustomerAccessDenied is returned when a member asks for the records of another customer
+var ErrCustomerAccessDenied = errors.New("access denied for this customer")
+
+// ErrInvalidCustomerDetails is returned when contact or membership details do not make sense
+var ErrInvalidCustomerDetails = errors.New("invalid customer details")
+
+// Phone numbers once spaces, dashes, dots and parentheses are stripped
+var phonePattern = regexp.MustCompile(`^\+?[0-9]{6,15}$`)
+
 type customerService struct {
 	repository          repository.CustomerRepository
 	bookTransactionRepo repository.BookTransactionRepository
+	// Months a membership runs when renewed without a period
+	membershipMonths int
 }
 
 func NewCustomerService(
 	repository repository.CustomerRepository,
 	bookTransactionRepo repository.BookTransactionRepository,
+	membershipMonths int,
 ) CustomerService {
 	return &customerService{
 		repository:          repository,
 		bookTransactionRepo: bookTransactionRepo,
+		membershipMonths:    membershipMonths,
 	}
 }
 
@@ -110,14 +128,33 @@ func (s *customerService) Create(req dto.CustomerCreateRequest) (*dto.CustomerRe
 		return nil, errors.New("customer code already exists")
 	}
 
+	now := time.Now()
 	customer := model.Customer{
-		ID:        uuid.New(),
-		Code:      req.Code,
-		Name:      req.Name,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:       uuid.New(),
+		Code:     req.Code,
+		Name:     req.Name,
+		Category: req.Category,
+		// Contact details
+		Email:       normalizeEmail(req.Email),
+		Phone:       req.Phone,
+		Address:     strings.TrimSpace(req.Address),
+		DateOfBirth: req.DateOfBirth,
+		// Membership
+		MembershipStartedAt: req.MembershipStartedAt,
+		MembershipExpiresAt: req.MembershipExpiresAt,
+		Status:              model.CustomerStatusActive,
+		CreatedAt:           now,
+		UpdatedAt:           now,
+	}
+	if customer.MembershipStartedAt == nil {
+		customer.MembershipStartedAt = &now
 	}
 
+	if err := validateCustomerDetails(&customer, now); err != nil {
+		return nil, err
+	}
+	customer.Status = membershipStatus(&customer, now)
+
 	if err := s.repository.Create(&customer); err != nil {
 		return nil, err
 	}
@@ -132,6 +169,9 @@ func (s *customerService) Update(id uuid.UUID, req dto.CustomerUpdateRequest) (*
 	if err != nil {
 		return nil, errors.New("customer not found")
 	}
+	if customer.AnonymizedAt != nil {
+		return nil, fmt.Errorf("%w: customer is anonymized", ErrInvalidCustomerDetails)
+	}
 
 	// Update fields if provided
 	if req.Code != nil {
@@ -147,6 +187,52 @@ func (s *customerService) Update(id uuid.UUID, req dto.CustomerUpdateRequest) (*
 		customer.Name = *req.Name
 	}
 
+	if req.Category != nil {
+		customer.Category = *req.Category
+	}
+
+	if req.Email != nil {
+		customer.Email = normalizeEmail(*req.Email)
+	}
+
+	if req.Phone != nil {
+		customer.Phone = *req.Phone
+	}
+
+	if req.Address != nil {
+		customer.Address = strings.TrimSpace(*req.Address)
+	}
+
+	if req.DateOfBirth != nil {
+		customer.DateOfBirth = req.DateOfBirth
+	}
+
+	if req.MembershipStartedAt != nil {
+		customer.MembershipStartedAt = req.MembershipStartedAt
+	}
+
+	if req.MembershipExpiresAt != nil {
+		customer.MembershipExpiresAt = req.MembershipExpiresAt
+	}
+
+	if req.Status != nil {
+		customer.Status = *req.Status
+		if *req.Status != model.CustomerStatusSuspended {
+			customer.StatusReason = ""
+		}
+	}
+
+	if req.StatusReason != nil {
+		customer.StatusReason = *req.StatusReason
+	}
+
+	now := time.Now()
+	if err := validateCustomerDetails(customer, now); err != nil {
+		return nil, err
+	}
+	// A changed expiry date may expire or revive the membership
+	customer.Status = membershipStatus(customer, now)
+
 	if err := s.repository.Update(customer); err != nil {
 		return nil, err
 	}
@@ -168,18 +254,130 @@ func (s *customerService) Delete(id uuid.UUID) error {
 		return errors.New("cannot delete customer with existing book transactions")
 	}
 
+	// Dependants need another guardian first
+	dependants, err := s.repository.FindDependants(customer.ID)
+	if err == nil && len(dependants) > 0 {
+		return errors.New("cannot delete customer who is the guardian of other customers")
+	}
+
 	// Delete customer
 	return s.repository.Delete(id)
 }
 
+// RenewMembership extends a customer's membership and reactivates it once expired.
+// A suspension is left in place; staff lift it separately.
+func (s *customerService) RenewMembership(id uuid.UUID, req dto.MembershipRenewRequest) (*dto.CustomerResponse, error) {
+	customer, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if customer.AnonymizedAt != nil {
+		return nil, fmt.Errorf("%w: customer is anonymized", ErrInvalidCustomerDetails)
+	}
+
+	now := time.Now()
+	var expiresAt time.Time
+	if req.ExpiresAt != nil {
+		expiresAt = *req.ExpiresAt
+	} else {
+		months := s.membershipMonths
+		if req.Months != nil {
+			months = *req.Months
+		}
+		// Renewing early adds to the remaining period rather than losing it
+		from := now
+		if customer.MembershipExpiresAt != nil && customer.MembershipExpiresAt.After(now) {
+			from = *customer.MembershipExpiresAt
+		}
+		expiresAt = from.AddDate(0, months, 0)
+	}
+
+	if !expiresAt.After(now) {
+		return nil, fmt.Errorf("%w: a renewed membership must expire in the future", ErrInvalidCustomerDetails)
+	}
+	if customer.MembershipExpiresAt != nil && !expiresAt.After(*customer.MembershipExpiresAt) {
+		return nil, fmt.Errorf("%w: renewing would not extend the membership", ErrInvalidCustomerDetails)
+	}
+
+	customer.MembershipExpiresAt = &expiresAt
+	if customer.MembershipStartedAt == nil {
+		customer.MembershipStartedAt = &now
+	}
+	customer.Status = membershipStatus(customer, now)
+
+	if err := s.repository.Update(customer); err != nil {
+		return nil, err
+	}
+
+	response := mapToCustomerResponse(customer)
+	return &response, nil
+}
+
+// ExpireMemberships marks active customers past their membership expiry as Expired; the scheduler runs it periodically
+func (s *customerService) ExpireMemberships() (int64, error) {
+	return s.repository.ExpireMemberships(time.Now())
+}
+
+// membershipStatus is the status a customer should have now: suspensions stand until lifted,
+// otherwise the membership is active until its expiry date passes
+func membershipStatus(customer *model.Customer, now time.Time) string {
+	if customer.AnonymizedAt != nil {
+		return model.CustomerStatusAnonymized
+	}
+	if customer.Status == model.CustomerStatusSuspended {
+		return model.CustomerStatusSuspended
+	}
+	if customer.MembershipExpiresAt != nil && customer.MembershipExpiresAt.Before(now) {
+		return model.CustomerStatusExpired
+	}
+	return model.CustomerStatusActive
+}
+
+// validateCustomerDetails normalises the phone number and checks dates hang together
+func validateCustomerDetails(customer *model.Customer, now time.Time) error {
+	if customer.Phone != "" {
+		phone := strings.NewReplacer(" ", "", "-", "", ".", "", "(", "", ")", "").Replace(customer.Phone)
+		if !phonePattern.MatchString(phone) {
+			return fmt.Errorf("%w: phone must be 6 to 15 digits, optionally starting with +", ErrInvalidCustomerDetails)
+		}
+		customer.Phone = phone
+	}
+
+	if customer.DateOfBirth != nil && !customer.DateOfBirth.Before(now) {
+		return fmt.Errorf("%w: date of birth must be in the past", ErrInvalidCustomerDetails)
+	}
+
+	if customer.MembershipStartedAt != nil && customer.MembershipExpiresAt != nil && !customer.MembershipExpiresAt.After(*customer.MembershipStartedAt) {
+		return fmt.Errorf("%w: membership must expire after it starts", ErrInvalidCustomerDetails)
+	}
+	return nil
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
 // Helper function to map a Customer entity to a CustomerResponse DTO
 func mapToCustomerResponse(customer *model.Customer) dto.CustomerResponse {
 	return dto.CustomerResponse{
-		ID:        customer.ID,
-		Code:      customer.Code,
-		Name:      customer.Name,
-		CreatedAt: customer.CreatedAt,
-		UpdatedAt: customer.UpdatedAt,
+		ID:       customer.ID,
+		Code:     customer.Code,
+		Name:     customer.Name,
+		Category: customer.Category,
+		// Contact details
+		Email:       customer.Email,
+		Phone:       customer.Phone,
+		Address:     customer.Address,
+		DateOfBirth: customer.DateOfBirth,
+		// Membership
+		MembershipStartedAt: customer.MembershipStartedAt,
+		MembershipExpiresAt: customer.MembershipExpiresAt,
+		Status:              customer.Status,
+		StatusReason:        customer.StatusReason,
+		AnonymizedAt:        customer.AnonymizedAt,
+		GuardianID:          customer.GuardianID,
+		CreatedAt:           customer.CreatedAt,
+		UpdatedAt:           customer.UpdatedAt,
 	}
 }
 
@@ -189,6 +387,7 @@ func mapToCustomerWithTransactionsResponse(customer *model.Customer, transaction
 		ID:        customer.ID,
 		Code:      customer.Code,
 		Name:      customer.Name,
+		Category:  customer.Category,
 		CreatedAt: customer.CreatedAt,
 		UpdatedAt: customer.UpdatedAt,
 	}
@@ -204,3 +403,21 @@ func mapToCustomerWithTransactionsResponse(customer *model.Customer, transaction
 
 	return response
 }
+
+// CheckCustomerAccess enforces that members only see their own customer records.
+// Admins may see every customer; other accounts only the customer they are linked to and its dependants.
+func CheckCustomerAccess(actor dto.UserData, customerID uuid.UUID) error {
+	if actor.Role == "admin" {
+		return nil
+	}
+	if actor.CustomerID != nil && *actor.CustomerID == customerID {
+		return nil
+	}
+	// Guardians see the records of their dependants
+	for _, dependantID := range actor.DependantIDs {
+		if dependantID == customerID {
+			return nil
+		}
+	}
+	return ErrCustomerAccessDenied
+}
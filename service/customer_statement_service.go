@@ -0,0 +1,343 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type CustomerStatementService interface {
+	GetStatement(customerID uuid.UUID, from, to string, page, perPage int) (*dto.CustomerStatementResponse, error)
+	GetStatementPDF(customerID uuid.UUID, from, to string) ([]byte, error)
+}
+
+// ErrInvalidStatementPeriod is returned when the dates of a statement cannot be used
+var ErrInvalidStatementPeriod = errors.New("invalid statement period")
+
+// Kinds of statement entries
+const (
+	StatementEntryCharge     = "Charge"
+	StatementEntryPayment    = "Payment"
+	StatementEntryAdjustment = "Adjustment"
+	StatementEntryRefund     = "Refund"
+)
+
+type customerStatementService struct {
+	customerRepo        repository.CustomerRepository
+	bookTransactionRepo repository.BookTransactionRepository
+	chargeRepo          repository.ChargeRepository
+}
+
+func NewCustomerStatementService(
+	customerRepo repository.CustomerRepository,
+	bookTransactionRepo repository.BookTransactionRepository,
+	chargeRepo repository.ChargeRepository,
+) CustomerStatementService {
+	return &customerStatementService{
+		customerRepo:        customerRepo,
+		bookTransactionRepo: bookTransactionRepo,
+		chargeRepo:          chargeRepo,
+	}
+}
+
+// GetStatement builds a customer's statement between two dates, both inclusive and formatted as
+// YYYY-MM-DD; they default to the twelve months up to today. Only the reading history is paginated,
+// the account entries of the period are always complete so their running balance adds up.
+func (s *customerStatementService) GetStatement(customerID uuid.UUID, from, to string, page, perPage int) (*dto.CustomerStatementResponse, error) {
+	start, end, err := parseStatementPeriod(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	customer, err := s.customerRepo.FindByID(customerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	statement := &dto.CustomerStatementResponse{
+		Customer:     mapToCustomerResponse(customer),
+		From:         start,
+		To:           end.AddDate(0, 0, -1),
+		GeneratedAt:  time.Now(),
+		CurrentLoans: make([]dto.BookTransactionResponse, 0),
+		Charges:      make([]dto.ChargeBalanceResponse, 0),
+		Entries:      make([]dto.StatementEntryResponse, 0),
+	}
+
+	transactions, err := s.bookTransactionRepo.FindByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range transactions {
+		if transaction.Status == model.StatusBTBorrowed || transaction.Status == model.StatusBTOverdue {
+			statement.CurrentLoans = append(statement.CurrentLoans, mapToBookTransactionResponse(&transaction))
+		}
+	}
+	sort.SliceStable(statement.CurrentLoans, func(i, j int) bool {
+		return statement.CurrentLoans[i].DueDate.Before(statement.CurrentLoans[j].DueDate)
+	})
+
+	charges, err := s.chargeRepo.FindByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, charge := range charges {
+		ledger := summarizeCharge(&charge)
+		statement.Outstanding += ledger.Balance
+		// Charges raised during the period, and older ones still unpaid
+		if (!charge.CreatedAt.Before(start) && charge.CreatedAt.Before(end)) || ledger.Balance > 0 {
+			statement.Charges = append(statement.Charges, mapToChargeBalanceResponse(&charge))
+		}
+	}
+	statement.Outstanding = roundMoney(statement.Outstanding)
+
+	entries := statementEntries(charges)
+	for _, entry := range entries {
+		movement := entry.Debit - entry.Credit
+		if entry.Date.Before(start) {
+			statement.OpeningBalance += movement
+			continue
+		}
+		if !entry.Date.Before(end) {
+			break
+		}
+		switch entry.Type {
+		case StatementEntryCharge:
+			statement.TotalCharged += entry.Debit
+		case StatementEntryPayment:
+			statement.TotalPaid += entry.Credit
+		case StatementEntryAdjustment:
+			statement.TotalWaived += entry.Credit
+		case StatementEntryRefund:
+			statement.TotalRefunded += entry.Debit
+		}
+		statement.Entries = append(statement.Entries, entry)
+	}
+	statement.OpeningBalance = roundMoney(statement.OpeningBalance)
+	balance := statement.OpeningBalance
+	for i := range statement.Entries {
+		balance = roundMoney(balance + statement.Entries[i].Debit - statement.Entries[i].Credit)
+		statement.Entries[i].Balance = balance
+	}
+	statement.ClosingBalance = balance
+	statement.TotalCharged = roundMoney(statement.TotalCharged)
+	statement.TotalPaid = roundMoney(statement.TotalPaid)
+	statement.TotalWaived = roundMoney(statement.TotalWaived)
+	statement.TotalRefunded = roundMoney(statement.TotalRefunded)
+
+	history, total, err := s.bookTransactionRepo.FindHistoryByCustomerID(customerID, start, end, page, perPage)
+	if err != nil {
+		return nil, err
+	}
+	statement.History.Items = make([]dto.BookTransactionResponse, 0, len(history))
+	for _, transaction := range history {
+		statement.History.Items = append(statement.History.Items, mapToBookTransactionResponse(&transaction))
+	}
+
+	// Calculate total pages
+	var totalPages int64 = 1
+	if perPage > 0 {
+		totalPages = int64(total) / int64(perPage)
+		if int64(total)%int64(perPage) > 0 {
+			totalPages++
+		}
+	}
+
+	statement.History.Meta = dto.PaginationMeta{
+		Page:        page,
+		PerPage:     perPage,
+		TotalItems:  total,
+		TotalPages:  totalPages,
+		ItemsOnPage: int64(len(statement.History.Items)),
+	}
+
+	return statement, nil
+}
+
+// GetStatementPDF renders the statement for printing, with the whole reading history of the period
+func (s *customerStatementService) GetStatementPDF(customerID uuid.UUID, from, to string) ([]byte, error) {
+	statement, err := s.GetStatement(customerID, from, to, 0, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	const dateLayout = "02 Jan 2006"
+	summary := []lib.ReceiptLine{
+		{Label: "Customer", Value: fmt.Sprintf("%s (%s)", statement.Customer.Name, statement.Customer.Code)},
+		{Label: "Membership", Value: statement.Customer.Status},
+		{Label: "Opening balance", Value: formatMoney(statement.OpeningBalance)},
+		{Label: "Charged", Value: formatMoney(statement.TotalCharged)},
+		{Label: "Paid", Value: formatMoney(statement.TotalPaid)},
+		{Label: "Waived", Value: formatMoney(statement.TotalWaived)},
+		{Label: "Refunded", Value: formatMoney(statement.TotalRefunded)},
+		{Label: "Closing balance", Value: formatMoney(statement.ClosingBalance)},
+		{Label: "Outstanding today", Value: formatMoney(statement.Outstanding)},
+	}
+
+	loans := lib.StatementTable{
+		Heading: "Current loans",
+		Columns: []lib.StatementColumn{
+			{Title: "Title", Width: 0.5, Align: "L"},
+			{Title: "Copy", Width: 0.2, Align: "L"},
+			{Title: "Due", Width: 0.15, Align: "L"},
+			{Title: "Status", Width: 0.15, Align: "L"},
+		},
+		Empty: "No books on loan.",
+	}
+	for _, loan := range statement.CurrentLoans {
+		loans.Rows = append(loans.Rows, []string{statementBookTitle(loan), loan.StockCode, loan.DueDate.Format(dateLayout), loan.Status})
+	}
+
+	entries := lib.StatementTable{
+		Heading: "Account activity",
+		Columns: []lib.StatementColumn{
+			{Title: "Date", Width: 0.15, Align: "L"},
+			{Title: "Description", Width: 0.43, Align: "L"},
+			{Title: "Debit", Width: 0.14, Align: "R"},
+			{Title: "Credit", Width: 0.14, Align: "R"},
+			{Title: "Balance", Width: 0.14, Align: "R"},
+		},
+		Empty: "No account activity in this period.",
+	}
+	for _, entry := range statement.Entries {
+		debit, credit := "", ""
+		if entry.Debit > 0 {
+			debit = formatMoney(entry.Debit)
+		}
+		if entry.Credit > 0 {
+			credit = formatMoney(entry.Credit)
+		}
+		description := entry.Description
+		if entry.Reference != "" {
+			description = fmt.Sprintf("%s [%s]", description, entry.Reference)
+		}
+		entries.Rows = append(entries.Rows, []string{entry.Date.Format(dateLayout), description, debit, credit, formatMoney(entry.Balance)})
+	}
+
+	history := lib.StatementTable{
+		Heading: "Reading history",
+		Columns: []lib.StatementColumn{
+			{Title: "Borrowed", Width: 0.15, Align: "L"},
+			{Title: "Title", Width: 0.5, Align: "L"},
+			{Title: "Returned", Width: 0.15, Align: "L"},
+			{Title: "Status", Width: 0.2, Align: "L"},
+		},
+		Empty: "Nothing borrowed in this period.",
+	}
+	for _, loan := range statement.History.Items {
+		borrowed, returned := "", ""
+		if loan.BorrowedAt != nil {
+			borrowed = loan.BorrowedAt.Format(dateLayout)
+		}
+		if loan.ReturnAt != nil {
+			returned = loan.ReturnAt.Format(dateLayout)
+		}
+		history.Rows = append(history.Rows, []string{borrowed, statementBookTitle(loan), returned, loan.Status})
+	}
+
+	return lib.GenerateStatementPDF(lib.Statement{
+		Title:    "Account Statement",
+		Subtitle: fmt.Sprintf("%s to %s", statement.From.Format(dateLayout), statement.To.Format(dateLayout)),
+		Summary:  summary,
+		Tables:   []lib.StatementTable{loans, entries, history},
+		Footer:   fmt.Sprintf("Generated on %s. Please contact the library desk about any entry you do not recognise.", statement.GeneratedAt.Format("02 Jan 2006 15:04")),
+	})
+}
+
+// statementEntries lists every movement on the customer's charges in date order
+// A charge counts at its current total, so later changes to it show on the day it was raised
+func statementEntries(charges []model.Charge) []dto.StatementEntryResponse {
+	entries := make([]dto.StatementEntryResponse, 0)
+	for _, charge := range charges {
+		title := charge.BookTransaction.Book.Title
+		description := "Late fee"
+		if charge.Type == model.ChargeTypeReplacement {
+			description = "Replacement"
+		}
+		if title != "" {
+			description = fmt.Sprintf("%s: %s", description, title)
+		}
+		entries = append(entries, dto.StatementEntryResponse{
+			Date:        charge.CreatedAt,
+			Type:        StatementEntryCharge,
+			ChargeID:    charge.ID,
+			Description: description,
+			Debit:       charge.Total,
+		})
+
+		for _, payment := range charge.Payments {
+			entries = append(entries, dto.StatementEntryResponse{
+				Date:        payment.CreatedAt,
+				Type:        StatementEntryPayment,
+				ChargeID:    charge.ID,
+				Description: fmt.Sprintf("Payment (%s)", payment.Method),
+				Reference:   payment.ReceiptNumber,
+				Credit:      payment.Amount,
+			})
+		}
+		for _, adjustment := range charge.Adjustments {
+			entries = append(entries, dto.StatementEntryResponse{
+				Date:        adjustment.CreatedAt,
+				Type:        StatementEntryAdjustment,
+				ChargeID:    charge.ID,
+				Description: fmt.Sprintf("%s: %s", adjustment.Type, adjustment.Reason),
+				Credit:      adjustment.Amount,
+			})
+		}
+		for _, refund := range charge.Refunds {
+			entries = append(entries, dto.StatementEntryResponse{
+				Date:        refund.CreatedAt,
+				Type:        StatementEntryRefund,
+				ChargeID:    charge.ID,
+				Description: fmt.Sprintf("Refund (%s): %s", refund.Method, refund.Reason),
+				Debit:       refund.Amount,
+			})
+		}
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Date.Before(entries[j].Date)
+	})
+	return entries
+}
+
+// parseStatementPeriod turns inclusive YYYY-MM-DD dates into the half-open range [start, end)
+func parseStatementPeriod(from, to string) (time.Time, time.Time, error) {
+	now := time.Now()
+	end := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	if to != "" {
+		parsed, err := time.ParseInLocation(model.CalendarDateLayout, to, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: dates must be formatted as YYYY-MM-DD", ErrInvalidStatementPeriod)
+		}
+		end = parsed.AddDate(0, 0, 1)
+	}
+
+	start := end.AddDate(-1, 0, 0)
+	if from != "" {
+		parsed, err := time.ParseInLocation(model.CalendarDateLayout, from, time.Local)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("%w: dates must be formatted as YYYY-MM-DD", ErrInvalidStatementPeriod)
+		}
+		start = parsed
+	}
+
+	if !start.Before(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: the period ends before it starts", ErrInvalidStatementPeriod)
+	}
+	return start, end, nil
+}
+
+func statementBookTitle(loan dto.BookTransactionResponse) string {
+	if loan.Book != nil {
+		return loan.Book.Title
+	}
+	return loan.StockCode
+}
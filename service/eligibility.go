@@ -0,0 +1,129 @@
+package service
+
+import (
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"strings"
+	"time"
+)
+
+// EligibilityRules configures the checks a customer must pass before borrowing
+type EligibilityRules struct {
+	// MaxUnpaidFines blocks borrowing once the outstanding balance of the household exceeds it; nil disables the check
+	MaxUnpaidFines         *float64
+	BlockOverdue           bool
+	BlockExpiredMembership bool
+}
+
+// Codes of the checkout eligibility checks
+const (
+	EligibilityUnpaidFines       = "unpaid_fines"
+	EligibilityOverdueLoans      = "overdue_loans"
+	EligibilityMembershipExpired = "membership_expired"
+	EligibilitySuspended         = "membership_suspended"
+	EligibilityLoanLimit         = "loan_limit"
+)
+
+// EligibilityError is returned when a customer fails checkout eligibility checks
+type EligibilityError struct {
+	Reasons []dto.EligibilityReason
+}
+
+func (e *EligibilityError) Error() string {
+	messages := make([]string, 0, len(e.Reasons))
+	for _, reason := range e.Reasons {
+		messages = append(messages, reason.Message)
+	}
+	return "customer is not eligible to borrow: " + strings.Join(messages, "; ")
+}
+
+// evaluateEligibility runs the checkout eligibility checks for a customer borrowing under the policy
+// and returns every failed check
+func evaluateEligibility(repos repository.Repositories, rules EligibilityRules, customer *model.Customer, policy *model.LoanPolicy, now time.Time) ([]dto.EligibilityReason, error) {
+	reasons := make([]dto.EligibilityReason, 0)
+
+	// The fine limit applies to a household as a whole: the guardian and every dependant
+	if rules.MaxUnpaidFines != nil {
+		members, err := householdMemberIDs(repos.Customers, customer)
+		if err != nil {
+			return nil, err
+		}
+		var outstanding float64
+		for _, memberID := range members {
+			charges, err := repos.Charges.FindByCustomerID(memberID)
+			if err != nil {
+				return nil, err
+			}
+			for _, charge := range charges {
+				if ledger := summarizeCharge(&charge); ledger.Balance > 0 {
+					outstanding += ledger.Balance
+				}
+			}
+		}
+		outstanding = roundMoney(outstanding)
+		if outstanding > *rules.MaxUnpaidFines {
+			subject := "unpaid fines"
+			if len(members) > 1 {
+				subject = "unpaid fines of the household"
+			}
+			reasons = append(reasons, dto.EligibilityReason{
+				Code:    EligibilityUnpaidFines,
+				Message: fmt.Sprintf("%s of %s exceed the limit of %s", subject, formatMoney(outstanding), formatMoney(*rules.MaxUnpaidFines)),
+				Limit:   *rules.MaxUnpaidFines,
+				Actual:  outstanding,
+			})
+		}
+	}
+
+	if rules.BlockOverdue {
+		overdue, err := repos.BookTransactions.CountOverdueByCustomerID(customer.ID, now)
+		if err != nil {
+			return nil, err
+		}
+		if overdue > 0 {
+			reasons = append(reasons, dto.EligibilityReason{
+				Code:    EligibilityOverdueLoans,
+				Message: fmt.Sprintf("customer has %d overdue loans", overdue),
+				Actual:  float64(overdue),
+			})
+		}
+	}
+
+	// Suspended members never borrow without an override
+	if customer.Status == model.CustomerStatusSuspended {
+		message := "membership is suspended"
+		if customer.StatusReason != "" {
+			message += ": " + customer.StatusReason
+		}
+		reasons = append(reasons, dto.EligibilityReason{
+			Code:    EligibilitySuspended,
+			Message: message,
+		})
+	}
+
+	if rules.BlockExpiredMembership && customer.MembershipExpiresAt != nil && customer.MembershipExpiresAt.Before(now) {
+		reasons = append(reasons, dto.EligibilityReason{
+			Code:    EligibilityMembershipExpired,
+			Message: fmt.Sprintf("membership expired on %s", customer.MembershipExpiresAt.Format("2006-01-02")),
+		})
+	}
+
+	if policy.MaxLoans > 0 {
+		activeLoans, err := repos.BookTransactions.CountActiveByCustomerID(customer.ID, policy.MaterialType)
+		if err != nil {
+			return nil, err
+		}
+		if activeLoans >= int64(policy.MaxLoans) {
+			reasons = append(reasons, dto.EligibilityReason{
+				Code:    EligibilityLoanLimit,
+				Message: fmt.Sprintf("customer has reached the maximum of %d loans allowed by loan policy %s", policy.MaxLoans, policy.Name),
+				Limit:   float64(policy.MaxLoans),
+				Actual:  float64(activeLoans),
+			})
+		}
+	}
+
+	return reasons, nil
+}
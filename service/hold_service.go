@@ -0,0 +1,377 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type HoldService interface {
+	GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.HoldResponse], error)
+	GetByID(id uuid.UUID) (*dto.HoldResponse, error)
+	GetByBookID(bookID uuid.UUID) ([]dto.HoldResponse, error)
+	GetByCustomerID(customerID uuid.UUID) ([]dto.HoldResponse, error)
+	Create(req dto.HoldCreateRequest) (*dto.HoldResponse, error)
+	Cancel(id uuid.UUID, actor dto.UserData) (*dto.HoldResponse, error)
+	ExpirePickups() (*dto.HoldExpireResponse, error)
+}
+
+// ErrHoldNotAllowed is returned when a hold cannot be placed or changed
+var ErrHoldNotAllowed = errors.New("hold not allowed")
+
+type holdService struct {
+	repository    repository.HoldRepository
+	bookRepo      repository.BookRepository
+	bookStockRepo repository.BookStockRepository
+	uow           repository.UnitOfWork
+	pickupDays    int
+}
+
+func NewHoldService(
+	repository repository.HoldRepository,
+	bookRepo repository.BookRepository,
+	bookStockRepo repository.BookStockRepository,
+	uow repository.UnitOfWork,
+	pickupDays int,
+) HoldService {
+	return &holdService{
+		repository:    repository,
+		bookRepo:      bookRepo,
+		bookStockRepo: bookStockRepo,
+		uow:           uow,
+		pickupDays:    pickupDays,
+	}
+}
+
+func (s *holdService) GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.HoldResponse], error) {
+	holds, total, err := s.repository.FindAll(page, perPage, search, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	holdResponses := make([]dto.HoldResponse, 0)
+	for _, hold := range holds {
+		response, err := s.mapWithPosition(&hold)
+		if err != nil {
+			return nil, err
+		}
+		holdResponses = append(holdResponses, response)
+	}
+
+	// Calculate total pages
+	totalPages := int64(total) / int64(perPage)
+	if int64(total)%int64(perPage) > 0 {
+		totalPages++
+	}
+
+	return &dto.PaginatedResponseData[[]dto.HoldResponse]{
+		Status:  200,
+		Message: "Holds retrieved successfully",
+		Data:    holdResponses,
+		Meta: dto.PaginationMeta{
+			Page:        page,
+			PerPage:     perPage,
+			TotalItems:  total,
+			TotalPages:  totalPages,
+			ItemsOnPage: int64(len(holdResponses)),
+		},
+	}, nil
+}
+
+func (s *holdService) GetByID(id uuid.UUID) (*dto.HoldResponse, error) {
+	hold, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s.mapWithPosition(hold)
+	if err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+// GetByBookID returns the active queue of a book: copies waiting for pickup first, then pending holds in order
+func (s *holdService) GetByBookID(bookID uuid.UUID) ([]dto.HoldResponse, error) {
+	holds, err := s.repository.FindByBookID(bookID, []string{model.StatusHoldReady, model.StatusHoldPending})
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.HoldResponse, 0, len(holds))
+	var position int64
+	for _, hold := range holds {
+		if hold.Status == model.StatusHoldReady {
+			responses = append(responses, mapToHoldResponse(&hold))
+		}
+	}
+	for _, hold := range holds {
+		if hold.Status == model.StatusHoldPending {
+			position++
+			response := mapToHoldResponse(&hold)
+			response.Position = position
+			responses = append(responses, response)
+		}
+	}
+
+	return responses, nil
+}
+
+// GetByCustomerID returns the holds of a customer, with the queue position of pending ones
+func (s *holdService) GetByCustomerID(customerID uuid.UUID) ([]dto.HoldResponse, error) {
+	holds, err := s.repository.FindByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.HoldResponse, 0, len(holds))
+	for _, hold := range holds {
+		response, err := s.mapWithPosition(&hold)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, response)
+	}
+
+	return responses, nil
+}
+
+func (s *holdService) Create(req dto.HoldCreateRequest) (*dto.HoldResponse, error) {
+	// Check if book exists
+	if _, err := s.bookRepo.FindByID(req.BookID); err != nil {
+		return nil, ErrBookNotFound
+	}
+
+	var hold model.Hold
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		// Lock the customer so the same hold cannot be placed twice concurrently
+		customer, err := repos.Customers.FindByIDForUpdate(req.CustomerID)
+		if err != nil {
+			return errors.New("customer not found")
+		}
+
+		if existing, err := repos.Holds.FindActiveByCustomerAndBook(customer.ID, req.BookID); err == nil && existing != nil {
+			return fmt.Errorf("%w: customer already has a %s hold on this book", ErrHoldNotAllowed, existing.Status)
+		}
+
+		// Holds are for titles that cannot be borrowed right away
+		available, err := repos.BookStocks.FindAvailableByBookID(req.BookID, nil)
+		if err != nil {
+			return err
+		}
+		if len(available) > 0 {
+			return fmt.Errorf("%w: %d copies of this book are available", ErrHoldNotAllowed, len(available))
+		}
+
+		hold = model.Hold{
+			ID:         uuid.New(),
+			BookID:     req.BookID,
+			CustomerID: customer.ID,
+			Status:     model.StatusHoldPending,
+		}
+		return repos.Holds.Create(&hold)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(hold.ID)
+}
+
+func (s *holdService) Cancel(id uuid.UUID, actor dto.UserData) (*dto.HoldResponse, error) {
+	hold, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("hold not found")
+	}
+
+	err = s.uow.Do(func(repos repository.Repositories) error {
+		// Copies are locked before holds, in the same order as at checkout
+		var bookStock *model.BookStock
+		if hold.Status == model.StatusHoldReady && hold.StockCode != nil {
+			bookStock, err = repos.BookStocks.FindByCodeForUpdate(*hold.StockCode)
+			if err != nil {
+				return ErrBookStockNotFound
+			}
+		}
+
+		locked, err := repos.Holds.FindByIDForUpdate(id)
+		if err != nil {
+			return errors.New("hold not found")
+		}
+		if locked.Status != hold.Status {
+			return fmt.Errorf("%w: hold changed while it was being cancelled", ErrHoldNotAllowed)
+		}
+		if locked.Status != model.StatusHoldPending && locked.Status != model.StatusHoldReady {
+			return fmt.Errorf("%w: hold is already %s", ErrHoldNotAllowed, locked.Status)
+		}
+
+		now := time.Now()
+		locked.Status = model.StatusHoldCancelled
+		locked.ClosedAt = &now
+		if err := repos.Holds.Update(locked); err != nil {
+			return err
+		}
+
+		// The copy set aside for this hold goes to the next customer in line
+		if bookStock != nil {
+			_, err := releaseCopyToQueue(repos, bookStock, model.StockActionHold, "hold cancelled", &actor.ID, s.pickupDays)
+			return err
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(id)
+}
+
+// ExpirePickups closes ready holds whose pickup deadline has passed and passes their copies on
+func (s *holdService) ExpirePickups() (*dto.HoldExpireResponse, error) {
+	holds, err := s.repository.FindExpiredReady(time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.HoldExpireResponse{}
+	var errs []error
+	for _, hold := range holds {
+		if hold.StockCode == nil {
+			continue
+		}
+
+		// Each hold is expired in its own database transaction so one failure does not block the rest
+		var expired, reassigned bool
+		err := s.uow.Do(func(repos repository.Repositories) error {
+			bookStock, err := repos.BookStocks.FindByCodeForUpdate(*hold.StockCode)
+			if err != nil {
+				return ErrBookStockNotFound
+			}
+
+			// The copy may have been borrowed in the meantime
+			locked, err := repos.Holds.FindByIDForUpdate(hold.ID)
+			if err != nil {
+				return errors.New("hold not found")
+			}
+			now := time.Now()
+			if locked.Status != model.StatusHoldReady || locked.PickupDeadline == nil || !locked.PickupDeadline.Before(now) {
+				return nil
+			}
+
+			locked.Status = model.StatusHoldExpired
+			locked.ClosedAt = &now
+			if err := repos.Holds.Update(locked); err != nil {
+				return err
+			}
+
+			next, err := releaseCopyToQueue(repos, bookStock, model.StockActionHold, "pickup deadline passed", nil, s.pickupDays)
+			if err != nil {
+				return err
+			}
+			expired, reassigned = true, next != nil
+			return nil
+		})
+		if err != nil {
+			result.Failed++
+			errs = append(errs, fmt.Errorf("hold %s: %w", hold.ID, err))
+			continue
+		}
+
+		// Only counted once committed
+		if expired {
+			result.Expired++
+		}
+		if reassigned {
+			result.Reassigned++
+		}
+	}
+
+	return result, errors.Join(errs...)
+}
+
+func (s *holdService) mapWithPosition(hold *model.Hold) (dto.HoldResponse, error) {
+	response := mapToHoldResponse(hold)
+	if hold.Status == model.StatusHoldPending {
+		ahead, err := s.repository.CountPendingBefore(hold.BookID, hold.CreatedAt)
+		if err != nil {
+			return response, err
+		}
+		response.Position = ahead + 1
+	}
+	return response, nil
+}
+
+// releaseCopyToQueue sets a copy that has become free aside for the oldest pending hold on its book,
+// or puts it back on the shelf when nobody is waiting. It returns the hold the copy was given to.
+func releaseCopyToQueue(repos repository.Repositories, bookStock *model.BookStock, action, reason string, userID *uuid.UUID, pickupDays int) (*model.Hold, error) {
+	next, err := repos.Holds.FindNextPendingForUpdate(bookStock.BookID)
+	if err != nil {
+		return nil, err
+	}
+
+	if next == nil {
+		if bookStock.Status == model.StatusAvailable {
+			return nil, nil
+		}
+		return nil, transitionBookStock(repos.BookStocks, bookStock, model.StatusAvailable, action, reason, userID)
+	}
+
+	if bookStock.Status != model.StatusOnHold {
+		if err := transitionBookStock(repos.BookStocks, bookStock, model.StatusOnHold, action, reason, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	deadline := now.AddDate(0, 0, pickupDays)
+	stockCode := bookStock.Code
+	next.Status = model.StatusHoldReady
+	next.StockCode = &stockCode
+	next.ReadyAt = &now
+	next.PickupDeadline = &deadline
+	if err := repos.Holds.Update(next); err != nil {
+		return nil, err
+	}
+	return next, nil
+}
+
+// Helper function to map a Hold entity to a HoldResponse DTO
+func mapToHoldResponse(hold *model.Hold) dto.HoldResponse {
+	response := dto.HoldResponse{
+		ID:             hold.ID,
+		BookID:         hold.BookID,
+		CustomerID:     hold.CustomerID,
+		Status:         hold.Status,
+		StockCode:      hold.StockCode,
+		ReadyAt:        hold.ReadyAt,
+		PickupDeadline: hold.PickupDeadline,
+		ClosedAt:       hold.ClosedAt,
+		CreatedAt:      hold.CreatedAt,
+	}
+
+	if hold.Book.ID != uuid.Nil {
+		response.Book = &dto.BookRes{
+			ID:           hold.Book.ID,
+			Title:        hold.Book.Title,
+			Description:  hold.Book.Description,
+			MaterialType: hold.Book.MaterialType,
+		}
+	}
+
+	if hold.Customer.ID != uuid.Nil {
+		response.Customer = &dto.CustomerResponse{
+			ID:       hold.Customer.ID,
+			Code:     hold.Customer.Code,
+			Name:     hold.Customer.Name,
+			Category: hold.Customer.Category,
+		}
+	}
+
+	return response
+}
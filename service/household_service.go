@@ -0,0 +1,213 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+type HouseholdService interface {
+	GetHousehold(actor dto.UserData, customerID uuid.UUID) (*dto.HouseholdResponse, error)
+	SetGuardian(customerID uuid.UUID, req dto.GuardianRequest) (*dto.CustomerResponse, error)
+	RemoveGuardian(customerID uuid.UUID) (*dto.CustomerResponse, error)
+}
+
+// ErrInvalidHousehold is returned when a guardian link would break the shape of a household
+var ErrInvalidHousehold = errors.New("invalid household")
+
+type householdService struct {
+	customerRepo        repository.CustomerRepository
+	bookTransactionRepo repository.BookTransactionRepository
+	chargeRepo          repository.ChargeRepository
+	uow                 repository.UnitOfWork
+}
+
+func NewHouseholdService(
+	customerRepo repository.CustomerRepository,
+	bookTransactionRepo repository.BookTransactionRepository,
+	chargeRepo repository.ChargeRepository,
+	uow repository.UnitOfWork,
+) HouseholdService {
+	return &householdService{
+		customerRepo:        customerRepo,
+		bookTransactionRepo: bookTransactionRepo,
+		chargeRepo:          chargeRepo,
+		uow:                 uow,
+	}
+}
+
+// GetHousehold lists the current loans and charges of the household a customer belongs to.
+// Members see their own household only as its guardian; a dependant's account sees its own records elsewhere.
+func (s *householdService) GetHousehold(actor dto.UserData, customerID uuid.UUID) (*dto.HouseholdResponse, error) {
+	if err := CheckCustomerAccess(actor, customerID); err != nil {
+		return nil, err
+	}
+
+	customer, err := s.customerRepo.FindByID(customerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	guardian := customer
+	if customer.GuardianID != nil {
+		if actor.Role != "admin" {
+			return nil, fmt.Errorf("%w: the household is only visible to its guardian", ErrCustomerAccessDenied)
+		}
+		guardian, err = s.customerRepo.FindByID(*customer.GuardianID)
+		if err != nil {
+			return nil, errors.New("guardian not found")
+		}
+	}
+
+	dependants, err := s.customerRepo.FindDependants(guardian.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.HouseholdResponse{
+		Guardian: mapToCustomerResponse(guardian),
+		Members:  make([]dto.HouseholdMemberResponse, 0, len(dependants)+1),
+	}
+	for _, member := range append([]model.Customer{*guardian}, dependants...) {
+		summary, err := s.householdMember(&member)
+		if err != nil {
+			return nil, err
+		}
+		summary.IsGuardian = member.ID == guardian.ID
+		response.ActiveLoans += len(summary.Loans)
+		response.Outstanding += summary.Outstanding
+		response.Members = append(response.Members, *summary)
+	}
+	response.Outstanding = roundMoney(response.Outstanding)
+
+	return response, nil
+}
+
+// SetGuardian makes one customer responsible for another. Households are one level deep:
+// a guardian cannot have a guardian, and a customer with dependants cannot become one.
+func (s *householdService) SetGuardian(customerID uuid.UUID, req dto.GuardianRequest) (*dto.CustomerResponse, error) {
+	if customerID == req.GuardianID {
+		return nil, fmt.Errorf("%w: a customer cannot be their own guardian", ErrInvalidHousehold)
+	}
+
+	var customer *model.Customer
+	err := s.uow.Do(func(repos repository.Repositories) error {
+		// Lock both customers in a fixed order so two links made at once cannot form a cycle
+		ids := []uuid.UUID{customerID, req.GuardianID}
+		sort.Slice(ids, func(i, j int) bool { return ids[i].String() < ids[j].String() })
+		locked := make(map[uuid.UUID]*model.Customer, len(ids))
+		for _, id := range ids {
+			c, err := repos.Customers.FindByIDForUpdate(id)
+			if err != nil {
+				if id == req.GuardianID {
+					return errors.New("guardian not found")
+				}
+				return errors.New("customer not found")
+			}
+			locked[id] = c
+		}
+		customer = locked[customerID]
+		guardian := locked[req.GuardianID]
+
+		if customer.AnonymizedAt != nil || guardian.AnonymizedAt != nil {
+			return fmt.Errorf("%w: anonymized customers cannot be part of a household", ErrInvalidHousehold)
+		}
+		if guardian.GuardianID != nil {
+			return fmt.Errorf("%w: guardian %s is a dependant of another customer", ErrInvalidHousehold, guardian.Code)
+		}
+		dependants, err := repos.Customers.FindDependants(customer.ID)
+		if err != nil {
+			return err
+		}
+		if len(dependants) > 0 {
+			return fmt.Errorf("%w: customer %s is the guardian of %d customers", ErrInvalidHousehold, customer.Code, len(dependants))
+		}
+
+		customer.GuardianID = &guardian.ID
+		return repos.Customers.Update(customer)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapToCustomerResponse(customer)
+	return &response, nil
+}
+
+// RemoveGuardian makes a dependant a customer on their own again
+func (s *householdService) RemoveGuardian(customerID uuid.UUID) (*dto.CustomerResponse, error) {
+	customer, err := s.customerRepo.FindByID(customerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+	if customer.GuardianID == nil {
+		return nil, fmt.Errorf("%w: customer has no guardian", ErrInvalidHousehold)
+	}
+
+	customer.GuardianID = nil
+	if err := s.customerRepo.Update(customer); err != nil {
+		return nil, err
+	}
+
+	response := mapToCustomerResponse(customer)
+	return &response, nil
+}
+
+// householdMember collects the current loans and the charges of one customer of a household
+func (s *householdService) householdMember(customer *model.Customer) (*dto.HouseholdMemberResponse, error) {
+	member := &dto.HouseholdMemberResponse{
+		Customer: mapToCustomerResponse(customer),
+		Loans:    make([]dto.BookTransactionResponse, 0),
+		Charges:  make([]dto.ChargeBalanceResponse, 0),
+	}
+
+	transactions, err := s.bookTransactionRepo.FindByCustomerID(customer.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, transaction := range transactions {
+		if transaction.Status == model.StatusBTBorrowed || transaction.Status == model.StatusBTOverdue {
+			member.Loans = append(member.Loans, mapToBookTransactionResponse(&transaction))
+		}
+	}
+
+	charges, err := s.chargeRepo.FindByCustomerID(customer.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, charge := range charges {
+		balance := mapToChargeBalanceResponse(&charge)
+		if balance.Balance > 0 {
+			member.Outstanding += balance.Balance
+		}
+		member.Charges = append(member.Charges, balance)
+	}
+	member.Outstanding = roundMoney(member.Outstanding)
+
+	return member, nil
+}
+
+// householdMemberIDs returns the customers whose fines count together with a customer's:
+// the guardian of the household and every dependant
+func householdMemberIDs(customers repository.CustomerRepository, customer *model.Customer) ([]uuid.UUID, error) {
+	guardianID := customer.ID
+	if customer.GuardianID != nil {
+		guardianID = *customer.GuardianID
+	}
+
+	dependants, err := customers.FindDependants(guardianID)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := []uuid.UUID{guardianID}
+	for _, dependant := range dependants {
+		ids = append(ids, dependant.ID)
+	}
+	return ids, nil
+}
@@ -0,0 +1,362 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Statuses of copies that are expected to be found on the shelves during stock-taking. Copies on
+// the hold shelf are left out: they belong to a customer's hold, which expires on its own.
+var inventoryExpectedStatuses = []string{model.StatusAvailable, model.StatusDamaged}
+
+type InventoryService interface {
+	GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.InventorySessionResponse], error)
+	GetByID(id uuid.UUID) (*dto.InventorySessionResponse, error)
+	Create(actor dto.UserData, req dto.InventorySessionCreateRequest) (*dto.InventorySessionResponse, error)
+	Scan(id uuid.UUID, actor dto.UserData, req dto.InventoryScanRequest) (*dto.InventoryScanResponse, error)
+	Close(id uuid.UUID, actor dto.UserData) (*dto.InventorySessionResponse, error)
+	GetReport(id uuid.UUID) (*dto.InventoryReportResponse, error)
+	MarkMissingLost(id uuid.UUID, actor dto.UserData, req dto.InventoryMarkLostRequest) (*dto.InventoryMarkLostResponse, error)
+}
+
+type inventoryService struct {
+	repository    repository.InventoryRepository
+	bookStockRepo repository.BookStockRepository
+	uow           repository.UnitOfWork
+}
+
+func NewInventoryService(repository repository.InventoryRepository, bookStockRepo repository.BookStockRepository, uow repository.UnitOfWork) InventoryService {
+	return &inventoryService{
+		repository:    repository,
+		bookStockRepo: bookStockRepo,
+		uow:           uow,
+	}
+}
+
+func (s *inventoryService) GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.InventorySessionResponse], error) {
+	sessions, total, err := s.repository.FindAll(page, perPage, search, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionResponses := make([]dto.InventorySessionResponse, 0)
+	for _, session := range sessions {
+		scanCount, err := s.repository.CountScans(session.ID)
+		if err != nil {
+			return nil, err
+		}
+		sessionResponses = append(sessionResponses, mapToInventorySessionResponse(&session, scanCount))
+	}
+
+	// Calculate total pages
+	totalPages := int64(total) / int64(perPage)
+	if int64(total)%int64(perPage) > 0 {
+		totalPages++
+	}
+
+	return &dto.PaginatedResponseData[[]dto.InventorySessionResponse]{
+		Status:  200,
+		Message: "Inventory sessions retrieved successfully",
+		Data:    sessionResponses,
+		Meta: dto.PaginationMeta{
+			Page:        page,
+			PerPage:     perPage,
+			TotalItems:  total,
+			TotalPages:  totalPages,
+			ItemsOnPage: int64(len(sessionResponses)),
+		},
+	}, nil
+}
+
+func (s *inventoryService) GetByID(id uuid.UUID) (*dto.InventorySessionResponse, error) {
+	session, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("inventory session not found")
+	}
+
+	scanCount, err := s.repository.CountScans(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapToInventorySessionResponse(session, scanCount)
+	return &response, nil
+}
+
+func (s *inventoryService) Create(actor dto.UserData, req dto.InventorySessionCreateRequest) (*dto.InventorySessionResponse, error) {
+	// Sessions without a branch cover the whole collection
+	branchID, err := resolveActorBranch(actor, req.BranchID)
+	if err != nil {
+		return nil, err
+	}
+
+	session := model.InventorySession{
+		ID:        uuid.New(),
+		Name:      req.Name,
+		Notes:     req.Notes,
+		Status:    model.StatusInventoryOpen,
+		BranchID:  branchID,
+		UserID:    actor.ID,
+		StartedAt: time.Now(),
+	}
+
+	if err := s.repository.Create(&session); err != nil {
+		return nil, err
+	}
+
+	response := mapToInventorySessionResponse(&session, 0)
+	return &response, nil
+}
+
+func (s *inventoryService) Scan(id uuid.UUID, actor dto.UserData, req dto.InventoryScanRequest) (*dto.InventoryScanResponse, error) {
+	session, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("inventory session not found")
+	}
+
+	if err := checkBranchAccess(actor, session.BranchID); err != nil {
+		return nil, err
+	}
+
+	if session.Status != model.StatusInventoryOpen {
+		return nil, errors.New("inventory session is closed")
+	}
+
+	// Unknown codes are still stored so they show up in the report
+	now := time.Now()
+	seen := make(map[string]bool, len(req.Codes))
+	scans := make([]model.InventoryScan, 0, len(req.Codes))
+	codes := make([]string, 0, len(req.Codes))
+	for _, code := range req.Codes {
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		codes = append(codes, code)
+		scans = append(scans, model.InventoryScan{
+			ID:        uuid.New(),
+			SessionID: session.ID,
+			Code:      code,
+			UserID:    actor.ID,
+			ScannedAt: now,
+		})
+	}
+
+	accepted, err := s.repository.CreateScans(scans)
+	if err != nil {
+		return nil, err
+	}
+
+	bookStocks, err := s.bookStockRepo.FindByCodes(codes)
+	if err != nil {
+		return nil, err
+	}
+	known := make(map[string]bool, len(bookStocks))
+	for _, bookStock := range bookStocks {
+		known[bookStock.Code] = true
+	}
+
+	unknownCodes := make([]string, 0)
+	for _, code := range codes {
+		if !known[code] {
+			unknownCodes = append(unknownCodes, code)
+		}
+	}
+
+	return &dto.InventoryScanResponse{
+		Accepted:     accepted,
+		Duplicates:   int64(len(req.Codes)) - accepted,
+		UnknownCodes: unknownCodes,
+	}, nil
+}
+
+func (s *inventoryService) Close(id uuid.UUID, actor dto.UserData) (*dto.InventorySessionResponse, error) {
+	session, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("inventory session not found")
+	}
+
+	if err := checkBranchAccess(actor, session.BranchID); err != nil {
+		return nil, err
+	}
+
+	if session.Status != model.StatusInventoryOpen {
+		return nil, errors.New("inventory session is already closed")
+	}
+
+	now := time.Now()
+	session.Status = model.StatusInventoryClosed
+	session.ClosedAt = &now
+
+	if err := s.repository.Update(session); err != nil {
+		return nil, err
+	}
+
+	scanCount, err := s.repository.CountScans(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapToInventorySessionResponse(session, scanCount)
+	return &response, nil
+}
+
+func (s *inventoryService) GetReport(id uuid.UUID) (*dto.InventoryReportResponse, error) {
+	session, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("inventory session not found")
+	}
+
+	scanCount, err := s.repository.CountScans(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	missing, err := s.repository.FindMissingStocks(session.ID, session.BranchID, inventoryExpectedStatuses)
+	if err != nil {
+		return nil, err
+	}
+
+	borrowed, err := s.repository.FindScannedStocksByStatus(session.ID, model.StatusBorrowed)
+	if err != nil {
+		return nil, err
+	}
+
+	unknownCodes, err := s.repository.FindUnknownCodes(session.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := dto.InventoryReportResponse{
+		Session:            mapToInventorySessionResponse(session, scanCount),
+		ExpectedNotScanned: make([]dto.BookStockResponse, 0, len(missing)),
+		ScannedButBorrowed: make([]dto.BookStockResponse, 0, len(borrowed)),
+		UnknownCodes:       unknownCodes,
+	}
+	for _, bookStock := range missing {
+		response.ExpectedNotScanned = append(response.ExpectedNotScanned, mapToBookStockResponse(&bookStock))
+	}
+	for _, bookStock := range borrowed {
+		response.ScannedButBorrowed = append(response.ScannedButBorrowed, mapToBookStockResponse(&bookStock))
+	}
+	if response.UnknownCodes == nil {
+		response.UnknownCodes = make([]string, 0)
+	}
+
+	return &response, nil
+}
+
+func (s *inventoryService) MarkMissingLost(id uuid.UUID, actor dto.UserData, req dto.InventoryMarkLostRequest) (*dto.InventoryMarkLostResponse, error) {
+	session, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("inventory session not found")
+	}
+
+	if err := checkBranchAccess(actor, session.BranchID); err != nil {
+		return nil, err
+	}
+
+	// Results are only final once scanning has stopped
+	if session.Status != model.StatusInventoryClosed {
+		return nil, errors.New("inventory session must be closed before marking copies as lost")
+	}
+
+	missing, err := s.repository.FindMissingStocks(session.ID, session.BranchID, inventoryExpectedStatuses)
+	if err != nil {
+		return nil, err
+	}
+
+	selected := missing
+	if len(req.Codes) > 0 {
+		missingByCode := make(map[string]model.BookStock, len(missing))
+		for _, bookStock := range missing {
+			missingByCode[bookStock.Code] = bookStock
+		}
+
+		selected = make([]model.BookStock, 0, len(req.Codes))
+		for _, code := range req.Codes {
+			bookStock, ok := missingByCode[code]
+			if !ok {
+				return nil, fmt.Errorf("book stock %s is not missing in this inventory session", code)
+			}
+			selected = append(selected, bookStock)
+		}
+	}
+
+	codes := make([]string, 0, len(selected))
+	for _, bookStock := range selected {
+		codes = append(codes, bookStock.Code)
+	}
+
+	// All copies are marked in one transaction, so a failure leaves none of them Lost
+	reason := fmt.Sprintf("Not found during inventory session %s", session.Name)
+	err = s.uow.Do(func(repos repository.Repositories) error {
+		bookStocks, err := lockBookStocks(repos.BookStocks, codes...)
+		if err != nil {
+			return err
+		}
+
+		for _, code := range codes {
+			// A copy lent or moved since the report was built is no longer missing
+			bookStock := bookStocks[code]
+			if !isInventoryExpected(bookStock, session.BranchID) {
+				return fmt.Errorf("book stock %s changed since the inventory session and is no longer missing", code)
+			}
+			if err := transitionBookStock(repos.BookStocks, bookStock, model.StatusLost, model.StockActionUpdate, reason, &actor.ID); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.InventoryMarkLostResponse{Codes: codes}, nil
+}
+
+// Helper function to check that a copy is still one the session expected on the shelves
+func isInventoryExpected(bookStock *model.BookStock, branchID *uuid.UUID) bool {
+	if branchID != nil && (bookStock.CurrentBranchID == nil || *bookStock.CurrentBranchID != *branchID) {
+		return false
+	}
+	for _, status := range inventoryExpectedStatuses {
+		if bookStock.Status == status {
+			return true
+		}
+	}
+	return false
+}
+
+// Helper function to map an InventorySession entity to an InventorySessionResponse DTO
+func mapToInventorySessionResponse(session *model.InventorySession, scanCount int64) dto.InventorySessionResponse {
+	response := dto.InventorySessionResponse{
+		ID:        session.ID,
+		Name:      session.Name,
+		Notes:     session.Notes,
+		Status:    session.Status,
+		BranchID:  session.BranchID,
+		UserID:    session.UserID,
+		ScanCount: scanCount,
+		StartedAt: session.StartedAt,
+		ClosedAt:  session.ClosedAt,
+	}
+
+	if session.User.ID != uuid.Nil {
+		response.User = &dto.UserData{
+			ID:    session.User.ID,
+			Name:  session.User.Name,
+			Email: session.User.Email,
+			Role:  session.User.Role,
+		}
+	}
+
+	return response
+}
@@ -0,0 +1,143 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+)
+
+// Size in pixels of single PNG labels
+const (
+	labelImageWidth  = 400
+	labelImageHeight = 150
+	labelQRSize      = 300
+)
+
+// ErrLabelNotFound is returned when a label is requested for a code that does not exist
+var ErrLabelNotFound = errors.New("label code not found")
+
+// ErrInvalidLabelFormat is returned when a label is requested in an unsupported format
+var ErrInvalidLabelFormat = errors.New("invalid label format")
+
+type LabelService interface {
+	GetBookStockLabel(code, format string) ([]byte, error)
+	GetCustomerLabel(code, format string) ([]byte, error)
+	GetBookStockLabelSheet(req dto.LabelSheetRequest) ([]byte, error)
+	GetCustomerLabelSheet(req dto.LabelSheetRequest) ([]byte, error)
+}
+
+type labelService struct {
+	bookStockRepo repository.BookStockRepository
+	customerRepo  repository.CustomerRepository
+}
+
+func NewLabelService(bookStockRepo repository.BookStockRepository, customerRepo repository.CustomerRepository) LabelService {
+	return &labelService{
+		bookStockRepo: bookStockRepo,
+		customerRepo:  customerRepo,
+	}
+}
+
+func (s *labelService) GetBookStockLabel(code, format string) ([]byte, error) {
+	// Check if book stock exists
+	bookStock, err := s.bookStockRepo.FindByCode(code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: book stock %s", ErrLabelNotFound, code)
+	}
+
+	return renderLabelImage(bookStock.Code, format)
+}
+
+func (s *labelService) GetCustomerLabel(code, format string) ([]byte, error) {
+	// Check if customer exists
+	customer, err := s.customerRepo.FindByCode(code)
+	if err != nil {
+		return nil, fmt.Errorf("%w: customer %s", ErrLabelNotFound, code)
+	}
+
+	return renderLabelImage(customer.Code, format)
+}
+
+func (s *labelService) GetBookStockLabelSheet(req dto.LabelSheetRequest) ([]byte, error) {
+	if err := validateLabelFormat(req.Format); err != nil {
+		return nil, err
+	}
+
+	bookStocks, err := s.bookStockRepo.FindByCodes(req.Codes)
+	if err != nil {
+		return nil, err
+	}
+
+	stockByCode := make(map[string]model.BookStock, len(bookStocks))
+	for _, bookStock := range bookStocks {
+		stockByCode[bookStock.Code] = bookStock
+	}
+
+	// Keep the requested order so labels come out the way staff listed them
+	labels := make([]lib.Label, 0, len(req.Codes))
+	for _, code := range req.Codes {
+		bookStock, ok := stockByCode[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: book stock %s", ErrLabelNotFound, code)
+		}
+		labels = append(labels, lib.Label{
+			Code:    bookStock.Code,
+			Caption: bookStock.Book.Title,
+		})
+	}
+
+	return lib.GenerateLabelSheetPDF(labels, req.Format)
+}
+
+func (s *labelService) GetCustomerLabelSheet(req dto.LabelSheetRequest) ([]byte, error) {
+	if err := validateLabelFormat(req.Format); err != nil {
+		return nil, err
+	}
+
+	customers, err := s.customerRepo.FindByCodes(req.Codes)
+	if err != nil {
+		return nil, err
+	}
+
+	customerByCode := make(map[string]model.Customer, len(customers))
+	for _, customer := range customers {
+		customerByCode[customer.Code] = customer
+	}
+
+	labels := make([]lib.Label, 0, len(req.Codes))
+	for _, code := range req.Codes {
+		customer, ok := customerByCode[code]
+		if !ok {
+			return nil, fmt.Errorf("%w: customer %s", ErrLabelNotFound, code)
+		}
+		labels = append(labels, lib.Label{
+			Code:    customer.Code,
+			Caption: customer.Name,
+		})
+	}
+
+	return lib.GenerateLabelSheetPDF(labels, req.Format)
+}
+
+// Helper function to render a single label as PNG in the requested format
+func renderLabelImage(code, format string) ([]byte, error) {
+	if err := validateLabelFormat(format); err != nil {
+		return nil, err
+	}
+	if format == lib.LabelFormatQR {
+		return lib.GenerateBarcodePNG(code, format, labelQRSize, labelQRSize)
+	}
+	return lib.GenerateBarcodePNG(code, format, labelImageWidth, labelImageHeight)
+}
+
+// Helper function to reject formats the label generator cannot render
+// An empty format is allowed and falls back to Code128
+func validateLabelFormat(format string) error {
+	if format != "" && format != lib.LabelFormatCode128 && format != lib.LabelFormatQR {
+		return fmt.Errorf("%w: %s", ErrInvalidLabelFormat, format)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"fmt"
+	"go-gin-simple-api/model"
+	"strings"
+	"time"
+)
+
+// ClosedDays tells the late fee engine on which days the library is closed
+type ClosedDays interface {
+	IsClosed(day time.Time) bool
+}
+
+// WeeklyClosedDays closes the library on the same weekdays every week
+type WeeklyClosedDays []time.Weekday
+
+func (w WeeklyClosedDays) IsClosed(day time.Time) bool {
+	for _, weekday := range w {
+		if day.Weekday() == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseWeeklyClosedDays parses a comma separated list of weekday names such as "Saturday,Sunday"
+func ParseWeeklyClosedDays(value string) (WeeklyClosedDays, error) {
+	var days WeeklyClosedDays
+	for _, name := range strings.Split(value, ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		found := false
+		for weekday := time.Sunday; weekday <= time.Saturday; weekday++ {
+			if strings.EqualFold(weekday.String(), name) || strings.EqualFold(weekday.String()[:3], name) {
+				days = append(days, weekday)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown weekday %q", name)
+		}
+	}
+	return days, nil
+}
+
+// lateFee is the fee owed for returning a loan late
+type lateFee struct {
+	DaysLate     int
+	DailyLateFee float64
+	Total        float64
+}
+
+// lateFeeAsOf is the moment a loan's late fee runs until: its return, or now while it is still open
+func lateFeeAsOf(transaction *model.BookTransaction) time.Time {
+	if transaction.ReturnAt != nil {
+		return *transaction.ReturnAt
+	}
+	return time.Now()
+}
+
+// assessLateFee computes the late fee of a loan at asOf from the fee terms copied onto the loan at
+// checkout, charging dailyLateFee per day. Every started 24 hours past the due date is a late day;
+// closed days are left out when the terms say so, the grace period is deducted and the total is capped.
+func assessLateFee(transaction *model.BookTransaction, dailyLateFee float64, asOf time.Time, closed ClosedDays) lateFee {
+	fee := lateFee{DailyLateFee: dailyLateFee}
+
+	duration := asOf.Sub(transaction.DueDate)
+	if duration <= 0 {
+		return fee
+	}
+
+	elapsedDays := int(duration.Hours() / 24)
+	if duration.Hours()/24 > float64(elapsedDays) {
+		elapsedDays++
+	}
+
+	for i := 0; i < elapsedDays; i++ {
+		day := transaction.DueDate.Add(time.Duration(i) * 24 * time.Hour)
+		if transaction.SkipClosedDays && closed != nil && closed.IsClosed(day) {
+			continue
+		}
+		fee.DaysLate++
+	}
+
+	fee.DaysLate -= transaction.GracePeriodDays
+	if fee.DaysLate < 0 {
+		fee.DaysLate = 0
+	}
+
+	fee.Total = float64(fee.DaysLate) * fee.DailyLateFee
+	if transaction.MaxLateFee > 0 && fee.Total > transaction.MaxLateFee {
+		fee.Total = transaction.MaxLateFee
+	}
+	return fee
+}
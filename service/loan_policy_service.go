@@ -0,0 +1,197 @@
+package service
+
+import (
+	"errors"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+
+	"github.com/google/uuid"
+)
+
+// defaultLoanPolicy applies when no configured policy matches a checkout
+var defaultLoanPolicy = model.LoanPolicy{
+	Name:           "Default",
+	LoanPeriodDays: 7,
+	MaxRenewals:    2,
+}
+
+type LoanPolicyService interface {
+	GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.LoanPolicyResponse], error)
+	GetByID(id uuid.UUID) (*dto.LoanPolicyResponse, error)
+	Create(req dto.LoanPolicyCreateRequest) (*dto.LoanPolicyResponse, error)
+	Update(id uuid.UUID, req dto.LoanPolicyUpdateRequest) (*dto.LoanPolicyResponse, error)
+	Delete(id uuid.UUID) error
+}
+
+type loanPolicyService struct {
+	repository repository.LoanPolicyRepository
+}
+
+func NewLoanPolicyService(repository repository.LoanPolicyRepository) LoanPolicyService {
+	return &loanPolicyService{
+		repository: repository,
+	}
+}
+
+func (s *loanPolicyService) GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.LoanPolicyResponse], error) {
+	policies, total, err := s.repository.FindAll(page, perPage, search, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	policyResponses := make([]dto.LoanPolicyResponse, 0)
+	for _, policy := range policies {
+		policyResponses = append(policyResponses, mapToLoanPolicyResponse(&policy))
+	}
+
+	// Calculate total pages
+	totalPages := int64(total) / int64(perPage)
+	if int64(total)%int64(perPage) > 0 {
+		totalPages++
+	}
+
+	return &dto.PaginatedResponseData[[]dto.LoanPolicyResponse]{
+		Status:  200,
+		Message: "Loan policies retrieved successfully",
+		Data:    policyResponses,
+		Meta: dto.PaginationMeta{
+			Page:        page,
+			PerPage:     perPage,
+			TotalItems:  total,
+			TotalPages:  totalPages,
+			ItemsOnPage: int64(len(policyResponses)),
+		},
+	}, nil
+}
+
+func (s *loanPolicyService) GetByID(id uuid.UUID) (*dto.LoanPolicyResponse, error) {
+	policy, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("loan policy not found")
+	}
+
+	response := mapToLoanPolicyResponse(policy)
+	return &response, nil
+}
+
+func (s *loanPolicyService) Create(req dto.LoanPolicyCreateRequest) (*dto.LoanPolicyResponse, error) {
+	// Only one policy may cover a category and material type combination
+	if existing, err := s.repository.FindByScope(req.CustomerCategory, req.MaterialType); err == nil && existing != nil {
+		return nil, errors.New("a loan policy already exists for this customer category and material type")
+	}
+
+	policy := model.LoanPolicy{
+		ID:               uuid.New(),
+		Name:             req.Name,
+		CustomerCategory: req.CustomerCategory,
+		MaterialType:     req.MaterialType,
+		LoanPeriodDays:   req.LoanPeriodDays,
+		MaxLoans:         req.MaxLoans,
+		DailyLateFee:     req.DailyLateFee,
+		MaxRenewals:      req.MaxRenewals,
+		GracePeriodDays:  req.GracePeriodDays,
+		MaxLateFee:       req.MaxLateFee,
+		SkipClosedDays:   req.SkipClosedDays,
+	}
+
+	if err := s.repository.Create(&policy); err != nil {
+		return nil, err
+	}
+
+	response := mapToLoanPolicyResponse(&policy)
+	return &response, nil
+}
+
+func (s *loanPolicyService) Update(id uuid.UUID, req dto.LoanPolicyUpdateRequest) (*dto.LoanPolicyResponse, error) {
+	policy, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("loan policy not found")
+	}
+
+	// Update fields if provided
+	if req.Name != nil {
+		policy.Name = *req.Name
+	}
+	if req.CustomerCategory != nil {
+		policy.CustomerCategory = *req.CustomerCategory
+	}
+	if req.MaterialType != nil {
+		policy.MaterialType = *req.MaterialType
+	}
+	if req.LoanPeriodDays != nil {
+		policy.LoanPeriodDays = *req.LoanPeriodDays
+	}
+	if req.MaxLoans != nil {
+		policy.MaxLoans = *req.MaxLoans
+	}
+	if req.DailyLateFee != nil {
+		policy.DailyLateFee = *req.DailyLateFee
+	}
+	if req.MaxRenewals != nil {
+		policy.MaxRenewals = *req.MaxRenewals
+	}
+	if req.GracePeriodDays != nil {
+		policy.GracePeriodDays = *req.GracePeriodDays
+	}
+	if req.MaxLateFee != nil {
+		policy.MaxLateFee = *req.MaxLateFee
+	}
+	if req.SkipClosedDays != nil {
+		policy.SkipClosedDays = *req.SkipClosedDays
+	}
+
+	existing, err := s.repository.FindByScope(policy.CustomerCategory, policy.MaterialType)
+	if err == nil && existing != nil && existing.ID != policy.ID {
+		return nil, errors.New("a loan policy already exists for this customer category and material type")
+	}
+
+	if err := s.repository.Update(policy); err != nil {
+		return nil, err
+	}
+
+	response := mapToLoanPolicyResponse(policy)
+	return &response, nil
+}
+
+func (s *loanPolicyService) Delete(id uuid.UUID) error {
+	if _, err := s.repository.FindByID(id); err != nil {
+		return errors.New("loan policy not found")
+	}
+
+	// Loans keep their own copy of the terms, so removing a policy does not change them
+	return s.repository.Delete(id)
+}
+
+// resolveLoanPolicy returns the policy that applies to a customer borrowing a book
+func resolveLoanPolicy(repo repository.LoanPolicyRepository, customer *model.Customer, book *model.Book) (*model.LoanPolicy, error) {
+	policy, err := repo.FindMatching(customer.Category, book.MaterialType)
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		fallback := defaultLoanPolicy
+		return &fallback, nil
+	}
+	return policy, nil
+}
+
+// Helper function to map a LoanPolicy entity to a LoanPolicyResponse DTO
+func mapToLoanPolicyResponse(policy *model.LoanPolicy) dto.LoanPolicyResponse {
+	return dto.LoanPolicyResponse{
+		ID:               policy.ID,
+		Name:             policy.Name,
+		CustomerCategory: policy.CustomerCategory,
+		MaterialType:     policy.MaterialType,
+		LoanPeriodDays:   policy.LoanPeriodDays,
+		MaxLoans:         policy.MaxLoans,
+		DailyLateFee:     policy.DailyLateFee,
+		MaxRenewals:      policy.MaxRenewals,
+		GracePeriodDays:  policy.GracePeriodDays,
+		MaxLateFee:       policy.MaxLateFee,
+		SkipClosedDays:   policy.SkipClosedDays,
+		CreatedAt:        policy.CreatedAt,
+		UpdatedAt:        policy.UpdatedAt,
+	}
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Reason recorded on the ledger when a lost copy is returned after all
+const lostItemReturnedReason = "lost item returned"
+
+// newReplacementCharge charges the customer of a loan for a lost or damaged copy: the copy's
+// price plus the processing fee. The loan is closed, so the charge is frozen from the start.
+func newReplacementCharge(transaction *model.BookTransaction, bookStock *model.BookStock, processingFee float64, userID uuid.UUID, now time.Time) model.Charge {
+	return model.Charge{
+		ID:                uuid.New(),
+		BookTransactionID: transaction.ID,
+		Type:              model.ChargeTypeReplacement,
+		Total:             roundMoney(bookStock.Price + processingFee),
+		ReplacementCost:   bookStock.Price,
+		ProcessingFee:     processingFee,
+		UserID:            userID,
+		CreatedAt:         now,
+		FrozenAt:          &now,
+	}
+}
+
+// reverseReplacementCharge credits back the replacement cost of a lost copy that was returned.
+// The processing fee stays owed; whatever the customer already paid beyond it is refunded.
+func reverseReplacementCharge(repos repository.Repositories, chargeID, customerID uuid.UUID, actor dto.UserData, refundMethod string) error {
+	// Lock the charge so a payment taken at the same time is counted in the refund
+	charge, err := repos.Charges.FindByIDForUpdate(chargeID)
+	if err != nil {
+		return err
+	}
+	if charge.ReversedAt != nil {
+		return nil
+	}
+
+	ledger := summarizeCharge(charge)
+	credit := charge.ReplacementCost
+	refund := roundMoney(math.Min(math.Max(0, credit-ledger.Balance), ledger.Paid))
+	reversal := roundMoney(math.Min(credit, math.Max(0, ledger.Balance)) + refund)
+
+	if reversal > 0 {
+		adjustment := model.ChargeAdjustment{
+			ID:           uuid.New(),
+			ChargeID:     charge.ID,
+			Type:         model.AdjustmentReversal,
+			Amount:       reversal,
+			Reason:       lostItemReturnedReason,
+			ApprovedByID: actor.ID,
+			UserID:       actor.ID,
+		}
+		if err := repos.Payments.CreateAdjustment(&adjustment); err != nil {
+			return err
+		}
+	}
+
+	if refund > 0 {
+		if refundMethod == "" {
+			refundMethod = model.PaymentMethodCash
+		}
+		payback := model.Refund{
+			ID:         uuid.New(),
+			ChargeID:   charge.ID,
+			CustomerID: customerID,
+			Amount:     refund,
+			Method:     refundMethod,
+			Reason:     lostItemReturnedReason,
+			UserID:     actor.ID,
+		}
+		if err := repos.Payments.CreateRefund(&payback); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	charge.ReversedAt = &now
+	return repos.Charges.Update(charge)
+}
@@ -0,0 +1,704 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"math"
+	"net/mail"
+	"text/template"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationService interface {
+	GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.NotificationResponse], error)
+	GetByCustomerID(customerID uuid.UUID) ([]dto.NotificationResponse, error)
+	GetPreferences(customerID uuid.UUID) ([]dto.NotificationPreferenceResponse, error)
+	UpdatePreferences(actor dto.UserData, customerID uuid.UUID, req dto.NotificationPreferencesRequest) ([]dto.NotificationPreferenceResponse, error)
+	GetTemplates() ([]dto.NotificationTemplateResponse, error)
+	UpdateTemplate(req dto.NotificationTemplateRequest) (*dto.NotificationTemplateResponse, error)
+	SendPending() (*dto.NotificationRunResponse, error)
+}
+
+// ErrInvalidNotificationSettings is returned when preferences or templates cannot be used to send notices
+var ErrInvalidNotificationSettings = errors.New("invalid notification settings")
+
+// NotificationSchedule decides when customers are told about their loans
+type NotificationSchedule struct {
+	// Days before the due date the reminder goes out; 0 sends none
+	DueReminderDays int
+	// Days between overdue notices, the first going out once a loan is overdue
+	OverdueIntervalDays int
+	// Overdue notices sent per loan at most; 0 sends them until the loan is closed
+	MaxOverdueNotices int
+	// Delivery attempts per notice and channel before it is given up
+	MaxAttempts int
+}
+
+// notificationTemplateData holds the fields notice templates can use
+// RecipientName is who the notice goes to: the customer, or the guardian of a dependant.
+// Borrower reads "you" when they are the same, otherwise the customer's name.
+type notificationTemplateData struct {
+	RecipientName  string
+	Borrower       string
+	CustomerName   string
+	CustomerCode   string
+	BookTitle      string
+	StockCode      string
+	DueDate        string
+	Days           int
+	NoticeNumber   int
+	PickupDeadline string
+}
+
+// defaultNotificationTemplates is the wording used on every channel unless a custom template replaces it
+var defaultNotificationTemplates = map[string]model.NotificationTemplate{
+	model.NotificationTypeDueReminder: {
+		Subject: `Reminder: "{{.BookTitle}}" is due on {{.DueDate}}`,
+		Body:    `Hello {{.RecipientName}}, the book "{{.BookTitle}}" ({{.StockCode}}) {{.Borrower}} borrowed is due on {{.DueDate}}, in {{.Days}} day(s). Please return or renew it in time to avoid late fees.`,
+	},
+	model.NotificationTypeOverdueNotice: {
+		Subject: `Overdue notice {{.NoticeNumber}}: "{{.BookTitle}}"`,
+		Body:    `Hello {{.RecipientName}}, the book "{{.BookTitle}}" ({{.StockCode}}) {{.Borrower}} borrowed was due on {{.DueDate}} and is now {{.Days}} day(s) overdue. Late fees apply until it is returned.`,
+	},
+	model.NotificationTypeHoldReady: {
+		Subject: `Your hold is ready: "{{.BookTitle}}"`,
+		Body:    `Hello {{.RecipientName}}, the book "{{.BookTitle}}" {{.Borrower}} placed a hold on is waiting at the library. Please pick it up by {{.PickupDeadline}}.`,
+	},
+}
+
+var notificationTypes = []string{model.NotificationTypeDueReminder, model.NotificationTypeOverdueNotice, model.NotificationTypeHoldReady}
+var notificationChannels = []string{model.NotificationChannelEmail, model.NotificationChannelSMS, model.NotificationChannelWebhook}
+
+type notificationService struct {
+	repository          repository.NotificationRepository
+	bookTransactionRepo repository.BookTransactionRepository
+	holdRepo            repository.HoldRepository
+	customerRepo        repository.CustomerRepository
+	channels            map[string]lib.NotificationChannel
+	schedule            NotificationSchedule
+}
+
+func NewNotificationService(
+	repository repository.NotificationRepository,
+	bookTransactionRepo repository.BookTransactionRepository,
+	holdRepo repository.HoldRepository,
+	customerRepo repository.CustomerRepository,
+	channels []lib.NotificationChannel,
+	schedule NotificationSchedule,
+) NotificationService {
+	byName := make(map[string]lib.NotificationChannel, len(channels))
+	for _, channel := range channels {
+		byName[channel.Name()] = channel
+	}
+	if schedule.MaxAttempts < 1 {
+		schedule.MaxAttempts = 1
+	}
+
+	return &notificationService{
+		repository:          repository,
+		bookTransactionRepo: bookTransactionRepo,
+		holdRepo:            holdRepo,
+		customerRepo:        customerRepo,
+		channels:            byName,
+		schedule:            schedule,
+	}
+}
+
+func (s *notificationService) GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.NotificationResponse], error) {
+	notifications, total, err := s.repository.FindAll(page, perPage, search, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		responses = append(responses, mapToNotificationResponse(&notification))
+	}
+
+	// Calculate total pages
+	totalPages := int64(total) / int64(perPage)
+	if int64(total)%int64(perPage) > 0 {
+		totalPages++
+	}
+
+	return &dto.PaginatedResponseData[[]dto.NotificationResponse]{
+		Status:  200,
+		Message: "Notifications retrieved successfully",
+		Data:    responses,
+		Meta: dto.PaginationMeta{
+			Page:        page,
+			PerPage:     perPage,
+			TotalItems:  total,
+			TotalPages:  totalPages,
+			ItemsOnPage: int64(len(responses)),
+		},
+	}, nil
+}
+
+func (s *notificationService) GetByCustomerID(customerID uuid.UUID) ([]dto.NotificationResponse, error) {
+	notifications, err := s.repository.FindByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.NotificationResponse, 0, len(notifications))
+	for _, notification := range notifications {
+		responses = append(responses, mapToNotificationResponse(&notification))
+	}
+	return responses, nil
+}
+
+func (s *notificationService) GetPreferences(customerID uuid.UUID) ([]dto.NotificationPreferenceResponse, error) {
+	if _, err := s.customerRepo.FindByID(customerID); err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	preferences, err := s.repository.FindPreferencesByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.NotificationPreferenceResponse, 0, len(preferences))
+	for _, preference := range preferences {
+		responses = append(responses, mapToNotificationPreferenceResponse(&preference))
+	}
+	return responses, nil
+}
+
+// UpdatePreferences replaces the channels a customer receives notices on.
+// Only admins may set addresses of their own; members receive notices at the email and
+// phone on their customer record and on the library's default webhook.
+func (s *notificationService) UpdatePreferences(actor dto.UserData, customerID uuid.UUID, req dto.NotificationPreferencesRequest) ([]dto.NotificationPreferenceResponse, error) {
+	customer, err := s.customerRepo.FindByID(customerID)
+	if err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	seen := make(map[string]bool, len(req.Channels))
+	preferences := make([]model.NotificationPreference, 0, len(req.Channels))
+	for _, channel := range req.Channels {
+		if seen[channel.Channel] {
+			return nil, fmt.Errorf("%w: channel %s listed twice", ErrInvalidNotificationSettings, channel.Channel)
+		}
+		seen[channel.Channel] = true
+
+		// Unverified addresses would let members send notices to anyone, or make the server call any URL
+		if actor.Role != "admin" && channel.Address != "" && channel.Address != customerContact(customer, channel.Channel) {
+			return nil, fmt.Errorf("%w: only the library can set a %s address other than the one on the customer record", ErrInvalidNotificationSettings, channel.Channel)
+		}
+
+		// Without an address the customer's own email or phone is used at delivery time
+		address := channel.Address
+		if address == "" {
+			address = customerContact(customer, channel.Channel)
+		}
+		if err := validateNotificationAddress(channel.Channel, address); err != nil {
+			return nil, err
+		}
+
+		enabled := true
+		if channel.Enabled != nil {
+			enabled = *channel.Enabled
+		}
+		preferences = append(preferences, model.NotificationPreference{
+			ID:         uuid.New(),
+			CustomerID: customerID,
+			Channel:    channel.Channel,
+			Address:    channel.Address,
+			Enabled:    enabled,
+		})
+	}
+
+	if err := s.repository.ReplacePreferences(customerID, preferences); err != nil {
+		return nil, err
+	}
+
+	return s.GetPreferences(customerID)
+}
+
+// GetTemplates returns the wording of every notice on every channel, custom or built in
+func (s *notificationService) GetTemplates() ([]dto.NotificationTemplateResponse, error) {
+	templates, err := s.templates()
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.NotificationTemplateResponse, 0, len(notificationTypes)*len(notificationChannels))
+	for _, notificationType := range notificationTypes {
+		for _, channel := range notificationChannels {
+			responses = append(responses, mapToNotificationTemplateResponse(notificationType, channel, templates))
+		}
+	}
+	return responses, nil
+}
+
+func (s *notificationService) UpdateTemplate(req dto.NotificationTemplateRequest) (*dto.NotificationTemplateResponse, error) {
+	// Reject templates that would fail on every notice
+	for _, text := range []string{req.Subject, req.Body} {
+		if _, err := renderNotificationTemplate(text, notificationTemplateData{}); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidNotificationSettings, err)
+		}
+	}
+
+	wording := model.NotificationTemplate{
+		ID:        uuid.New(),
+		Type:      req.Type,
+		Channel:   req.Channel,
+		Subject:   req.Subject,
+		Body:      req.Body,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.repository.SaveTemplate(&wording); err != nil {
+		return nil, err
+	}
+
+	templates, err := s.templates()
+	if err != nil {
+		return nil, err
+	}
+	response := mapToNotificationTemplateResponse(req.Type, req.Channel, templates)
+	return &response, nil
+}
+
+// SendDueReminders reminds customers of loans falling due within the reminder window.
+// It returns the notices sent and the deliveries that failed.
+func (s *notificationService) SendDueReminders() (int, int, error) {
+	if s.schedule.DueReminderDays <= 0 {
+		return 0, 0, nil
+	}
+
+	now := time.Now()
+	transactions, err := s.bookTransactionRepo.FindDueBetween(now, now.AddDate(0, 0, s.schedule.DueReminderDays))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	templates, err := s.templates()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var sent, failed int
+	var errs []error
+	for _, transaction := range transactions {
+		// A renewal moves the due date, which earns the loan a new reminder
+		notice := pendingNotification{
+			Type:              model.NotificationTypeDueReminder,
+			Reference:         fmt.Sprintf("due:%s:%s", transaction.ID, transaction.DueDate.Format("2006-01-02")),
+			Customer:          &transaction.Customer,
+			BookTransactionID: &transaction.ID,
+			Data: notificationTemplateData{
+				CustomerName: transaction.Customer.Name,
+				CustomerCode: transaction.Customer.Code,
+				BookTitle:    transaction.Book.Title,
+				StockCode:    transaction.StockCode,
+				DueDate:      transaction.DueDate.Format("02 Jan 2006"),
+				Days:         daysUntil(now, transaction.DueDate),
+			},
+		}
+		s.count(s.notify(notice, templates), &sent, &failed, &errs)
+	}
+
+	return sent, failed, errors.Join(errs...)
+}
+
+// SendOverdueNotices tells customers about overdue loans, again every interval until the
+// maximum number of notices is reached or the loan is closed
+func (s *notificationService) SendOverdueNotices() (int, int, error) {
+	if s.schedule.OverdueIntervalDays <= 0 {
+		return 0, 0, nil
+	}
+
+	transactions, err := s.bookTransactionRepo.FindOverdueTransactions()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	templates, err := s.templates()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	now := time.Now()
+	var sent, failed int
+	var errs []error
+	for _, transaction := range transactions {
+		// Every started day past the due date counts, as for late fees
+		daysOverdue := int(math.Ceil(now.Sub(transaction.DueDate).Hours() / 24))
+		number := (daysOverdue-1)/s.schedule.OverdueIntervalDays + 1
+		if s.schedule.MaxOverdueNotices > 0 && number > s.schedule.MaxOverdueNotices {
+			number = s.schedule.MaxOverdueNotices
+		}
+
+		notice := pendingNotification{
+			Type:              model.NotificationTypeOverdueNotice,
+			Reference:         fmt.Sprintf("overdue:%s:%d", transaction.ID, number),
+			Customer:          &transaction.Customer,
+			BookTransactionID: &transaction.ID,
+			Data: notificationTemplateData{
+				CustomerName: transaction.Customer.Name,
+				CustomerCode: transaction.Customer.Code,
+				BookTitle:    transaction.Book.Title,
+				StockCode:    transaction.StockCode,
+				DueDate:      transaction.DueDate.Format("02 Jan 2006"),
+				Days:         daysOverdue,
+				NoticeNumber: number,
+			},
+		}
+		s.count(s.notify(notice, templates), &sent, &failed, &errs)
+	}
+
+	return sent, failed, errors.Join(errs...)
+}
+
+// SendHoldReadyNotices tells customers a copy is waiting for them
+func (s *notificationService) SendHoldReadyNotices() (int, int, error) {
+	holds, err := s.holdRepo.FindReady()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	templates, err := s.templates()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var sent, failed int
+	var errs []error
+	for _, hold := range holds {
+		data := notificationTemplateData{
+			CustomerName: hold.Customer.Name,
+			CustomerCode: hold.Customer.Code,
+			BookTitle:    hold.Book.Title,
+		}
+		if hold.StockCode != nil {
+			data.StockCode = *hold.StockCode
+		}
+		if hold.PickupDeadline != nil {
+			data.PickupDeadline = hold.PickupDeadline.Format("02 Jan 2006")
+		}
+
+		notice := pendingNotification{
+			Type:      model.NotificationTypeHoldReady,
+			Reference: fmt.Sprintf("hold:%s", hold.ID),
+			Customer:  &hold.Customer,
+			HoldID:    &hold.ID,
+			Data:      data,
+		}
+		s.count(s.notify(notice, templates), &sent, &failed, &errs)
+	}
+
+	return sent, failed, errors.Join(errs...)
+}
+
+// SendPending sends every notice that is due; the scheduler calls it periodically
+func (s *notificationService) SendPending() (*dto.NotificationRunResponse, error) {
+	response := &dto.NotificationRunResponse{}
+	var errs []error
+	var failed int
+	var err error
+
+	response.DueReminders, failed, err = s.SendDueReminders()
+	response.Failed += failed
+	errs = append(errs, err)
+
+	response.OverdueNotices, failed, err = s.SendOverdueNotices()
+	response.Failed += failed
+	errs = append(errs, err)
+
+	response.HoldReady, failed, err = s.SendHoldReadyNotices()
+	response.Failed += failed
+	errs = append(errs, err)
+
+	return response, errors.Join(errs...)
+}
+
+// pendingNotification is a notice about to be sent to a customer on each of their channels
+type pendingNotification struct {
+	Type              string
+	Reference         string
+	Customer          *model.Customer
+	BookTransactionID *uuid.UUID
+	HoldID            *uuid.UUID
+	Data              notificationTemplateData
+}
+
+// notifyResult counts the deliveries of one notice
+type notifyResult struct {
+	sent   int
+	failed int
+	err    error
+}
+
+func (s *notificationService) count(result notifyResult, sent, failed *int, errs *[]error) {
+	if result.sent > 0 {
+		*sent++
+	}
+	*failed += result.failed
+	if result.err != nil {
+		*errs = append(*errs, result.err)
+	}
+}
+
+// notify delivers a notice on every enabled channel of the customer, or of their guardian, that has
+// not had it yet. Every attempt is logged; a customer without channels gets a single skipped entry.
+func (s *notificationService) notify(notice pendingNotification, templates map[string]model.NotificationTemplate) notifyResult {
+	var result notifyResult
+
+	// Notices about a dependant go to their guardian, on the guardian's channels
+	recipient := notice.Customer
+	notice.Data.RecipientName = notice.Customer.Name
+	notice.Data.Borrower = "you"
+	if notice.Customer.GuardianID != nil {
+		guardian, err := s.customerRepo.FindByID(*notice.Customer.GuardianID)
+		if err != nil {
+			result.err = err
+			return result
+		}
+		recipient = guardian
+		notice.Data.RecipientName = guardian.Name
+		notice.Data.Borrower = notice.Customer.Name
+	}
+
+	preferences, err := s.repository.FindPreferencesByCustomerID(recipient.ID)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	var enabled []model.NotificationPreference
+	for _, preference := range preferences {
+		if preference.Enabled {
+			enabled = append(enabled, preference)
+		}
+	}
+	if len(enabled) == 0 {
+		reason := "customer has no notification channels"
+		if recipient != notice.Customer {
+			reason = "guardian has no notification channels"
+		}
+		result.err = s.logOnce(notice, "", "", reason)
+		return result
+	}
+
+	for _, preference := range enabled {
+		if preference.Address == "" {
+			preference.Address = customerContact(recipient, preference.Channel)
+		}
+		done, err := s.repository.CountByReference(notice.Reference, preference.Channel, []string{model.NotificationStatusSent, model.NotificationStatusSkipped})
+		if err != nil {
+			result.err = err
+			return result
+		}
+		attempts, err := s.repository.CountByReference(notice.Reference, preference.Channel, []string{model.NotificationStatusFailed})
+		if err != nil {
+			result.err = err
+			return result
+		}
+		if done > 0 || attempts >= int64(s.schedule.MaxAttempts) {
+			continue
+		}
+
+		channel, ok := s.channels[preference.Channel]
+		if !ok {
+			result.err = s.logOnce(notice, preference.Channel, preference.Address, "channel is not configured")
+			if result.err != nil {
+				return result
+			}
+			continue
+		}
+
+		entry := s.newLogEntry(notice, preference.Channel, preference.Address)
+		wording := templates[templateKey(notice.Type, preference.Channel)]
+		entry.Subject, err = renderNotificationTemplate(wording.Subject, notice.Data)
+		if err == nil {
+			entry.Body, err = renderNotificationTemplate(wording.Body, notice.Data)
+		}
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			err = channel.Send(ctx, lib.NotificationMessage{
+				To:      preference.Address,
+				Subject: entry.Subject,
+				Body:    entry.Body,
+				Type:    notice.Type,
+			})
+			cancel()
+		}
+
+		entry.Status = model.NotificationStatusSent
+		if err != nil {
+			entry.Status = model.NotificationStatusFailed
+			entry.Error = err.Error()
+			result.failed++
+		} else {
+			result.sent++
+		}
+		if err := s.repository.Create(&entry); err != nil {
+			result.err = err
+			return result
+		}
+	}
+
+	return result
+}
+
+// logOnce records a notice that cannot be delivered on a channel, unless it already was
+func (s *notificationService) logOnce(notice pendingNotification, channel, recipient, reason string) error {
+	count, err := s.repository.CountByReference(notice.Reference, channel, []string{model.NotificationStatusSkipped})
+	if err != nil || count > 0 {
+		return err
+	}
+
+	entry := s.newLogEntry(notice, channel, recipient)
+	entry.Status = model.NotificationStatusSkipped
+	entry.Error = reason
+	return s.repository.Create(&entry)
+}
+
+func (s *notificationService) newLogEntry(notice pendingNotification, channel, recipient string) model.Notification {
+	return model.Notification{
+		ID:                uuid.New(),
+		CustomerID:        notice.Customer.ID,
+		Type:              notice.Type,
+		Channel:           channel,
+		Recipient:         recipient,
+		Reference:         notice.Reference,
+		BookTransactionID: notice.BookTransactionID,
+		HoldID:            notice.HoldID,
+		CreatedAt:         time.Now(),
+	}
+}
+
+// templates returns the wording of every notice type and channel, keyed by templateKey
+func (s *notificationService) templates() (map[string]model.NotificationTemplate, error) {
+	custom, err := s.repository.FindTemplates()
+	if err != nil {
+		return nil, err
+	}
+
+	templates := make(map[string]model.NotificationTemplate, len(notificationTypes)*len(notificationChannels))
+	for _, notificationType := range notificationTypes {
+		for _, channel := range notificationChannels {
+			wording := defaultNotificationTemplates[notificationType]
+			wording.Type = notificationType
+			wording.Channel = channel
+			templates[templateKey(notificationType, channel)] = wording
+		}
+	}
+	for _, wording := range custom {
+		templates[templateKey(wording.Type, wording.Channel)] = wording
+	}
+	return templates, nil
+}
+
+func templateKey(notificationType, channel string) string {
+	return notificationType + "/" + channel
+}
+
+func renderNotificationTemplate(text string, data notificationTemplateData) (string, error) {
+	tmpl, err := template.New("notification").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// customerContact returns the contact detail of the customer that fits a channel
+func customerContact(customer *model.Customer, channel string) string {
+	switch channel {
+	case model.NotificationChannelEmail:
+		return customer.Email
+	case model.NotificationChannelSMS:
+		return customer.Phone
+	}
+	return ""
+}
+
+// validateNotificationAddress checks the address fits the channel it is given for
+func validateNotificationAddress(channel, address string) error {
+	switch channel {
+	case model.NotificationChannelEmail:
+		if _, err := mail.ParseAddress(address); err != nil {
+			return fmt.Errorf("%w: a valid email address is required", ErrInvalidNotificationSettings)
+		}
+	case model.NotificationChannelSMS:
+		if len(address) < 6 {
+			return fmt.Errorf("%w: a phone number is required", ErrInvalidNotificationSettings)
+		}
+	case model.NotificationChannelWebhook:
+		// Without an address the library's default webhook is used
+		if address == "" {
+			return nil
+		}
+		if err := lib.CheckPublicURL(address); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidNotificationSettings, err)
+		}
+	}
+	return nil
+}
+
+// daysUntil counts the started days from now until the due date
+func daysUntil(now, due time.Time) int {
+	return int(math.Ceil(due.Sub(now).Hours() / 24))
+}
+
+func mapToNotificationResponse(notification *model.Notification) dto.NotificationResponse {
+	response := dto.NotificationResponse{
+		ID:                notification.ID,
+		CustomerID:        notification.CustomerID,
+		Type:              notification.Type,
+		Channel:           notification.Channel,
+		Recipient:         notification.Recipient,
+		Reference:         notification.Reference,
+		BookTransactionID: notification.BookTransactionID,
+		HoldID:            notification.HoldID,
+		Subject:           notification.Subject,
+		Body:              notification.Body,
+		Status:            notification.Status,
+		Error:             notification.Error,
+		CreatedAt:         notification.CreatedAt,
+	}
+	if notification.Customer != nil {
+		customer := mapToCustomerResponse(notification.Customer)
+		response.Customer = &customer
+	}
+	return response
+}
+
+func mapToNotificationPreferenceResponse(preference *model.NotificationPreference) dto.NotificationPreferenceResponse {
+	return dto.NotificationPreferenceResponse{
+		Channel:   preference.Channel,
+		Address:   preference.Address,
+		Enabled:   preference.Enabled,
+		UpdatedAt: preference.UpdatedAt,
+	}
+}
+
+func mapToNotificationTemplateResponse(notificationType, channel string, templates map[string]model.NotificationTemplate) dto.NotificationTemplateResponse {
+	wording := templates[templateKey(notificationType, channel)]
+	response := dto.NotificationTemplateResponse{
+		Type:    notificationType,
+		Channel: channel,
+		Subject: wording.Subject,
+		Body:    wording.Body,
+		Custom:  wording.ID != uuid.Nil,
+	}
+	if wording.ID != uuid.Nil {
+		response.UpdatedAt = &wording.UpdatedAt
+	}
+	return response
+}
@@ -0,0 +1,353 @@
+package service
+
+import (
+	"errors"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// memoryNotificationRepo keeps preferences and the delivery log in memory
+type memoryNotificationRepo struct {
+	repository.NotificationRepository
+	preferences map[uuid.UUID][]model.NotificationPreference
+	log         []model.Notification
+}
+
+func (r *memoryNotificationRepo) FindPreferencesByCustomerID(customerID uuid.UUID) ([]model.NotificationPreference, error) {
+	return r.preferences[customerID], nil
+}
+
+func (r *memoryNotificationRepo) CountByReference(reference, channel string, statuses []string) (int64, error) {
+	var count int64
+	for _, entry := range r.log {
+		if entry.Reference != reference || entry.Channel != channel {
+			continue
+		}
+		for _, status := range statuses {
+			if entry.Status == status {
+				count++
+				break
+			}
+		}
+	}
+	return count, nil
+}
+
+func (r *memoryNotificationRepo) Create(notification *model.Notification) error {
+	r.log = append(r.log, *notification)
+	return nil
+}
+
+func (r *memoryNotificationRepo) FindTemplates() ([]model.NotificationTemplate, error) {
+	return nil, nil
+}
+
+// entries returns the log entries with a status
+func (r *memoryNotificationRepo) entries(status string) []model.Notification {
+	var entries []model.Notification
+	for _, entry := range r.log {
+		if entry.Status == status {
+			entries = append(entries, entry)
+		}
+	}
+	return entries
+}
+
+// loansRepo serves open loans to the reminder and overdue runs
+type loansRepo struct {
+	repository.BookTransactionRepository
+	loans []model.BookTransaction
+}
+
+func (r *loansRepo) FindDueBetween(from, to time.Time) ([]model.BookTransaction, error) {
+	var due []model.BookTransaction
+	for _, loan := range r.loans {
+		if loan.Status == model.StatusBTBorrowed && !loan.DueDate.Before(from) && loan.DueDate.Before(to) {
+			due = append(due, loan)
+		}
+	}
+	return due, nil
+}
+
+func (r *loansRepo) FindOverdueTransactions() ([]model.BookTransaction, error) {
+	var overdue []model.BookTransaction
+	for _, loan := range r.loans {
+		if loan.DueDate.Before(time.Now()) {
+			overdue = append(overdue, loan)
+		}
+	}
+	return overdue, nil
+}
+
+type readyHoldsRepo struct {
+	repository.HoldRepository
+	holds []model.Hold
+}
+
+func (r *readyHoldsRepo) FindReady() ([]model.Hold, error) {
+	return r.holds, nil
+}
+
+type customerDirectory struct {
+	repository.CustomerRepository
+	customers map[uuid.UUID]model.Customer
+}
+
+func (r *customerDirectory) FindByID(id uuid.UUID) (*model.Customer, error) {
+	customer, ok := r.customers[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &customer, nil
+}
+
+// notificationFixture wires a notification service to memory repositories and memory channels
+type notificationFixture struct {
+	repo      *memoryNotificationRepo
+	loans     *loansRepo
+	holds     *readyHoldsRepo
+	customers *customerDirectory
+	email     *lib.MemoryChannel
+	sms       *lib.MemoryChannel
+	webhook   *lib.MemoryChannel
+}
+
+func newNotificationFixture() *notificationFixture {
+	return &notificationFixture{
+		repo:      &memoryNotificationRepo{preferences: map[uuid.UUID][]model.NotificationPreference{}},
+		loans:     &loansRepo{},
+		holds:     &readyHoldsRepo{},
+		customers: &customerDirectory{customers: map[uuid.UUID]model.Customer{}},
+		email:     lib.NewMemoryChannel(model.NotificationChannelEmail),
+		sms:       lib.NewMemoryChannel(model.NotificationChannelSMS),
+		webhook:   lib.NewMemoryChannel(model.NotificationChannelWebhook),
+	}
+}
+
+func (f *notificationFixture) service(schedule NotificationSchedule, channels ...lib.NotificationChannel) *notificationService {
+	if channels == nil {
+		channels = []lib.NotificationChannel{f.email, f.sms, f.webhook}
+	}
+	return NewNotificationService(f.repo, f.loans, f.holds, f.customers, channels, schedule).(*notificationService)
+}
+
+func (f *notificationFixture) addCustomer(name string, preferences ...model.NotificationPreference) model.Customer {
+	customer := model.Customer{
+		ID:    uuid.New(),
+		Code:  strings.ToUpper(name[:3]) + "-001",
+		Name:  name,
+		Email: strings.ToLower(name) + "@example.com",
+		Phone: "+6281234567",
+	}
+	f.customers.customers[customer.ID] = customer
+	f.repo.preferences[customer.ID] = preferences
+	return customer
+}
+
+func (f *notificationFixture) addLoan(customer model.Customer, due time.Time) model.BookTransaction {
+	loan := model.BookTransaction{
+		ID:         uuid.New(),
+		StockCode:  "BK-0001",
+		CustomerID: customer.ID,
+		Customer:   customer,
+		Book:       model.Book{Title: "The Go Programming Language"},
+		Status:     model.StatusBTBorrowed,
+		DueDate:    due,
+	}
+	f.loans.loans = append(f.loans.loans, loan)
+	return loan
+}
+
+func preference(channel, address string, enabled bool) model.NotificationPreference {
+	return model.NotificationPreference{ID: uuid.New(), Channel: channel, Address: address, Enabled: enabled}
+}
+
+func TestSendDueRemindersUsesEnabledChannelsOnce(t *testing.T) {
+	f := newNotificationFixture()
+	customer := f.addCustomer("Ayu",
+		preference(model.NotificationChannelEmail, "", true),
+		preference(model.NotificationChannelSMS, "", false),
+	)
+	f.addLoan(customer, time.Now().Add(36*time.Hour))
+	// Due after the reminder window, so not reminded yet
+	f.addLoan(customer, time.Now().AddDate(0, 0, 10))
+
+	svc := f.service(NotificationSchedule{DueReminderDays: 3})
+	sent, failed, err := svc.SendDueReminders()
+	if err != nil || sent != 1 || failed != 0 {
+		t.Fatalf("expected 1 reminder sent, got sent=%d failed=%d err=%v", sent, failed, err)
+	}
+
+	messages := f.email.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 email, got %d", len(messages))
+	}
+	if messages[0].To != customer.Email {
+		t.Errorf("expected the email to go to the customer's own address %s, got %s", customer.Email, messages[0].To)
+	}
+	if !strings.Contains(messages[0].Subject, "The Go Programming Language") || messages[0].Type != model.NotificationTypeDueReminder {
+		t.Errorf("unexpected reminder %+v", messages[0])
+	}
+	if len(f.sms.Messages()) != 0 {
+		t.Error("expected nothing on the disabled SMS channel")
+	}
+
+	// A second run finds the reminder in the log and does not send it again
+	sent, _, _ = svc.SendDueReminders()
+	if sent != 0 || len(f.email.Messages()) != 1 {
+		t.Errorf("expected the reminder to be sent once, got %d emails", len(f.email.Messages()))
+	}
+	if entries := f.repo.entries(model.NotificationStatusSent); len(entries) != 1 {
+		t.Errorf("expected 1 sent log entry, got %d", len(entries))
+	}
+}
+
+func TestSendOverdueNoticesNumbersNoticesByInterval(t *testing.T) {
+	f := newNotificationFixture()
+	customer := f.addCustomer("Budi", preference(model.NotificationChannelWebhook, "https://hooks.example.com/budi", true))
+	loan := f.addLoan(customer, time.Now().Add(-8*24*time.Hour-time.Hour))
+
+	svc := f.service(NotificationSchedule{OverdueIntervalDays: 7})
+	sent, failed, err := svc.SendOverdueNotices()
+	if err != nil || sent != 1 || failed != 0 {
+		t.Fatalf("expected 1 overdue notice sent, got sent=%d failed=%d err=%v", sent, failed, err)
+	}
+
+	messages := f.webhook.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 webhook call, got %d", len(messages))
+	}
+	if messages[0].To != "https://hooks.example.com/budi" {
+		t.Errorf("expected the customer's webhook URL, got %s", messages[0].To)
+	}
+	// Nine started days overdue with a weekly interval is the second notice
+	if !strings.HasPrefix(messages[0].Subject, "Overdue notice 2") {
+		t.Errorf("expected the second notice, got subject %q", messages[0].Subject)
+	}
+
+	entries := f.repo.entries(model.NotificationStatusSent)
+	if len(entries) != 1 || entries[0].Reference != "overdue:"+loan.ID.String()+":2" {
+		t.Errorf("expected one logged notice for reference overdue:%s:2, got %+v", loan.ID, entries)
+	}
+}
+
+func TestSendHoldReadyNoticesGoToTheGuardian(t *testing.T) {
+	f := newNotificationFixture()
+	guardian := f.addCustomer("Sari", preference(model.NotificationChannelSMS, "", true))
+	dependant := f.addCustomer("Dodi", preference(model.NotificationChannelEmail, "", true))
+	dependant.GuardianID = &guardian.ID
+	f.customers.customers[dependant.ID] = dependant
+
+	deadline := time.Now().AddDate(0, 0, 3)
+	f.holds.holds = []model.Hold{{
+		ID:             uuid.New(),
+		CustomerID:     dependant.ID,
+		Customer:       dependant,
+		Book:           model.Book{Title: "Charlotte's Web"},
+		Status:         model.StatusHoldReady,
+		PickupDeadline: &deadline,
+	}}
+
+	sent, failed, err := f.service(NotificationSchedule{}).SendHoldReadyNotices()
+	if err != nil || sent != 1 || failed != 0 {
+		t.Fatalf("expected 1 hold notice sent, got sent=%d failed=%d err=%v", sent, failed, err)
+	}
+
+	if len(f.email.Messages()) != 0 {
+		t.Error("expected nothing on the dependant's own channels")
+	}
+	messages := f.sms.Messages()
+	if len(messages) != 1 {
+		t.Fatalf("expected 1 SMS to the guardian, got %d", len(messages))
+	}
+	if messages[0].To != guardian.Phone {
+		t.Errorf("expected the SMS to go to the guardian's phone %s, got %s", guardian.Phone, messages[0].To)
+	}
+	if !strings.Contains(messages[0].Body, "Hello Sari") || !strings.Contains(messages[0].Body, "Dodi placed a hold") {
+		t.Errorf("expected the notice to address the guardian about the dependant, got %q", messages[0].Body)
+	}
+
+	// The log keeps the notice under the dependant it is about
+	entries := f.repo.entries(model.NotificationStatusSent)
+	if len(entries) != 1 || entries[0].CustomerID != dependant.ID {
+		t.Errorf("expected the notice logged for the dependant, got %+v", entries)
+	}
+}
+
+func TestNotifyLogsUndeliverableNoticesOnce(t *testing.T) {
+	f := newNotificationFixture()
+	withoutChannels := f.addCustomer("Citra")
+	smsOnly := f.addCustomer("Dewi", preference(model.NotificationChannelSMS, "", true))
+	f.addLoan(withoutChannels, time.Now().Add(24*time.Hour))
+	f.addLoan(smsOnly, time.Now().Add(24*time.Hour))
+
+	// SMS is not configured, so Dewi's only channel cannot be used
+	svc := f.service(NotificationSchedule{DueReminderDays: 3}, f.email)
+	for run := 0; run < 3; run++ {
+		if _, _, err := svc.SendDueReminders(); err != nil {
+			t.Fatalf("run %d: unexpected error: %v", run, err)
+		}
+	}
+
+	skipped := f.repo.entries(model.NotificationStatusSkipped)
+	if len(skipped) != 2 {
+		t.Fatalf("expected one skipped entry per customer, got %d", len(skipped))
+	}
+	reasons := map[uuid.UUID]string{}
+	for _, entry := range skipped {
+		reasons[entry.CustomerID] = entry.Error
+	}
+	if reasons[withoutChannels.ID] != "customer has no notification channels" {
+		t.Errorf("unexpected reason for a customer without channels: %q", reasons[withoutChannels.ID])
+	}
+	if reasons[smsOnly.ID] != "channel is not configured" {
+		t.Errorf("unexpected reason for an unconfigured channel: %q", reasons[smsOnly.ID])
+	}
+	if len(f.email.Messages()) != 0 {
+		t.Error("expected no email to be sent")
+	}
+}
+
+func TestNotifyLogsFailedDeliveriesUntilMaxAttempts(t *testing.T) {
+	f := newNotificationFixture()
+	customer := f.addCustomer("Eka", preference(model.NotificationChannelEmail, "", true))
+	f.addLoan(customer, time.Now().Add(24*time.Hour))
+	f.email.Err = errors.New("smtp: connection refused")
+
+	svc := f.service(NotificationSchedule{DueReminderDays: 3, MaxAttempts: 2})
+	var failures []int
+	for run := 0; run < 3; run++ {
+		sent, failed, err := svc.SendDueReminders()
+		if err != nil || sent != 0 {
+			t.Fatalf("run %d: expected no reminder sent, got sent=%d err=%v", run, sent, err)
+		}
+		failures = append(failures, failed)
+	}
+
+	// Two attempts are made, the third run gives the notice up
+	if failures[0] != 1 || failures[1] != 1 || failures[2] != 0 {
+		t.Errorf("expected failures 1, 1, 0 per run, got %v", failures)
+	}
+	failed := f.repo.entries(model.NotificationStatusFailed)
+	if len(failed) != 2 {
+		t.Fatalf("expected 2 failed log entries, got %d", len(failed))
+	}
+	for _, entry := range failed {
+		if entry.Error != "smtp: connection refused" || entry.Recipient != customer.Email || entry.Body == "" {
+			t.Errorf("expected the failure to be logged with the rendered notice, got %+v", entry)
+		}
+	}
+
+	// The provider recovering does not revive a notice that was given up
+	f.email.Err = nil
+	if sent, _, _ := svc.SendDueReminders(); sent != 0 || len(f.email.Messages()) != 0 {
+		t.Error("expected the given up notice to stay unsent")
+	}
+}
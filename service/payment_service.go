@@ -0,0 +1,470 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"go-gin-simple-api/dto"
+	"go-gin-simple-api/lib"
+	"go-gin-simple-api/model"
+	"go-gin-simple-api/repository"
+	"math"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentService interface {
+	GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.PaymentResponse], error)
+	GetByID(id uuid.UUID) (*dto.PaymentResponse, error)
+	GetReceipt(id uuid.UUID) (*dto.PaymentReceiptResponse, error)
+	GetReceiptPDF(id uuid.UUID) ([]byte, error)
+	Pay(chargeID uuid.UUID, actor dto.UserData, req dto.PaymentCreateRequest) (*dto.PaymentResponse, error)
+	Adjust(chargeID uuid.UUID, actor dto.UserData, req dto.ChargeAdjustmentRequest) (*dto.ChargeBalanceResponse, error)
+	GetChargeBalance(chargeID uuid.UUID) (*dto.ChargeBalanceResponse, error)
+	GetCustomerBalance(customerID uuid.UUID) (*dto.CustomerBalanceResponse, error)
+}
+
+// ErrPaymentNotAllowed is returned when a payment or adjustment does not fit the charge balance
+var ErrPaymentNotAllowed = errors.New("payment not allowed")
+
+type paymentService struct {
+	repository    repository.PaymentRepository
+	chargeRepo    repository.ChargeRepository
+	customerRepo  repository.CustomerRepository
+	userRepo      repository.AuthRepository
+	branchRepo    repository.BranchRepository
+	sequenceRepo  repository.CodeSequenceRepository
+	uow           repository.UnitOfWork
+	receiptFormat *lib.CodePattern
+	defaultBranch string
+}
+
+func NewPaymentService(
+	repository repository.PaymentRepository,
+	chargeRepo repository.ChargeRepository,
+	customerRepo repository.CustomerRepository,
+	userRepo repository.AuthRepository,
+	branchRepo repository.BranchRepository,
+	sequenceRepo repository.CodeSequenceRepository,
+	uow repository.UnitOfWork,
+	receiptFormat *lib.CodePattern,
+	defaultBranch string,
+) PaymentService {
+	return &paymentService{
+		repository:    repository,
+		chargeRepo:    chargeRepo,
+		customerRepo:  customerRepo,
+		userRepo:      userRepo,
+		branchRepo:    branchRepo,
+		sequenceRepo:  sequenceRepo,
+		uow:           uow,
+		receiptFormat: receiptFormat,
+		defaultBranch: defaultBranch,
+	}
+}
+
+func (s *paymentService) GetAll(page, perPage int, search string, filter lib.FilterParams) (*dto.PaginatedResponseData[[]dto.PaymentResponse], error) {
+	payments, total, err := s.repository.FindAll(page, perPage, search, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	paymentResponses := make([]dto.PaymentResponse, 0)
+	for _, payment := range payments {
+		paymentResponses = append(paymentResponses, mapToPaymentResponse(&payment))
+	}
+
+	// Calculate total pages
+	totalPages := int64(total) / int64(perPage)
+	if int64(total)%int64(perPage) > 0 {
+		totalPages++
+	}
+
+	return &dto.PaginatedResponseData[[]dto.PaymentResponse]{
+		Status:  200,
+		Message: "Payments retrieved successfully",
+		Data:    paymentResponses,
+		Meta: dto.PaginationMeta{
+			Page:        page,
+			PerPage:     perPage,
+			TotalItems:  total,
+			TotalPages:  totalPages,
+			ItemsOnPage: int64(len(paymentResponses)),
+		},
+	}, nil
+}
+
+func (s *paymentService) GetByID(id uuid.UUID) (*dto.PaymentResponse, error) {
+	payment, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	response := mapToPaymentResponse(payment)
+	return &response, nil
+}
+
+func (s *paymentService) GetReceipt(id uuid.UUID) (*dto.PaymentReceiptResponse, error) {
+	payment, err := s.repository.FindByID(id)
+	if err != nil {
+		return nil, errors.New("payment not found")
+	}
+
+	// The balance right after this payment, ignoring anything recorded later
+	charge := payment.Charge
+	var settled float64
+	for _, p := range charge.Payments {
+		if !p.CreatedAt.After(payment.CreatedAt) {
+			settled += p.Amount
+		}
+	}
+	for _, adjustment := range charge.Adjustments {
+		if !adjustment.CreatedAt.After(payment.CreatedAt) {
+			settled += adjustment.Amount
+		}
+	}
+	for _, refund := range charge.Refunds {
+		if !refund.CreatedAt.After(payment.CreatedAt) {
+			settled -= refund.Amount
+		}
+	}
+
+	return &dto.PaymentReceiptResponse{
+		ReceiptNumber: payment.ReceiptNumber,
+		IssuedAt:      payment.CreatedAt,
+		CustomerCode:  payment.Customer.Code,
+		CustomerName:  payment.Customer.Name,
+		ChargeID:      payment.ChargeID,
+		BookTitle:     charge.BookTransaction.Book.Title,
+		StockCode:     charge.BookTransaction.StockCode,
+		ChargeTotal:   charge.Total,
+		Amount:        payment.Amount,
+		Method:        payment.Method,
+		Note:          payment.Note,
+		BalanceAfter:  roundMoney(charge.Total - settled),
+		ReceivedBy:    payment.User.Name,
+	}, nil
+}
+
+func (s *paymentService) GetReceiptPDF(id uuid.UUID) ([]byte, error) {
+	receipt, err := s.GetReceipt(id)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := []lib.ReceiptLine{
+		{Label: "Date", Value: receipt.IssuedAt.Format("02 Jan 2006 15:04")},
+		{Label: "Customer", Value: fmt.Sprintf("%s (%s)", receipt.CustomerName, receipt.CustomerCode)},
+	}
+	if receipt.BookTitle != "" {
+		lines = append(lines, lib.ReceiptLine{Label: "Item", Value: fmt.Sprintf("%s [%s]", receipt.BookTitle, receipt.StockCode)})
+	}
+	lines = append(lines,
+		lib.ReceiptLine{Label: "Charge total", Value: formatMoney(receipt.ChargeTotal)},
+		lib.ReceiptLine{Label: "Amount paid", Value: formatMoney(receipt.Amount)},
+		lib.ReceiptLine{Label: "Method", Value: receipt.Method},
+		lib.ReceiptLine{Label: "Balance remaining", Value: formatMoney(receipt.BalanceAfter)},
+		lib.ReceiptLine{Label: "Received by", Value: receipt.ReceivedBy},
+	)
+	if receipt.Note != "" {
+		lines = append(lines, lib.ReceiptLine{Label: "Note", Value: receipt.Note})
+	}
+
+	return lib.GenerateReceiptPDF(lib.Receipt{
+		Title:  "Payment Receipt",
+		Number: receipt.ReceiptNumber,
+		Lines:  lines,
+		Footer: "Thank you. Please keep this receipt for your records.",
+	})
+}
+
+func (s *paymentService) Pay(chargeID uuid.UUID, actor dto.UserData, req dto.PaymentCreateRequest) (*dto.PaymentResponse, error) {
+	// Receipt numbers are reserved up front; a failed payment only leaves a gap in the sequence
+	receiptNumber, err := s.nextReceiptNumber(actor)
+	if err != nil {
+		return nil, err
+	}
+
+	var payment model.Payment
+	err = s.uow.Do(func(repos repository.Repositories) error {
+		// Lock the charge so concurrent payments cannot together exceed the balance
+		charge, err := repos.Charges.FindByIDForUpdate(chargeID)
+		if err != nil {
+			return errors.New("charge not found")
+		}
+
+		transaction, err := repos.BookTransactions.FindByID(charge.BookTransactionID)
+		if err != nil {
+			return errors.New("book transaction not found")
+		}
+
+		amount := roundMoney(req.Amount)
+		ledger := summarizeCharge(charge)
+		if ledger.Balance <= 0 {
+			return fmt.Errorf("%w: charge is already settled", ErrPaymentNotAllowed)
+		}
+		if amount > ledger.Balance {
+			return fmt.Errorf("%w: amount %s exceeds the outstanding balance of %s", ErrPaymentNotAllowed, formatMoney(amount), formatMoney(ledger.Balance))
+		}
+
+		payment = model.Payment{
+			ID:            uuid.New(),
+			ChargeID:      charge.ID,
+			CustomerID:    transaction.CustomerID,
+			Amount:        amount,
+			Method:        req.Method,
+			ReceiptNumber: receiptNumber,
+			Note:          req.Note,
+			UserID:        actor.ID,
+		}
+		return repos.Payments.Create(&payment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetByID(payment.ID)
+}
+
+func (s *paymentService) Adjust(chargeID uuid.UUID, actor dto.UserData, req dto.ChargeAdjustmentRequest) (*dto.ChargeBalanceResponse, error) {
+	// Only administrators can approve waivers and discounts
+	approverID := actor.ID
+	if req.ApprovedBy != nil {
+		approverID = *req.ApprovedBy
+	}
+	approver, err := s.userRepo.FindByID(approverID)
+	if err != nil {
+		return nil, errors.New("approver not found")
+	}
+	if approver.Role != "admin" {
+		return nil, fmt.Errorf("%w: approver must be an administrator", ErrPaymentNotAllowed)
+	}
+
+	err = s.uow.Do(func(repos repository.Repositories) error {
+		charge, err := repos.Charges.FindByIDForUpdate(chargeID)
+		if err != nil {
+			return errors.New("charge not found")
+		}
+
+		ledger := summarizeCharge(charge)
+		if ledger.Balance <= 0 {
+			return fmt.Errorf("%w: charge is already settled", ErrPaymentNotAllowed)
+		}
+
+		amount := ledger.Balance
+		if req.Type == model.AdjustmentDiscount {
+			amount = roundMoney(req.Amount)
+			if amount > ledger.Balance {
+				return fmt.Errorf("%w: discount %s exceeds the outstanding balance of %s", ErrPaymentNotAllowed, formatMoney(amount), formatMoney(ledger.Balance))
+			}
+		}
+
+		adjustment := model.ChargeAdjustment{
+			ID:           uuid.New(),
+			ChargeID:     charge.ID,
+			Type:         req.Type,
+			Amount:       amount,
+			Reason:       req.Reason,
+			ApprovedByID: approver.ID,
+			UserID:       actor.ID,
+		}
+		return repos.Payments.CreateAdjustment(&adjustment)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetChargeBalance(chargeID)
+}
+
+func (s *paymentService) GetChargeBalance(chargeID uuid.UUID) (*dto.ChargeBalanceResponse, error) {
+	charge, err := s.chargeRepo.FindByID(chargeID)
+	if err != nil {
+		return nil, errors.New("charge not found")
+	}
+
+	response := mapToChargeBalanceResponse(charge)
+	return &response, nil
+}
+
+func (s *paymentService) GetCustomerBalance(customerID uuid.UUID) (*dto.CustomerBalanceResponse, error) {
+	if _, err := s.customerRepo.FindByID(customerID); err != nil {
+		return nil, errors.New("customer not found")
+	}
+
+	charges, err := s.chargeRepo.FindByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.CustomerBalanceResponse{
+		CustomerID: customerID,
+		Charges:    make([]dto.ChargeBalanceResponse, 0, len(charges)),
+	}
+	for _, charge := range charges {
+		balance := mapToChargeBalanceResponse(&charge)
+		response.TotalCharged += balance.Total
+		response.TotalPaid += balance.Paid
+		response.TotalWaived += balance.Waived
+		response.Charges = append(response.Charges, balance)
+	}
+	response.TotalCharged = roundMoney(response.TotalCharged)
+	response.TotalPaid = roundMoney(response.TotalPaid)
+	response.TotalWaived = roundMoney(response.TotalWaived)
+	response.Balance = roundMoney(response.TotalCharged - response.TotalPaid - response.TotalWaived)
+
+	return response, nil
+}
+
+// nextReceiptNumber renders the next receipt number for the branch the actor works at
+func (s *paymentService) nextReceiptNumber(actor dto.UserData) (string, error) {
+	branch := s.defaultBranch
+	if actor.BranchID != nil {
+		if b, err := s.branchRepo.FindByID(*actor.BranchID); err == nil {
+			branch = b.Code
+		}
+	}
+
+	values := lib.CodePatternValues{Branch: branch, Time: time.Now()}
+	seq, err := s.sequenceRepo.NextRange("receipt:"+s.receiptFormat.Scope(values), 1)
+	if err != nil {
+		return "", err
+	}
+
+	number := s.receiptFormat.Render(values, seq)
+	if len(number) > 50 {
+		return "", fmt.Errorf("generated receipt number %s exceeds 50 characters", number)
+	}
+	return number, nil
+}
+
+// chargeLedger sums up what was paid and forgiven on a charge; Paid is net of refunds
+type chargeLedger struct {
+	Paid     float64
+	Refunded float64
+	Waived   float64
+	Balance  float64
+	Status   string
+}
+
+// summarizeCharge computes the outstanding balance of a charge from its preloaded ledger
+func summarizeCharge(charge *model.Charge) chargeLedger {
+	var ledger chargeLedger
+	for _, payment := range charge.Payments {
+		ledger.Paid += payment.Amount
+	}
+	for _, adjustment := range charge.Adjustments {
+		ledger.Waived += adjustment.Amount
+	}
+	for _, refund := range charge.Refunds {
+		ledger.Refunded += refund.Amount
+	}
+	ledger.Refunded = roundMoney(ledger.Refunded)
+	ledger.Paid = roundMoney(ledger.Paid - ledger.Refunded)
+	ledger.Waived = roundMoney(ledger.Waived)
+	ledger.Balance = roundMoney(charge.Total - ledger.Paid - ledger.Waived)
+
+	switch {
+	case ledger.Balance > 0 && ledger.Paid == 0 && ledger.Waived == 0:
+		ledger.Status = model.ChargeStatusUnpaid
+	case ledger.Balance > 0:
+		ledger.Status = model.ChargeStatusPartiallyPaid
+	case ledger.Paid == 0 && ledger.Waived > 0:
+		ledger.Status = model.ChargeStatusWaived
+	default:
+		ledger.Status = model.ChargeStatusPaid
+	}
+	return ledger
+}
+
+// roundMoney rounds an amount to whole cents
+func roundMoney(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func formatMoney(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
+
+// Helper function to map a Charge entity to a ChargeBalanceResponse DTO
+func mapToChargeBalanceResponse(charge *model.Charge) dto.ChargeBalanceResponse {
+	ledger := summarizeCharge(charge)
+	response := dto.ChargeBalanceResponse{
+		ChargeID:          charge.ID,
+		BookTransactionID: charge.BookTransactionID,
+		Total:             charge.Total,
+		Paid:              ledger.Paid,
+		Refunded:          ledger.Refunded,
+		Waived:            ledger.Waived,
+		Balance:           ledger.Balance,
+		Status:            ledger.Status,
+		Payments:          make([]dto.PaymentResponse, 0, len(charge.Payments)),
+		Adjustments:       make([]dto.ChargeAdjustmentResponse, 0, len(charge.Adjustments)),
+		Refunds:           make([]dto.RefundResponse, 0, len(charge.Refunds)),
+	}
+
+	for _, payment := range charge.Payments {
+		response.Payments = append(response.Payments, mapToPaymentResponse(&payment))
+	}
+	for _, adjustment := range charge.Adjustments {
+		response.Adjustments = append(response.Adjustments, dto.ChargeAdjustmentResponse{
+			ID:           adjustment.ID,
+			ChargeID:     adjustment.ChargeID,
+			Type:         adjustment.Type,
+			Amount:       adjustment.Amount,
+			Reason:       adjustment.Reason,
+			ApprovedByID: adjustment.ApprovedByID,
+			UserID:       adjustment.UserID,
+			CreatedAt:    adjustment.CreatedAt,
+		})
+	}
+	for _, refund := range charge.Refunds {
+		response.Refunds = append(response.Refunds, dto.RefundResponse{
+			ID:         refund.ID,
+			ChargeID:   refund.ChargeID,
+			CustomerID: refund.CustomerID,
+			Amount:     refund.Amount,
+			Method:     refund.Method,
+			Reason:     refund.Reason,
+			UserID:     refund.UserID,
+			CreatedAt:  refund.CreatedAt,
+		})
+	}
+
+	return response
+}
+
+// Helper function to map a Payment entity to a PaymentResponse DTO
+func mapToPaymentResponse(payment *model.Payment) dto.PaymentResponse {
+	response := dto.PaymentResponse{
+		ID:            payment.ID,
+		ChargeID:      payment.ChargeID,
+		CustomerID:    payment.CustomerID,
+		Amount:        payment.Amount,
+		Method:        payment.Method,
+		ReceiptNumber: payment.ReceiptNumber,
+		Note:          payment.Note,
+		UserID:        payment.UserID,
+		CreatedAt:     payment.CreatedAt,
+	}
+
+	if payment.Customer.ID != uuid.Nil {
+		response.Customer = &dto.CustomerResponse{
+			ID:       payment.Customer.ID,
+			Code:     payment.Customer.Code,
+			Name:     payment.Customer.Name,
+			Category: payment.Customer.Category,
+		}
+	}
+
+	if payment.User.ID != uuid.Nil {
+		response.User = &dto.UserData{
+			ID:    payment.User.ID,
+			Name:  payment.User.Name,
+			Email: payment.User.Email,
+			Role:  payment.User.Role,
+		}
+	}
+
+	return response
+}